@@ -6,28 +6,54 @@
 package govcloudair
 
 import (
+	"context"
 	"encoding/xml"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-
-	types "github.com/ukcloud/govcloudair/types/v56"
+	"sync"
+	"sync/atomic"
 )
 
 // Client provides a client to vCloud Air, values can be populated automatically using the Authenticate method.
+//
+// A Client is safe for concurrent use once authenticated: many goroutines can
+// share one session, its connection pool and its request limit, see
+// SetMaxConcurrentRequests. Objects such as VApp or Task keep a pointer to the
+// Client they were created from, so a token renewed by re-authentication is
+// picked up by all of them. Configuration and login, i.e. setting fields, Use
+// and the Authenticate methods, must happen before the client is shared.
 type Client struct {
-	APIVersion    string      // The API version required
-	VCDToken      string      // Access Token (authorization header)
-	VCDAuthHeader string      // Authorization header
-	VCDVDCHREF    url.URL     // HREF of the backend VDC you're using
-	Http          http.Client // HttpClient is the client to use. Default will be used if not provided.
+	APIVersion    string       // The API version in use, negotiated at login
+	MinAPIVersion string       // Lowest API version acceptable at login, MinSupportedAPIVersion if empty
+	MaxAPIVersion string       // Highest API version acceptable at login, MaxSupportedAPIVersion if empty
+	VCDToken      string       // Access Token (authorization header)
+	VCDAuthHeader string       // Authorization header
+	VCDVDCHREF    url.URL      // HREF of the backend VDC you're using
+	Http          http.Client  // HttpClient is the client to use. Default will be used if not provided.
+	RetryPolicy   *RetryPolicy // Retry policy for transient errors, DefaultRetryPolicy if nil.
+	Middleware    []Middleware // Middleware chain wrapping the Http transport, see Use.
+	Metrics       Metrics      // Receives request and task metrics, nothing is recorded if nil.
+	Tracer        Tracer       // Traces operations, requests and tasks, nothing is traced if nil.
+
+	authMu   sync.RWMutex                    // Guards VCDToken and VCDAuthHeader against re-authentication
+	reauthMu sync.Mutex                      // Serialises re-authentication
+	reauth   func(ctx context.Context) error // Logs in again, set by VCDClient.EnableReauthentication
+	slots    atomic.Value                    // *chan struct{} limiting requests in flight, see SetMaxConcurrentRequests
+	limiter  atomic.Value                    // *rateLimiter, see SetRateLimit
 }
 
 // NewRequest creates a new HTTP request and applies necessary auth headers if
 // set.
 func (c *Client) NewRequest(params map[string]string, method string, u url.URL, body io.Reader) *http.Request {
+	return c.NewRequestWithContext(context.Background(), params, method, u, body)
+}
+
+// NewRequestWithContext creates a new HTTP request bound to ctx and applies
+// necessary auth headers if set. Cancelling ctx aborts the request while it is
+// in flight.
+func (c *Client) NewRequestWithContext(ctx context.Context, params map[string]string, method string, u url.URL, body io.Reader) *http.Request {
 
 	p := url.Values{}
 
@@ -42,32 +68,19 @@ func (c *Client) NewRequest(params map[string]string, method string, u url.URL,
 	// Build the request, no point in checking for errors here as we're just
 	// passing a string version of an url.URL struct and http.NewRequest returns
 	// error only if can't process an url.ParseRequestURI().
-	req, _ := http.NewRequest(method, u.String(), body)
+	req, _ := http.NewRequestWithContext(ctx, method, u.String(), body)
 
-	if c.VCDAuthHeader != "" && c.VCDToken != "" {
+	if header, value := c.authHeader(); header != "" && value != "" {
 		// Add the authorization header
-		req.Header.Add(c.VCDAuthHeader, c.VCDToken)
+		req.Header.Add(header, value)
 		// Add the Accept header for VCD
-		req.Header.Add("Accept", "application/*+xml;version="+c.APIVersion)
+		req.Header.Add("Accept", c.acceptHeader("application/*+xml"))
 	}
 
 	return req
 
 }
 
-// parseErr takes an error XML resp and returns a single string for use in error messages.
-func parseErr(resp *http.Response) error {
-
-	errBody := new(types.Error)
-
-	// if there was an error decoding the body, just return that
-	if err := decodeBody(resp, errBody); err != nil {
-		return fmt.Errorf("error parsing error body for non-200 request: %s", err)
-	}
-
-	return fmt.Errorf("API Error: %d: %s", errBody.MajorErrorCode, errBody.Message)
-}
-
 // decodeBody is used to XML decode a response body
 func decodeBody(resp *http.Response, out interface{}) error {
 
@@ -86,8 +99,8 @@ func decodeBody(resp *http.Response, out interface{}) error {
 
 // checkResp wraps http.Client.Do() and verifies the request, if status code
 // is 2XX it passes back the response, if it's a known invalid status code it
-// parses the resultant XML error and returns a descriptive error, if the
-// status code is not handled it returns a generic error with the status code.
+// parses the resultant XML error and returns it as an *APIError, if the
+// status code is not handled it returns an *APIError built from the status.
 func checkResp(resp *http.Response, err error) (*http.Response, error) {
 	if err != nil {
 		return resp, err
@@ -98,10 +111,14 @@ func checkResp(resp *http.Response, err error) (*http.Response, error) {
 	case i == 200 || i == 201 || i == 202 || i == 204:
 		return resp, nil
 	// Invalid request, parse the XML error returned and return it.
-	case i == 400 || i == 401 || i == 403 || i == 404 || i == 405 || i == 406 || i == 409 || i == 415 || i == 500 || i == 503 || i == 504:
+	case i == 400 || i == 401 || i == 403 || i == 404 || i == 405 || i == 406 || i == 409 || i == 415 || i == 429 || i == 500 || i == 503 || i == 504:
+		defer resp.Body.Close()
 		return nil, parseErr(resp)
 	// Unhandled response.
 	default:
-		return nil, fmt.Errorf("unhandled API response, please report this issue, status code: %s", resp.Status)
+		resp.Body.Close()
+		apiErr := newAPIError(resp)
+		apiErr.Message = "unhandled API response, please report this issue"
+		return nil, apiErr
 	}
 }
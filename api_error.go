@@ -0,0 +1,162 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// APIError is returned for every non successful response received from
+// vCloud Director. When the body carries a vCloud Error element its
+// attributes are copied over, otherwise the HTTP status is used to fill in
+// the codes. Callers can get hold of it with errors.As.
+type APIError struct {
+	StatusCode              int           // HTTP status code of the response
+	Status                  string        // HTTP status line of the response
+	Method                  string        // HTTP method of the failed request
+	URL                     string        // URL of the failed request
+	Message                 string        // Error message
+	MajorErrorCode          int           // vCloud major error code, the HTTP status if the body had none
+	MinorErrorCode          string        // vCloud minor error code, e.g. BUSY_ENTITY
+	VendorSpecificErrorCode string        // Vendor specific error code
+	StackTrace              string        // Server side stack trace, if any
+	RetryAfter              time.Duration // Delay asked for by the server in a Retry-After header, if any
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API Error: %d: %s", e.MajorErrorCode, e.Message)
+	if e.Method != "" && e.URL != "" {
+		msg += fmt.Sprintf(" [%s %s]", e.Method, e.URL)
+	}
+	return msg
+}
+
+// IsNotFound reports whether the API could not find the requested entity.
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound || e.MajorErrorCode == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether the request lacked valid credentials, which
+// usually means the session token has expired.
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.MajorErrorCode == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether the user is not allowed to perform the request.
+func (e *APIError) IsForbidden() bool {
+	return e.StatusCode == http.StatusForbidden || e.MajorErrorCode == http.StatusForbidden
+}
+
+// IsThrottled reports whether the server turned the request down because the
+// client is sending too many requests.
+func (e *APIError) IsThrottled() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsBusy reports whether the entity the request targeted is busy completing
+// another operation, in which case the request can be tried again later.
+func (e *APIError) IsBusy() bool {
+	if e.MinorErrorCode == "BUSY_ENTITY" {
+		return true
+	}
+	// Older releases only flag busy entities in the message, e.g. "The
+	// entity gateway is busy completing an operation." or "... is busy,
+	// cannot proceed with the operation."
+	return strings.Contains(e.Message, "is busy completing an operation") ||
+		strings.Contains(e.Message, "is busy, cannot proceed with the operation")
+}
+
+// IsNotFound reports whether err, or any error it wraps, is an APIError for
+// an entity that could not be found.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsNotFound()
+}
+
+// IsUnauthorized reports whether err, or any error it wraps, is an APIError
+// caused by missing or expired credentials.
+func IsUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsUnauthorized()
+}
+
+// IsForbidden reports whether err, or any error it wraps, is an APIError
+// caused by insufficient rights.
+func IsForbidden(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsForbidden()
+}
+
+// IsThrottled reports whether err, or any error it wraps, is an APIError
+// caused by the client sending too many requests.
+func IsThrottled(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsThrottled()
+}
+
+// IsBusy reports whether err, or any error it wraps, is an APIError caused by
+// the target entity being busy with another operation.
+func IsBusy(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.IsBusy()
+}
+
+// newAPIError builds an APIError out of the status and originating request of
+// resp, without looking at the body.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode:     resp.StatusCode,
+		Status:         resp.Status,
+		MajorErrorCode: resp.StatusCode,
+		Message:        http.StatusText(resp.StatusCode),
+		RetryAfter:     retryAfter(resp),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		if resp.Request.URL != nil {
+			apiErr.URL = resp.Request.URL.String()
+		}
+	}
+	return apiErr
+}
+
+// parseErr takes an error response and turns it into an APIError. If the body
+// is not a vCloud Error document the HTTP status is used instead.
+func parseErr(resp *http.Response) error {
+
+	apiErr := newAPIError(resp)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		apiErr.Message = fmt.Sprintf("error reading body for non-200 request: %s", err)
+		return apiErr
+	}
+
+	errBody := new(types.Error)
+
+	// Bodies coming from proxies and load balancers are usually HTML, keep
+	// the status based defaults for those.
+	if err = xml.Unmarshal(body, errBody); err != nil || (errBody.MajorErrorCode == 0 && errBody.Message == "") {
+		return apiErr
+	}
+
+	if errBody.MajorErrorCode != 0 {
+		apiErr.MajorErrorCode = errBody.MajorErrorCode
+	}
+	apiErr.Message = errBody.Message
+	apiErr.MinorErrorCode = errBody.MinorErrorCode
+	apiErr.VendorSpecificErrorCode = errBody.VendorSpecificErrorCode
+	apiErr.StackTrace = errBody.StackTrace
+
+	return apiErr
+}
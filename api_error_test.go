@@ -0,0 +1,98 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_APIErrorFromXML(c *C) {
+
+	testServer.Response(500, nil, vcdError)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+	var apiErr *APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(apiErr.StatusCode, Equals, 500)
+	c.Assert(apiErr.Method, Equals, "GET")
+	c.Assert(apiErr.URL, Matches, "http://localhost:4444/.*")
+	c.Assert(apiErr.Message, Equals, "Error Message")
+	c.Assert(apiErr.MajorErrorCode, Equals, 500)
+	c.Assert(apiErr.MinorErrorCode, Equals, "Server Error")
+	c.Assert(apiErr.VendorSpecificErrorCode, Equals, "NoSpecificError")
+	c.Assert(apiErr.StackTrace, Equals, "Hello my name is Stack Trace")
+
+}
+
+func (s *S) Test_APIErrorFromNonXML(c *C) {
+
+	testServer.Response(404, nil, notfoundErr)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+	var apiErr *APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(apiErr.StatusCode, Equals, 404)
+	c.Assert(apiErr.MajorErrorCode, Equals, 404)
+	c.Assert(apiErr.Message, Equals, "Not Found")
+	c.Assert(IsNotFound(err), Equals, true)
+	c.Assert(IsBusy(err), Equals, false)
+
+}
+
+func (s *S) Test_APIErrorUnhandledStatus(c *C) {
+
+	testServer.Response(418, nil, "")
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+	var apiErr *APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(apiErr.StatusCode, Equals, 418)
+	c.Assert(apiErr.Message, Equals, "unhandled API response, please report this issue")
+
+}
+
+func (s *S) Test_APIErrorPredicates(c *C) {
+
+	testServer.Response(401, nil, vcdUnauthorizedError)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(IsUnauthorized(err), Equals, true)
+	c.Assert(IsNotFound(err), Equals, false)
+
+	// Busy errors are retried by default, send the request only once
+	s.client.Client.RetryPolicy = &NoRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	testServer.Response(400, nil, vcdBusyError)
+	err = s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(IsBusy(err), Equals, true)
+
+	c.Assert(IsBusy(&APIError{Message: "The entity gateway is busy completing an operation."}), Equals, true)
+	c.Assert(IsBusy(&APIError{Message: "The entity network is busy, cannot proceed with the operation."}), Equals, true)
+	c.Assert(IsBusy(errors.New("is busy completing an operation")), Equals, false)
+
+}
+
+var vcdUnauthorizedError = `
+<Error xmlns="http://www.vmware.com/vcloud/v1.5" message="This operation is denied." majorErrorCode="401" minorErrorCode="ACCESS_TO_RESOURCE_IS_FORBIDDEN"/>
+	`
+
+var vcdBusyError = `
+<Error xmlns="http://www.vmware.com/vcloud/v1.5" message="The requested operation could not be executed since vApp &quot;vApp&quot; is busy." majorErrorCode="400" minorErrorCode="BUSY_ENTITY"/>
+	`
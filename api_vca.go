@@ -5,6 +5,7 @@
 package govcloudair
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -59,7 +60,7 @@ type vCloudSession struct {
 
 //
 
-func (c *VAClient) vaauthorize(user, pass string) (u url.URL, err error) {
+func (c *VAClient) vaauthorize(ctx context.Context, user, pass string) (u url.URL, err error) {
 
 	if user == "" {
 		user = os.Getenv("VCLOUDAIR_USERNAME")
@@ -73,15 +74,15 @@ func (c *VAClient) vaauthorize(user, pass string) (u url.URL, err error) {
 	s.Path += "/vchs/sessions"
 
 	// No point in checking for errors here
-	req := c.Client.NewRequest(map[string]string{}, "POST", s, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", s, nil)
 
 	// Set Basic Authentication Header
 	req.SetBasicAuth(user, pass)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.6")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
 		return url.URL{}, err
 	}
@@ -93,7 +94,7 @@ func (c *VAClient) vaauthorize(user, pass string) (u url.URL, err error) {
 	session := new(session)
 
 	if err = decodeBody(resp, session); err != nil {
-		return url.URL{}, fmt.Errorf("error decoding session response: %s", err)
+		return url.URL{}, fmt.Errorf("error decoding session response: %w", err)
 	}
 
 	// Loop in the session struct to find right service and compute resource.
@@ -106,29 +107,29 @@ func (c *VAClient) vaauthorize(user, pass string) (u url.URL, err error) {
 	return url.URL{}, fmt.Errorf("couldn't find a Service List in current session")
 }
 
-func (c *VAClient) vaacquireservice(s url.URL, cid string) (u url.URL, err error) {
+func (c *VAClient) vaacquireservice(ctx context.Context, s url.URL, cid string) (u url.URL, err error) {
 
 	if cid == "" {
 		cid = os.Getenv("VCLOUDAIR_COMPUTEID")
 	}
 
-	req := c.Client.NewRequest(map[string]string{}, "GET", s, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "GET", s, nil)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.6")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
 	// Set Authorization Header for vCA
 	req.Header.Add("x-vchs-authorization", c.VAToken)
 
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return url.URL{}, fmt.Errorf("error processing compute action: %s", err)
+		return url.URL{}, fmt.Errorf("error processing compute action: %w", err)
 	}
 
 	services := new(services)
 
 	if err = decodeBody(resp, services); err != nil {
-		return url.URL{}, fmt.Errorf("error decoding services response: %s", err)
+		return url.URL{}, fmt.Errorf("error decoding services response: %w", err)
 	}
 
 	// Loop in the Services struct to find right service and compute resource.
@@ -142,30 +143,30 @@ func (c *VAClient) vaacquireservice(s url.URL, cid string) (u url.URL, err error
 	return url.URL{}, fmt.Errorf("couldn't find a Compute Resource in current service list")
 }
 
-func (c *VAClient) vaacquirecompute(s url.URL, vid string) (u url.URL, err error) {
+func (c *VAClient) vaacquirecompute(ctx context.Context, s url.URL, vid string) (u url.URL, err error) {
 
 	if vid == "" {
 		vid = os.Getenv("VCLOUDAIR_VDCID")
 	}
 
-	req := c.Client.NewRequest(map[string]string{}, "GET", s, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "GET", s, nil)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.6")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
 	// Set Authorization Header
 	req.Header.Add("x-vchs-authorization", c.VAToken)
 
 	// TODO: wrap into checkresp to parse error
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return url.URL{}, fmt.Errorf("error processing compute action: %s", err)
+		return url.URL{}, fmt.Errorf("error processing compute action: %w", err)
 	}
 
 	computeresources := new(computeResources)
 
 	if err = decodeBody(resp, computeresources); err != nil {
-		return url.URL{}, fmt.Errorf("error decoding computeresources response: %s", err)
+		return url.URL{}, fmt.Errorf("error decoding computeresources response: %w", err)
 	}
 
 	// Iterate through the ComputeResources struct searching for the right
@@ -183,45 +184,42 @@ func (c *VAClient) vaacquirecompute(s url.URL, vid string) (u url.URL, err error
 	return url.URL{}, fmt.Errorf("couldn't find a VDC Resource in current Compute list")
 }
 
-func (c *VAClient) vagetbackendauth(s url.URL, cid string) error {
+func (c *VAClient) vagetbackendauth(ctx context.Context, s url.URL, cid string) error {
 
 	if cid == "" {
 		cid = os.Getenv("VCLOUDAIR_COMPUTEID")
 	}
 
-	req := c.Client.NewRequest(map[string]string{}, "POST", s, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", s, nil)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.6")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
 	// Set Authorization Header
 	req.Header.Add("x-vchs-authorization", c.VAToken)
 
 	// TODO: wrap into checkresp to parse error
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("error processing backend url action: %s", err)
+		return fmt.Errorf("error processing backend url action: %w", err)
 	}
 	defer resp.Body.Close()
 
 	vcloudsession := new(vCloudSession)
 
 	if err = decodeBody(resp, vcloudsession); err != nil {
-		return fmt.Errorf("error decoding vcloudsession response: %s", err)
+		return fmt.Errorf("error decoding vcloudsession response: %w", err)
 	}
 
 	// Get the backend session information
 	for _, s := range vcloudsession.VdcLink {
 		if s.Name == cid {
-			// Fetch the authorization token
-			c.Client.VCDToken = s.AuthorizationToken
-
-			// Fetch the authorization header
-			c.Client.VCDAuthHeader = s.AuthorizationHeader
+			// Fetch the authorization header and token
+			c.Client.setAuthToken(s.AuthorizationHeader, s.AuthorizationToken)
 
 			u, err := url.ParseRequestURI(s.HREF)
 			if err != nil {
-				return fmt.Errorf("error decoding href: %s", err)
+				return fmt.Errorf("error decoding href: %w", err)
 			}
 			c.Client.VCDVDCHREF = *u
 			return nil
@@ -259,6 +257,7 @@ func NewVAClient() (*VAClient, error) {
 					TLSHandshakeTimeout: 120 * time.Second,
 				},
 			},
+			Middleware: debugMiddleware(),
 		},
 	}
 	return &VAClient, nil
@@ -267,32 +266,40 @@ func NewVAClient() (*VAClient, error) {
 // Authenticate is an helper function that performs a complete login in vCloud
 // Air and in the backend vCloud Director instance.
 func (c *VAClient) Authenticate(username, password, computeid, vdcid string) (Vdc, error) {
+	return c.AuthenticateWithContext(context.Background(), username, password, computeid, vdcid)
+}
+
+// AuthenticateWithContext is like Authenticate but aborts the login sequence
+// as soon as ctx is cancelled or its deadline expires.
+func (c *VAClient) AuthenticateWithContext(ctx context.Context, username, password, computeid, vdcid string) (_ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VAClient.Authenticate")
+	defer func() { endSpan(span, err) }()
 	// Authorize
-	vaservicehref, err := c.vaauthorize(username, password)
+	vaservicehref, err := c.vaauthorize(ctx, username, password)
 	if err != nil {
-		return Vdc{}, fmt.Errorf("error Authorizing: %s", err)
+		return Vdc{}, fmt.Errorf("error Authorizing: %w", err)
 	}
 
 	// Get Service
-	vacomputehref, err := c.vaacquireservice(vaservicehref, computeid)
+	vacomputehref, err := c.vaacquireservice(ctx, vaservicehref, computeid)
 	if err != nil {
-		return Vdc{}, fmt.Errorf("error Acquiring Service: %s", err)
+		return Vdc{}, fmt.Errorf("error Acquiring Service: %w", err)
 	}
 
 	// Get Compute
-	vavdchref, err := c.vaacquirecompute(vacomputehref, vdcid)
+	vavdchref, err := c.vaacquirecompute(ctx, vacomputehref, vdcid)
 	if err != nil {
-		return Vdc{}, fmt.Errorf("error Acquiring Compute: %s", err)
+		return Vdc{}, fmt.Errorf("error Acquiring Compute: %w", err)
 	}
 
 	// Get Backend Authorization
-	if err = c.vagetbackendauth(vavdchref, computeid); err != nil {
-		return Vdc{}, fmt.Errorf("error Acquiring Backend Authorization: %s", err)
+	if err = c.vagetbackendauth(ctx, vavdchref, computeid); err != nil {
+		return Vdc{}, fmt.Errorf("error Acquiring Backend Authorization: %w", err)
 	}
 
-	v, err := c.Client.retrieveVDC()
+	v, err := c.Client.retrieveVDC(ctx)
 	if err != nil {
-		return Vdc{}, fmt.Errorf("error Acquiring VDC: %s", err)
+		return Vdc{}, fmt.Errorf("error Acquiring VDC: %w", err)
 	}
 
 	return v, nil
@@ -301,24 +308,34 @@ func (c *VAClient) Authenticate(username, password, computeid, vdcid string) (Vd
 
 // Disconnect performs a disconnection from the vCloud Air API endpoint.
 func (c *VAClient) Disconnect() error {
-	if c.Client.VCDToken == "" && c.Client.VCDAuthHeader == "" && c.VAToken == "" {
+	return c.DisconnectWithContext(context.Background())
+}
+
+// DisconnectWithContext performs a disconnection from the vCloud Air API
+// endpoint, bounded by ctx.
+func (c *VAClient) DisconnectWithContext(ctx context.Context) (err error) {
+	ctx, span := c.Client.startSpan(ctx, "VAClient.Disconnect")
+	defer func() { endSpan(span, err) }()
+	if header, token := c.Client.authToken(); token == "" && header == "" && c.VAToken == "" {
 		return fmt.Errorf("cannot disconnect, client is not authenticated")
 	}
 
 	s := c.VAEndpoint
 	s.Path += "/vchs/session"
 
-	req := c.Client.NewRequest(map[string]string{}, "DELETE", s, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "DELETE", s, nil)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.6")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
 	// Set Authorization Header
 	req.Header.Add("x-vchs-authorization", c.VAToken)
 
-	if _, err := checkResp(c.Client.Http.Do(req)); err != nil {
-		return fmt.Errorf("error processing session delete for vchs: %s", err)
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("error processing session delete for vchs: %w", err)
 	}
+	resp.Body.Close()
 
 	return nil
 }
@@ -8,6 +8,7 @@
 package govcloudair
 
 import (
+	"context"
 	"net/url"
 	"os"
 	"testing"
@@ -48,11 +49,11 @@ func (s *S) SetUpSuite(c *C) {
 	}
 
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	s.vdc, err = s.client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -82,7 +83,7 @@ func TestClient_vaauthorize(t *testing.T) {
 
 	// Set up a correct conversation
 	testServer.Response(201, authheader, vaauthorization)
-	_, err = client.vaauthorize("username", "password")
+	_, err = client.vaauthorize(context.Background(), "username", "password")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -97,7 +98,7 @@ func TestClient_vaauthorize(t *testing.T) {
 
 	// Test a correct response with a wrong status code
 	testServer.Response(404, authheader, notfoundErr)
-	_, err = client.vaauthorize("username", "password")
+	_, err = client.vaauthorize(context.Background(), "username", "password")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -105,7 +106,7 @@ func TestClient_vaauthorize(t *testing.T) {
 
 	// Test an API error
 	testServer.Response(500, authheader, vcdError)
-	_, err = client.vaauthorize("username", "password")
+	_, err = client.vaauthorize(context.Background(), "username", "password")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -113,7 +114,7 @@ func TestClient_vaauthorize(t *testing.T) {
 
 	// Test an API response that doesn't contain the param we're looking for.
 	testServer.Response(200, authheader, vaauthorizationErr)
-	_, err = client.vaauthorize("username", "password")
+	_, err = client.vaauthorize(context.Background(), "username", "password")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -121,7 +122,7 @@ func TestClient_vaauthorize(t *testing.T) {
 
 	// Test an un-parsable response.
 	testServer.Response(200, authheader, notfoundErr)
-	_, err = client.vaauthorize("username", "password")
+	_, err = client.vaauthorize(context.Background(), "username", "password")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -143,14 +144,14 @@ func TestClient_vaacquireservice(t *testing.T) {
 
 	// Test a correct conversation
 	testServer.Response(200, nil, vaservices)
-	vacomputehref, err := client.vaacquireservice(*aus, "CI123456-789")
+	vacomputehref, err := client.vaacquireservice(context.Background(), *aus, "CI123456-789")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	if vacomputehref.String() != "http://localhost:4444/api/vchs/compute/00000000-0000-0000-0000-000000000000" {
-		t.Fatalf("VAComputeHREF not set on client: %s", vacomputehref)
+		t.Fatalf("VAComputeHREF not set on client: %s", vacomputehref.String())
 	}
 
 	if client.Region != "US - Anywhere" {
@@ -161,7 +162,7 @@ func TestClient_vaacquireservice(t *testing.T) {
 
 	// Test a 404
 	testServer.Response(404, nil, notfoundErr)
-	_, err = client.vaacquireservice(*aus, "CI123456-789")
+	_, err = client.vaacquireservice(context.Background(), *aus, "CI123456-789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -169,7 +170,7 @@ func TestClient_vaacquireservice(t *testing.T) {
 
 	// Test an API error
 	testServer.Response(500, nil, vcdError)
-	_, err = client.vaacquireservice(*aus, "CI123456-789")
+	_, err = client.vaacquireservice(context.Background(), *aus, "CI123456-789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -177,7 +178,7 @@ func TestClient_vaacquireservice(t *testing.T) {
 
 	// Test an unknown Compute ID
 	testServer.Response(200, nil, vaservices)
-	_, err = client.vaacquireservice(*aus, "NOTVALID-789")
+	_, err = client.vaacquireservice(context.Background(), *aus, "NOTVALID-789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -185,7 +186,7 @@ func TestClient_vaacquireservice(t *testing.T) {
 
 	// Test an un-parsable response
 	testServer.Response(200, nil, notfoundErr)
-	_, err = client.vaacquireservice(*aus, "CI123456-789")
+	_, err = client.vaacquireservice(context.Background(), *aus, "CI123456-789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -206,28 +207,28 @@ func TestClient_vaacquirecompute(t *testing.T) {
 	client.Region = "US - Anywhere"
 
 	testServer.Response(200, nil, vacompute)
-	vavdchref, err := client.vaacquirecompute(*auc, "VDC12345-6789")
+	vavdchref, err := client.vaacquirecompute(context.Background(), *auc, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	if vavdchref.String() != "http://localhost:4444/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession" {
-		t.Fatalf("VAVDCHREF not set on client: %s", vavdchref)
+		t.Fatalf("VAVDCHREF not set on client: %s", vavdchref.String())
 	}
 
 	// Test client errors
 
 	// Test a 404
 	testServer.Response(404, nil, notfoundErr)
-	_, err = client.vaacquirecompute(*auc, "VDC12345-6789")
+	_, err = client.vaacquirecompute(context.Background(), *auc, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
 	}
 	// Test an API error
 	testServer.Response(500, nil, vcdError)
-	_, err = client.vaacquirecompute(*auc, "VDC12345-6789")
+	_, err = client.vaacquirecompute(context.Background(), *auc, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -235,7 +236,7 @@ func TestClient_vaacquirecompute(t *testing.T) {
 
 	// Test an unknown VDC ID
 	testServer.Response(200, nil, vacompute)
-	_, err = client.vaacquirecompute(*auc, "INVALID-6789")
+	_, err = client.vaacquirecompute(context.Background(), *auc, "INVALID-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -243,7 +244,7 @@ func TestClient_vaacquirecompute(t *testing.T) {
 
 	// Test an un-parsable response
 	testServer.Response(200, nil, notfoundErr)
-	_, err = client.vaacquirecompute(*auc, "VDC12345-6789")
+	_, err = client.vaacquirecompute(context.Background(), *auc, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -264,7 +265,7 @@ func TestClient_vagetbackendauth(t *testing.T) {
 	client.Region = "US - Anywhere"
 
 	testServer.Response(201, nil, vabackend)
-	err = client.vagetbackendauth(*aucs, "CI123456-789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "CI123456-789")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -277,42 +278,42 @@ func TestClient_vagetbackendauth(t *testing.T) {
 		t.Fatalf("VCDAuthHeader not set on client: %s", client.Client.VCDAuthHeader)
 	}
 	if client.Client.VCDVDCHREF.String() != "http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" {
-		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF)
+		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF.String())
 	}
 
 	// Test client errors
 
 	// Test a 404
 	testServer.Response(404, nil, notfoundErr)
-	err = client.vagetbackendauth(*aucs, "VDC12345-6789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
 	}
 	// Test an API error
 	testServer.Response(500, nil, vcdError)
-	err = client.vagetbackendauth(*aucs, "VDC12345-6789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
 	}
 	// Test an unknown backend VDC IC
 	testServer.Response(201, nil, vabackend)
-	err = client.vagetbackendauth(*aucs, "INVALID-6789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "INVALID-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
 	}
 	// Test an un-parsable response
 	testServer.Response(201, nil, notfoundErr)
-	err = client.vagetbackendauth(*aucs, "VDC12345-6789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
 	}
 	// Test a botched backend VDC IC
 	testServer.Response(201, nil, vabackendErr)
-	err = client.vagetbackendauth(*aucs, "VDC12345-6789")
+	err = client.vagetbackendauth(context.Background(), *aucs, "VDC12345-6789")
 	_ = testServer.WaitRequest()
 	if err == nil {
 		t.Fatalf("Request error not caught: %v", err)
@@ -336,7 +337,7 @@ func TestClient_vaauthorize_env(t *testing.T) {
 	}
 
 	testServer.Response(201, authheader, vaauthorization)
-	_, err = client.vaauthorize("", "")
+	_, err = client.vaauthorize(context.Background(), "", "")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -362,14 +363,14 @@ func TestClient_vaacquireservice_env(t *testing.T) {
 	client.VAToken = "012345678901234567890123456789"
 
 	testServer.Response(200, nil, vaservices)
-	vacomputehref, err := client.vaacquireservice(*aus, "")
+	vacomputehref, err := client.vaacquireservice(context.Background(), *aus, "")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	if vacomputehref.String() != "http://localhost:4444/api/vchs/compute/00000000-0000-0000-0000-000000000000" {
-		t.Fatalf("VAComputeHREF not set on client: %s", vacomputehref)
+		t.Fatalf("VAComputeHREF not set on client: %s", vacomputehref.String())
 	}
 
 	if client.Region != "US - Anywhere" {
@@ -393,14 +394,14 @@ func TestClient_vaacquirecompute_env(t *testing.T) {
 	client.Region = "US - Anywhere"
 
 	testServer.Response(200, nil, vacompute)
-	vavdchref, err := client.vaacquirecompute(*auc, "")
+	vavdchref, err := client.vaacquirecompute(context.Background(), *auc, "")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	if vavdchref.String() != "http://localhost:4444/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession" {
-		t.Fatalf("VAVDCHREF not set on client: %s", vavdchref)
+		t.Fatalf("VAVDCHREF not set on client: %s", vavdchref.String())
 	}
 
 }
@@ -420,7 +421,7 @@ func TestClient_vagetbackendauth_env(t *testing.T) {
 	client.Region = "US - Anywhere"
 
 	testServer.Response(201, nil, vabackend)
-	err = client.vagetbackendauth(*aucs, "")
+	err = client.vagetbackendauth(context.Background(), *aucs, "")
 	_ = testServer.WaitRequest()
 	if err != nil {
 		t.Fatalf("err: %v", err)
@@ -433,7 +434,7 @@ func TestClient_vagetbackendauth_env(t *testing.T) {
 		t.Fatalf("VCDAuthHeader not set on client: %s", client.Client.VCDAuthHeader)
 	}
 	if client.Client.VCDVDCHREF.String() != "http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" {
-		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF)
+		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF.String())
 	}
 
 }
@@ -490,11 +491,11 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 	// Botched auth
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{401, nil, vcdError},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 401, Headers: nil, Body: vcdError},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -506,11 +507,11 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 	// Botched services
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{500, nil, vcdError},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 500, Headers: nil, Body: vcdError},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -522,11 +523,11 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 	// Botched compute
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{500, nil, vcdError},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 500, Headers: nil, Body: vcdError},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -538,11 +539,11 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 	// Botched backend
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{500, nil, vcdError},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 500, Headers: nil, Body: vcdError},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -554,11 +555,11 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 	// Botched vdc
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{500, nil, vcdError},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 500, Headers: nil, Body: vcdError},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -570,7 +571,7 @@ func TestVAClient_Authenticate(t *testing.T) {
 
 }
 
-func makeClient(t *testing.T) VAClient {
+func makeClient(t *testing.T) *VAClient {
 
 	testServer.Start()
 	var err error
@@ -581,11 +582,11 @@ func makeClient(t *testing.T) VAClient {
 	}
 
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{201, nil, vabackend},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 201, Headers: nil, Body: vabackend},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
@@ -612,10 +613,10 @@ func makeClient(t *testing.T) VAClient {
 		t.Fatalf("VCDAuthHeader not set on client: %s", client.Client.VCDAuthHeader)
 	}
 	if client.Client.VCDVDCHREF.String() != "http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" {
-		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF)
+		t.Fatalf("VDC not set on client: %s", client.Client.VCDVDCHREF.String())
 	}
 
-	return *client
+	return client
 }
 
 func TestClient_parseErr(t *testing.T) {
@@ -629,11 +630,11 @@ func TestClient_parseErr(t *testing.T) {
 
 	// I'M A TEAPOT!
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/vchs/sessions":                                                                                            testutil.Response{201, authheader, vaauthorization},
-		"/api/vchs/services":                                                                                            testutil.Response{200, nil, vaservices},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{200, nil, vacompute},
-		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{418, nil, notfoundErr},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{200, nil, vdcExample},
+		"/api/vchs/sessions":                                                                                            testutil.Response{Status: 201, Headers: authheader, Body: vaauthorization},
+		"/api/vchs/services":                                                                                            testutil.Response{Status: 200, Headers: nil, Body: vaservices},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000":                                                        testutil.Response{Status: 200, Headers: nil, Body: vacompute},
+		"/api/vchs/compute/00000000-0000-0000-0000-000000000000/vdc/00000000-0000-0000-0000-000000000000/vcloudsession": testutil.Response{Status: 418, Headers: nil, Body: notfoundErr},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                                                                 testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	_, err = client.Authenticate("username", "password", "CI123456-789", "VDC12345-6789")
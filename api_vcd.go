@@ -1,6 +1,7 @@
 package govcloudair
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/http"
@@ -10,54 +11,75 @@ import (
 	"time"
 )
 
+// VCDClient is a client to a vCloud Director endpoint. Like Client, it is safe
+// for concurrent use once authenticated.
 type VCDClient struct {
 	OrgHREF     url.URL // vCloud Director OrgRef
 	Org         Org     // Org
 	OrgVdc      Vdc     // Org vDC
 	Client      Client  // Client for the underlying VCD instance
+	loginHREF   url.URL // HREF for the login API
 	sessionHREF url.URL // HREF for the session API
 	QueryHREF   url.URL // HREF for the query API
-	Mutex       sync.Mutex
+	// Deprecated: Mutex isn't used by the client, which is safe for
+	// concurrent use once authenticated, see Client.
+	Mutex sync.Mutex
 }
 
-type supportedVersions struct {
-	VersionInfo struct {
-		Version  string `xml:"Version"`
-		LoginUrl string `xml:"LoginUrl"`
-	} `xml:"VersionInfo"`
-}
-
-func (c *VCDClient) vcdloginurl() error {
+func (c *VCDClient) vcdloginurl(ctx context.Context) error {
 
 	s := c.Client.VCDVDCHREF
 	s.Path += "/versions"
 
-	// No point in checking for errors here
-	req := c.Client.NewRequest(map[string]string{}, "GET", s, nil)
+	// No point in checking for errors here, the versions are public so don't
+	// send the token of a possibly expired session
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "GET", s, nil)
+	if header, _ := c.Client.authToken(); header != "" {
+		req.Header.Del(header)
+	}
 
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	supportedVersions := new(supportedVersions)
+	supportedVersions := new(SupportedVersions)
 
 	err = decodeBody(resp, supportedVersions)
 
 	if err != nil {
-		return fmt.Errorf("error decoding versions response: %s", err)
+		return fmt.Errorf("error decoding versions response: %w", err)
 	}
 
-	u, err := url.Parse(supportedVersions.VersionInfo.LoginUrl)
+	// Pick the highest version both sides support, within the caller pins.
+	version, err := supportedVersions.Negotiate(c.Client.MinAPIVersion, c.Client.MaxAPIVersion)
 	if err != nil {
+		return fmt.Errorf("error negotiating API version: %w", err)
+	}
+
+	u, err := url.Parse(version.LoginUrl)
+	if err != nil || version.LoginUrl == "" {
 		return fmt.Errorf("couldn't find a LoginUrl in versions")
 	}
-	c.sessionHREF = *u
+	c.Client.APIVersion = version.Version
+	c.loginHREF = *u
 	return nil
 }
 
-func (c *VCDClient) vcdauthorize(user, pass, org string) error {
+func (c *VCDClient) vcdauthorize(ctx context.Context, user, pass, org string) error {
+
+	session, err := c.vcdlogin(ctx, user, pass, org)
+	if err != nil {
+		return err
+	}
+
+	return c.loadSession(session)
+}
+
+// vcdlogin logs in and stores the session token. It leaves the session HREFs
+// alone, so it is safe to call while other goroutines use the client.
+func (c *VCDClient) vcdlogin(ctx context.Context, user, pass, org string) (*session, error) {
 
 	if user == "" {
 		user = os.Getenv("VCLOUD_USERNAME")
@@ -71,39 +93,54 @@ func (c *VCDClient) vcdauthorize(user, pass, org string) error {
 		org = os.Getenv("VCLOUD_ORG")
 	}
 
+	// Newer releases issue bearer tokens through the cloudapi
+	if c.Client.SupportsVersion(BearerTokenAPIVersion) {
+		return c.vcdbearerlogin(ctx, user, pass, org)
+	}
+
 	// No point in checking for errors here
-	req := c.Client.NewRequest(map[string]string{}, "POST", c.sessionHREF, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", c.loginHREF, nil)
 
-	// Set Basic Authentication Header
+	// Set Basic Authentication Header, dropping the token of an expired
+	// session when logging in again
+	if header, _ := c.Client.authToken(); header != "" {
+		req.Header.Del(header)
+	}
 	req.SetBasicAuth(user+"@"+org, pass)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/*+xml;version=5.5")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/*+xml"))
 
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// Store the authentication header
-	c.Client.VCDToken = resp.Header.Get("x-vcloud-authorization")
-	c.Client.VCDAuthHeader = "x-vcloud-authorization"
+	c.Client.setAuthToken("x-vcloud-authorization", resp.Header.Get("x-vcloud-authorization"))
 
 	session := new(session)
 	err = decodeBody(resp, session)
 
 	if err != nil {
-		fmt.Errorf("error decoding session response: %s", err)
+		return nil, fmt.Errorf("error decoding session response: %w", err)
 	}
 
+	return session, nil
+}
+
+// loadSession reads the organization, query and logout HREFs from the links
+// of a session.
+func (c *VCDClient) loadSession(session *session) error {
+
 	org_found := false
 	// Loop in the session struct to find the organization and query api.
 	for _, s := range session.Link {
 		if s.Type == "application/vnd.vmware.vcloud.org+xml" && s.Rel == "down" {
 			u, err := url.Parse(s.HREF)
 			if err != nil {
-				return fmt.Errorf("couldn't find a Organization in current session, %v", err)
+				return fmt.Errorf("couldn't find a Organization in current session, %w", err)
 			}
 			c.OrgHREF = *u
 			org_found = true
@@ -111,7 +148,7 @@ func (c *VCDClient) vcdauthorize(user, pass, org string) error {
 		if s.Type == "application/vnd.vmware.vcloud.query.queryList+xml" && s.Rel == "down" {
 			u, err := url.Parse(s.HREF)
 			if err != nil {
-				return fmt.Errorf("couldn't find a Query API in current session, %v", err)
+				return fmt.Errorf("couldn't find a Query API in current session, %w", err)
 			}
 			c.QueryHREF = *u
 		}
@@ -126,7 +163,7 @@ func (c *VCDClient) vcdauthorize(user, pass, org string) error {
 		if s.Rel == "remove" {
 			u, err := url.Parse(s.HREF)
 			if err != nil {
-				return fmt.Errorf("couldn't find a logout HREF in current session, %v", err)
+				return fmt.Errorf("couldn't find a logout HREF in current session, %w", err)
 			}
 			c.sessionHREF = *u
 			session_found = true
@@ -139,20 +176,26 @@ func (c *VCDClient) vcdauthorize(user, pass, org string) error {
 }
 
 func (c *VCDClient) RetrieveOrg(vcdname string) (Org, error) {
+	return c.RetrieveOrgWithContext(context.Background(), vcdname)
+}
 
-	req := c.Client.NewRequest(map[string]string{}, "GET", c.OrgHREF, nil)
-	req.Header.Add("Accept", "application/*+xml;version=5.5")
+func (c *VCDClient) RetrieveOrgWithContext(ctx context.Context, vcdname string) (_ Org, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.RetrieveOrg")
+	defer func() { endSpan(span, err) }()
+
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "GET", c.OrgHREF, nil)
+	req.Header.Add("Accept", c.Client.acceptHeader("application/*+xml"))
 
 	// TODO: wrap into checkresp to parse error
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return Org{}, fmt.Errorf("error retreiving org: %s", err)
+		return Org{}, fmt.Errorf("error retreiving org: %w", err)
 	}
 
 	org := NewOrg(&c.Client)
 
 	if err = decodeBody(resp, org.Org); err != nil {
-		return Org{}, fmt.Errorf("error decoding org response: %s", err)
+		return Org{}, fmt.Errorf("error decoding org response: %w", err)
 	}
 
 	// Get the VDC ref from the Org
@@ -180,7 +223,7 @@ func NewVCDClient(vcdEndpoint url.URL, insecure bool) *VCDClient {
 
 	return &VCDClient{
 		Client: Client{
-			APIVersion: "5.5",
+			APIVersion: MinSupportedAPIVersion,
 			VCDVDCHREF: vcdEndpoint,
 			Http: http.Client{
 				Transport: &http.Transport{
@@ -191,34 +234,50 @@ func NewVCDClient(vcdEndpoint url.URL, insecure bool) *VCDClient {
 					TLSHandshakeTimeout: 120 * time.Second,
 				},
 			},
+			Middleware: debugMiddleware(),
 		},
 	}
 }
 
 // Authenticate is an helper function that performs a login in vCloud Director.
 func (c *VCDClient) Authenticate(username, password, org, vdcname string) (Org, Vdc, error) {
+	return c.AuthenticateWithContext(context.Background(), username, password, org, vdcname)
+}
+
+// AuthenticateWithContext is like Authenticate but aborts the login sequence
+// as soon as ctx is cancelled or its deadline expires.
+func (c *VCDClient) AuthenticateWithContext(ctx context.Context, username, password, org, vdcname string) (_ Org, _ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.Authenticate")
+	defer func() { endSpan(span, err) }()
 
 	// LoginUrl
-	err := c.vcdloginurl()
+	err = c.vcdloginurl(ctx)
 	if err != nil {
-		return Org{}, Vdc{}, fmt.Errorf("error finding LoginUrl: %s", err)
+		return Org{}, Vdc{}, fmt.Errorf("error finding LoginUrl: %w", err)
 	}
 	// Authorize
-	err = c.vcdauthorize(username, password, org)
+	err = c.vcdauthorize(ctx, username, password, org)
 	if err != nil {
-		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %s", err)
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
 	}
 
+	return c.retrieveOrgAndVDC(ctx, vdcname)
+}
+
+// retrieveOrgAndVDC fetches the organization and the organization vDC named
+// vdcname once logged in.
+func (c *VCDClient) retrieveOrgAndVDC(ctx context.Context, vdcname string) (Org, Vdc, error) {
+
 	// Get Org
-	o, err := c.RetrieveOrg(vdcname)
+	o, err := c.RetrieveOrgWithContext(ctx, vdcname)
 	if err != nil {
-		return Org{}, Vdc{}, fmt.Errorf("error acquiring Org: %s", err)
+		return Org{}, Vdc{}, fmt.Errorf("error acquiring Org: %w", err)
 	}
 
-	vdc, err := c.Client.retrieveVDC()
+	vdc, err := c.Client.retrieveVDC(ctx)
 
 	if err != nil {
-		return Org{}, Vdc{}, fmt.Errorf("error retrieving the organization VDC")
+		return Org{}, Vdc{}, fmt.Errorf("error retrieving the organization VDC: %w", err)
 	}
 
 	return o, vdc, nil
@@ -226,20 +285,31 @@ func (c *VCDClient) Authenticate(username, password, org, vdcname string) (Org,
 
 // Disconnect performs a disconnection from the vCloud Director API endpoint.
 func (c *VCDClient) Disconnect() error {
-	if c.Client.VCDToken == "" && c.Client.VCDAuthHeader == "" {
+	return c.DisconnectWithContext(context.Background())
+}
+
+// DisconnectWithContext performs a disconnection from the vCloud Director API
+// endpoint, bounded by ctx.
+func (c *VCDClient) DisconnectWithContext(ctx context.Context) (err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.Disconnect")
+	defer func() { endSpan(span, err) }()
+	header, token := c.Client.authHeader()
+	if token == "" && header == "" {
 		return fmt.Errorf("cannot disconnect, client is not authenticated")
 	}
 
-	req := c.Client.NewRequest(map[string]string{}, "DELETE", c.sessionHREF, nil)
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "DELETE", c.sessionHREF, nil)
 
 	// Add the Accept header for vCA
-	req.Header.Add("Accept", "application/xml;version=5.5")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/xml"))
 
 	// Set Authorization Header
-	req.Header.Add(c.Client.VCDAuthHeader, c.Client.VCDToken)
+	req.Header.Set(header, token)
 
-	if _, err := checkResp(c.Client.Http.Do(req)); err != nil {
-		return fmt.Errorf("error processing session delete for vCloud Director: %s", err)
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("error processing session delete for vCloud Director: %w", err)
 	}
+	resp.Body.Close()
 	return nil
 }
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// SAMLToken is a signed SAML assertion issued by the identity provider of an
+// organization.
+type SAMLToken struct {
+	Assertion []byte // SAML assertion as issued by the identity provider, in XML
+
+	// Holder-of-key tokens are also signed by the client, Signature is the
+	// base64 encoded signature of the assertion and SignatureAlgorithm the
+	// algorithm used, e.g. SHA256withRSA. Leave both empty for bearer tokens.
+	Signature          string
+	SignatureAlgorithm string
+}
+
+// SAMLTokenProvider returns a SAML token for the given organization, from an
+// identity provider for instance.
+type SAMLTokenProvider func(ctx context.Context, org string) (*SAMLToken, error)
+
+// signHeader returns the value of the Authorization header presenting the
+// token to the login API of org.
+func (t *SAMLToken) signHeader(org string) (string, error) {
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(t.Assertion); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+
+	params := []string{
+		fmt.Sprintf("token=%q", base64.StdEncoding.EncodeToString(buf.Bytes())),
+		fmt.Sprintf("org=%q", org),
+	}
+	if t.Signature != "" {
+		params = append(params, fmt.Sprintf("signature=%q", t.Signature))
+	}
+	if t.SignatureAlgorithm != "" {
+		params = append(params, fmt.Sprintf("signature_alg=%q", t.SignatureAlgorithm))
+	}
+	return "SIGN " + strings.Join(params, ","), nil
+}
+
+// AuthenticateSAML logs in to org with a SAML token returned by provider,
+// for organizations using a federated identity provider, and returns the
+// organization and the organization vDC named vdcname.
+func (c *VCDClient) AuthenticateSAML(org, vdcname string, provider SAMLTokenProvider) (Org, Vdc, error) {
+	return c.AuthenticateSAMLWithContext(context.Background(), org, vdcname, provider)
+}
+
+// AuthenticateSAMLWithContext is like AuthenticateSAML but aborts as soon as
+// ctx is cancelled.
+func (c *VCDClient) AuthenticateSAMLWithContext(ctx context.Context, org, vdcname string, provider SAMLTokenProvider) (_ Org, _ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.AuthenticateSAML")
+	defer func() { endSpan(span, err) }()
+
+	// LoginUrl
+	if err := c.vcdloginurl(ctx); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error finding LoginUrl: %w", err)
+	}
+
+	token, err := provider(ctx, org)
+	if err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error getting SAML token: %w", err)
+	}
+	if token == nil || len(token.Assertion) == 0 {
+		return Org{}, Vdc{}, fmt.Errorf("error getting SAML token: empty assertion")
+	}
+
+	sign, err := token.signHeader(org)
+	if err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error encoding SAML token: %w", err)
+	}
+
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", c.loginHREF, nil)
+	if header, _ := c.Client.authToken(); header != "" {
+		req.Header.Del(header)
+	}
+	req.Header.Set("Authorization", sign)
+	req.Header.Del("Accept")
+	req.Header.Add("Accept", c.Client.acceptHeader("application/*+xml"))
+
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Newer releases answer with a bearer token as well.
+	if bearer := resp.Header.Get(BearerTokenHeader); bearer != "" {
+		c.Client.setAuthToken(bearerAuthHeader, bearer)
+	} else {
+		c.Client.setAuthToken("x-vcloud-authorization", resp.Header.Get("x-vcloud-authorization"))
+	}
+
+	session := new(session)
+	if err = decodeBody(resp, session); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error decoding session response: %w", err)
+	}
+	if err = c.loadSession(session); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
+	}
+
+	return c.retrieveOrgAndVDC(ctx, vdcname)
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_SAMLAuthentication(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp", "vm1")
+
+	idp, err := testutil.NewSAMLIdP("https://idp.example.com")
+	c.Assert(err, IsNil)
+	fake.SAMLKey = idp.PublicKey()
+
+	provider := func(ctx context.Context, org string) (*SAMLToken, error) {
+		assertion, err := idp.Assertion("federated-user", org)
+		return &SAMLToken{Assertion: assertion}, err
+	}
+
+	client := NewVCDClient(fake.Endpoint(), false)
+	_, vdc, err := client.AuthenticateSAML(fake.Org, fake.Vdc, provider)
+	c.Assert(err, IsNil)
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+
+	// Assertions signed by another IdP are refused
+	rogue, err := testutil.NewSAMLIdP("https://idp.example.com")
+	c.Assert(err, IsNil)
+	client = NewVCDClient(fake.Endpoint(), false)
+	_, _, err = client.AuthenticateSAML(fake.Org, fake.Vdc, func(ctx context.Context, org string) (*SAMLToken, error) {
+		assertion, err := rogue.Assertion("federated-user", org)
+		return &SAMLToken{Assertion: assertion}, err
+	})
+	c.Assert(IsUnauthorized(err), Equals, true, Commentf("got %v", err))
+
+}
@@ -1,8 +1,10 @@
 package govcloudair
 
 import (
+	"context"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/ukcloud/govcloudair/testutil"
 	. "gopkg.in/check.v1"
@@ -31,7 +33,7 @@ func (s *K) SetUpSuite(c *C) {
 	}
 
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/versions": testutil.Response{200, map[string]string{}, vcdversions},
+		"/api/versions": testutil.Response{Status: 200, Headers: map[string]string{}, Body: vcdversions},
 	})
 
 	s.org, s.vdc, err = s.client.Authenticate("username", "password", "organization", "VDC")
@@ -56,18 +58,23 @@ func TestClient_getloginurl(t *testing.T) {
 
 	// Set up a correct conversation
 	testServer.ResponseMap(200, testutil.ResponseMap{
-		"/api/versions": testutil.Response{200, nil, vcdversions},
+		"/api/versions": testutil.Response{Status: 200, Headers: nil, Body: vcdversions},
 	})
 
-	err = client.vcdloginurl()
+	err = client.vcdloginurl(context.Background())
 	testServer.Flush()
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
 	// Test if token is correctly set on client.
-	if client.sessionHREF.Path != "/api/sessions" {
-		t.Fatalf("Getting LoginUrl failed, url: %s", client.sessionHREF.Path)
+	if client.loginHREF.Path != "/api/sessions" {
+		t.Fatalf("Getting LoginUrl failed, url: %s", client.loginHREF.Path)
+	}
+
+	// Test if the highest mutually supported version was picked.
+	if client.Client.APIVersion != "5.5" {
+		t.Fatalf("Negotiating API version failed, version: %s", client.Client.APIVersion)
 	}
 }
 
@@ -83,10 +90,10 @@ func TestVCDClient_Authenticate(t *testing.T) {
 
 	// OK auth
 	testServer.ResponseMap(5, testutil.ResponseMap{
-		"/api/versions":                                 testutil.Response{200, nil, vcdversions},
-		"/api/sessions":                                 testutil.Response{201, vcdauthheader, vcdsessions},
-		"/api/org/00000000-0000-0000-0000-000000000000": testutil.Response{201, vcdauthheader, vcdorg},
-		"/api/vdc/00000000-0000-0000-0000-000000000000": testutil.Response{201, vcdauthheader, vcdorg},
+		"/api/versions":                                 testutil.Response{Status: 200, Headers: nil, Body: vcdversions},
+		"/api/sessions":                                 testutil.Response{Status: 201, Headers: vcdauthheader, Body: vcdsessions},
+		"/api/org/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 201, Headers: vcdauthheader, Body: vcdorg},
+		"/api/vdc/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 201, Headers: vcdauthheader, Body: vcdorg},
 	})
 
 	org, _, err := client.Authenticate("username", "password", "organization", "organization vDC")
@@ -100,11 +107,98 @@ func TestVCDClient_Authenticate(t *testing.T) {
 	}
 }
 
+// Test_EndToEnd drives a provisioning workflow against the stateful fake
+// vCloud Director.
+func (s *S) Test_EndToEnd(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddCatalogItem("catalog", "template", "vm1")
+	fake.AddEdgeGateway("edge")
+
+	client, org, vdc := fakeVCDLogin(c, fake)
+	c.Assert(vdc.Vdc.Name, Equals, fake.Vdc)
+	c.Assert(client.Client.APIVersion, Equals, "27.0")
+	c.Assert(client.SupportsVersion("9.0"), Equals, true)
+
+	// Catalogs
+	catalog, err := org.FindCatalog("catalog")
+	c.Assert(err, IsNil)
+	item, err := catalog.FindCatalogItem("template")
+	c.Assert(err, IsNil)
+	template, err := item.GetVAppTemplate()
+	c.Assert(err, IsNil)
+	c.Assert(template.VAppTemplate.Children, NotNil)
+	c.Assert(template.VAppTemplate.Children.VM[0].Name, Equals, "vm1")
+
+	// A composed vApp shows up in the vDC
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, NotNil)
+	err = vdc.ComposeRawVApp("vapp")
+	c.Assert(err, IsNil)
+	vapp, err := vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+	status, err := vapp.GetStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "POWERED_OFF")
+
+	// Power operations go through tasks
+	task, err := vapp.PowerOn()
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	status, err = vapp.GetStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "POWERED_ON")
+
+	// Tasks move from queued to running to success
+	fake.TaskDuration = 200 * time.Millisecond
+	task, err = vapp.PowerOff()
+	c.Assert(err, IsNil)
+	c.Assert(task.Task.Status, Equals, "queued")
+	time.Sleep(120 * time.Millisecond)
+	c.Assert(task.Refresh(), IsNil)
+	c.Assert(task.Task.Status, Equals, "running")
+	status, err = vapp.GetStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "POWERED_ON")
+	time.Sleep(100 * time.Millisecond)
+	c.Assert(task.Refresh(), IsNil)
+	c.Assert(task.Task.Status, Equals, "success")
+	status, err = vapp.GetStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "POWERED_OFF")
+	fake.TaskDuration = 0
+
+	// Edge gateway configuration is persisted
+	edge, err := vdc.FindEdgeGateway("edge")
+	c.Assert(err, IsNil)
+	task, err = edge.CreateFirewallRules("allow", nil)
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	c.Assert(edge.Refresh(), IsNil)
+	c.Assert(edge.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration.FirewallService.DefaultAction, Equals, "allow")
+
+	// Deleting the vApp removes it from the vDC
+	task, err = vapp.Undeploy()
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	task, err = vapp.Delete()
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, NotNil)
+
+	// Logging out invalidates the session
+	c.Assert(client.Disconnect(), IsNil)
+	c.Assert(IsUnauthorized(vdc.Refresh()), Equals, true)
+
+}
+
 // status: 200
 var vcdversions = `
 <?xml version="1.0" encoding="UTF-8"?>
 <SupportedVersions xmlns="http://www.vmware.com/vcloud/versions" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.vmware.com/vcloud/versions http://localhost:4444/api/versions/schema/versions.xsd">
-    <VersionInfo>
+    <VersionInfo deprecated="true">
         <Version>1.5</Version>
         <LoginUrl>http://localhost:4444/api/sessions</LoginUrl>
         <MediaTypeMapping>
@@ -118,6 +212,14 @@ var vcdversions = `
             <SchemaLocation>http://localhost:4444/api/v1.5/schema/vmwextensions.xsd</SchemaLocation>
         </MediaTypeMapping>
     </VersionInfo>
+    <VersionInfo>
+        <Version>5.1</Version>
+        <LoginUrl>http://localhost:4444/api/sessions</LoginUrl>
+    </VersionInfo>
+    <VersionInfo>
+        <Version>5.5</Version>
+        <LoginUrl>http://localhost:4444/api/sessions</LoginUrl>
+    </VersionInfo>
 </SupportedVersions>
 `
 
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// BearerTokenAPIVersion is the first API version logging in through the
+// cloudapi, which issues bearer tokens instead of x-vcloud-authorization ones.
+const BearerTokenAPIVersion = "33.0"
+
+// BearerTokenHeader is the response header carrying bearer tokens.
+const BearerTokenHeader = "X-Vmware-Vcloud-Access-Token"
+
+// bearerAuthHeader is the request header carrying bearer tokens.
+const bearerAuthHeader = "Authorization"
+
+// apiTokenResponse is the response of the OAuth token endpoint.
+type apiTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// endpointHREF returns the URL of p relative to the root of the vCloud
+// Director endpoint, worked out from the login URL.
+func (c *VCDClient) endpointHREF(p string) url.URL {
+	u := c.loginHREF
+	u.Path = path.Join(path.Dir(path.Dir(u.Path)), p)
+	u.RawQuery = ""
+	return u
+}
+
+// vcdbearerlogin logs in through the cloudapi and stores the bearer token.
+func (c *VCDClient) vcdbearerlogin(ctx context.Context, user, pass, org string) (*session, error) {
+
+	s := c.endpointHREF("cloudapi/1.0.0/sessions")
+	if strings.EqualFold(org, "System") {
+		s = c.endpointHREF("cloudapi/1.0.0/sessions/provider")
+	}
+
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", s, nil)
+
+	// Set Basic Authentication Header, dropping the token of an expired
+	// session when logging in again
+	if header, _ := c.Client.authToken(); header != "" {
+		req.Header.Del(header)
+	}
+	req.Header.Del("Accept")
+	req.SetBasicAuth(user+"@"+org, pass)
+	req.Header.Add("Accept", c.Client.acceptHeader("application/json"))
+
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	token := resp.Header.Get(BearerTokenHeader)
+	if token == "" {
+		return nil, fmt.Errorf("couldn't find a bearer token in the login response")
+	}
+	c.Client.setAuthToken(bearerAuthHeader, token)
+
+	return c.getSession(ctx)
+}
+
+// getSession fetches the current session.
+func (c *VCDClient) getSession(ctx context.Context) (*session, error) {
+
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "GET", c.endpointHREF("api/session"), nil)
+
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	session := new(session)
+	if err = decodeBody(resp, session); err != nil {
+		return nil, fmt.Errorf("error decoding session response: %w", err)
+	}
+
+	return session, nil
+}
+
+// retrieveSession fetches the current session and loads its links.
+func (c *VCDClient) retrieveSession(ctx context.Context) error {
+	session, err := c.getSession(ctx)
+	if err != nil {
+		return err
+	}
+	return c.loadSession(session)
+}
+
+// isBearerToken reports whether token is a JWT, as opposed to a legacy
+// x-vcloud-authorization token.
+func isBearerToken(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// AuthenticateToken logs in with a token issued beforehand, a bearer token or
+// a legacy x-vcloud-authorization one, and returns the organization and the
+// organization vDC named vdcname.
+func (c *VCDClient) AuthenticateToken(token, vdcname string) (Org, Vdc, error) {
+	return c.AuthenticateTokenWithContext(context.Background(), token, vdcname)
+}
+
+// AuthenticateTokenWithContext is like AuthenticateToken but aborts as soon as
+// ctx is cancelled.
+func (c *VCDClient) AuthenticateTokenWithContext(ctx context.Context, token, vdcname string) (_ Org, _ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.AuthenticateToken")
+	defer func() { endSpan(span, err) }()
+
+	if token == "" {
+		return Org{}, Vdc{}, fmt.Errorf("cannot authenticate with an empty token")
+	}
+
+	// LoginUrl
+	if err := c.vcdloginurl(ctx); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error finding LoginUrl: %w", err)
+	}
+
+	if isBearerToken(token) {
+		c.Client.setAuthToken(bearerAuthHeader, token)
+	} else {
+		c.Client.setAuthToken("x-vcloud-authorization", token)
+	}
+
+	if err := c.retrieveSession(ctx); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
+	}
+
+	return c.retrieveOrgAndVDC(ctx, vdcname)
+}
+
+// AuthenticateRefreshToken logs in to org with an API refresh token, as
+// created in the user preferences of the tenant portal, and returns the
+// organization and the organization vDC named vdcname.
+func (c *VCDClient) AuthenticateRefreshToken(org, refreshToken, vdcname string) (Org, Vdc, error) {
+	return c.AuthenticateRefreshTokenWithContext(context.Background(), org, refreshToken, vdcname)
+}
+
+// AuthenticateRefreshTokenWithContext is like AuthenticateRefreshToken but
+// aborts as soon as ctx is cancelled.
+func (c *VCDClient) AuthenticateRefreshTokenWithContext(ctx context.Context, org, refreshToken, vdcname string) (_ Org, _ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.AuthenticateRefreshToken")
+	defer func() { endSpan(span, err) }()
+
+	// LoginUrl
+	if err := c.vcdloginurl(ctx); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error finding LoginUrl: %w", err)
+	}
+
+	s := c.endpointHREF("oauth/tenant/" + url.PathEscape(org) + "/token")
+	if strings.EqualFold(org, "System") {
+		s = c.endpointHREF("oauth/provider/token")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+
+	req := c.Client.NewRequestWithContext(ctx, map[string]string{}, "POST", s, strings.NewReader(form.Encode()))
+	if header, _ := c.Client.authToken(); header != "" {
+		req.Header.Del(header)
+	}
+	req.Header.Del("Accept")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	token := new(apiTokenResponse)
+	if err = json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error decoding token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return Org{}, Vdc{}, fmt.Errorf("couldn't find an access token in the token response")
+	}
+	c.Client.setAuthToken(bearerAuthHeader, token.AccessToken)
+
+	if err = c.retrieveSession(ctx); err != nil {
+		return Org{}, Vdc{}, fmt.Errorf("error authorizing: %w", err)
+	}
+
+	return c.retrieveOrgAndVDC(ctx, vdcname)
+}
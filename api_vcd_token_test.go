@@ -0,0 +1,58 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_BearerAuthentication(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.Versions = []string{"27.0", "33.0", "36.0"}
+	fake.AddVApp("vapp", "vm1")
+	fake.AddAPIToken("api-token")
+
+	// Username and password through the cloudapi
+	client := NewVCDClient(fake.Endpoint(), false)
+	_, vdc, err := client.Authenticate(fake.User, fake.Password, fake.Org, fake.Vdc)
+	c.Assert(err, IsNil)
+	c.Assert(client.Client.VCDAuthHeader, Equals, "Authorization")
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+
+	// Expired bearer sessions log in again through the cloudapi
+	client.EnableReauthentication(StaticCredentials(fake.User, fake.Password, fake.Org))
+	fake.ExpireSession()
+	c.Assert(vdc.Refresh(), IsNil)
+
+	// A refused bearer login is reported instead of logging in again
+	client.EnableReauthentication(StaticCredentials(fake.User, "wrong", fake.Org))
+	fake.ExpireSession()
+	err = vdc.Refresh()
+	c.Assert(err, ErrorMatches, ".*re-authentication failed.*")
+
+	// API refresh token
+	client = NewVCDClient(fake.Endpoint(), false)
+	_, _, err = client.AuthenticateRefreshToken(fake.Org, "wrong-token", fake.Vdc)
+	c.Assert(err, NotNil)
+	_, vdc, err = client.AuthenticateRefreshToken(fake.Org, "api-token", fake.Vdc)
+	c.Assert(err, IsNil)
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+
+	// Pre-issued bearer and legacy tokens
+	for _, bearer := range []bool{true, false} {
+		client = NewVCDClient(fake.Endpoint(), false)
+		_, vdc, err = client.AuthenticateToken(fake.IssueToken(bearer), fake.Vdc)
+		c.Assert(err, IsNil)
+		_, err = vdc.FindVAppByName("vapp")
+		c.Assert(err, IsNil)
+		c.Assert(client.Disconnect(), IsNil)
+	}
+
+}
@@ -5,8 +5,8 @@
 package govcloudair
 
 import (
+	"context"
 	"fmt"
-	"net/url"
 
 	types "github.com/ukcloud/govcloudair/types/v56"
 )
@@ -24,27 +24,20 @@ func NewCatalog(c *Client) *Catalog {
 }
 
 func (c *Catalog) FindCatalogItem(catalogitem string) (CatalogItem, error) {
+	return c.FindCatalogItemWithContext(context.Background(), catalogitem)
+}
+
+func (c *Catalog) FindCatalogItemWithContext(ctx context.Context, catalogitem string) (_ CatalogItem, err error) {
+	ctx, span := c.c.startSpan(ctx, "Catalog.FindCatalogItem")
+	defer func() { endSpan(span, err) }()
 
 	for _, cis := range c.Catalog.CatalogItems {
 		for _, ci := range cis.CatalogItem {
 			if ci.Name == catalogitem && ci.Type == "application/vnd.vmware.vcloud.catalogItem+xml" {
-				u, err := url.ParseRequestURI(ci.HREF)
-
-				if err != nil {
-					return CatalogItem{}, fmt.Errorf("error decoding catalog response: %s", err)
-				}
-
-				req := c.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-				resp, err := checkResp(c.c.Http.Do(req))
-				if err != nil {
-					return CatalogItem{}, fmt.Errorf("error retreiving catalog: %s", err)
-				}
-
 				cat := NewCatalogItem(c.c)
 
-				if err = decodeBody(resp, cat.CatalogItem); err != nil {
-					return CatalogItem{}, fmt.Errorf("error decoding catalog response: %s", err)
+				if err = c.c.getEntity(ctx, ci.HREF, "catalog item", cat.CatalogItem); err != nil {
+					return CatalogItem{}, err
 				}
 
 				// The request was successful
@@ -5,8 +5,7 @@
 package govcloudair
 
 import (
-	"fmt"
-	"net/url"
+	"context"
 
 	types "github.com/ukcloud/govcloudair/types/v56"
 )
@@ -24,23 +23,17 @@ func NewCatalogItem(c *Client) *CatalogItem {
 }
 
 func (ci *CatalogItem) GetVAppTemplate() (VAppTemplate, error) {
-	url, err := url.ParseRequestURI(ci.CatalogItem.Entity.HREF)
-
-	if err != nil {
-		return VAppTemplate{}, fmt.Errorf("error decoding catalogitem response: %s", err)
-	}
-
-	req := ci.c.NewRequest(map[string]string{}, "GET", *url, nil)
+	return ci.GetVAppTemplateWithContext(context.Background())
+}
 
-	resp, err := checkResp(ci.c.Http.Do(req))
-	if err != nil {
-		return VAppTemplate{}, fmt.Errorf("error retreiving vapptemplate: %s", err)
-	}
+func (ci *CatalogItem) GetVAppTemplateWithContext(ctx context.Context) (_ VAppTemplate, err error) {
+	ctx, span := ci.c.startSpan(ctx, "CatalogItem.GetVAppTemplate")
+	defer func() { endSpan(span, err) }()
 
 	cat := NewVAppTemplate(ci.c)
 
-	if err = decodeBody(resp, cat.VAppTemplate); err != nil {
-		return VAppTemplate{}, fmt.Errorf("error decoding vapptemplate response: %s", err)
+	if err = ci.c.getEntity(ctx, ci.CatalogItem.Entity.HREF, "vApp template", cat.VAppTemplate); err != nil {
+		return VAppTemplate{}, err
 	}
 
 	// The request was successful
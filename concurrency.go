@@ -0,0 +1,65 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// SetMaxConcurrentRequests limits the number of requests the client sends at
+// the same time, across every goroutine sharing it. Requests over the limit
+// wait for a slot, or until their context is cancelled. Use it to stay below
+// the per-user request throttling of vCloud Director when fanning out
+// operations. A request holds its slot until its response body is read to the
+// end or closed. Zero or less removes the limit. It can be called at any time,
+// requests already waiting keep the previous limit.
+func (c *Client) SetMaxConcurrentRequests(n int) {
+	var slots chan struct{}
+	if n > 0 {
+		slots = make(chan struct{}, n)
+	}
+	c.slots.Store(&slots)
+}
+
+// acquireSlot waits for a free request slot and returns the function releasing
+// it, which can be called more than once.
+func (c *Client) acquireSlot(ctx context.Context) (func(), error) {
+	p, _ := c.slots.Load().(*chan struct{})
+	if p == nil || *p == nil {
+		return func() {}, nil
+	}
+	slots := *p
+	select {
+	case slots <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-slots }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// slotBody is a response body holding the request slot of its request until
+// it is closed or read to the end, so that the limit of concurrent requests
+// also covers the transfer of response bodies.
+type slotBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *slotBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.release()
+	}
+	return n, err
+}
+
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_SlotHeldUntilBodyClosed(c *C) {
+
+	s.client.Client.SetMaxConcurrentRequests(1)
+	defer s.client.Client.SetMaxConcurrentRequests(0)
+
+	slotFree := func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		release, err := s.client.Client.acquireSlot(ctx)
+		if err != nil {
+			return false
+		}
+		release()
+		return true
+	}
+
+	u, _ := url.ParseRequestURI("http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000")
+
+	// Closing the body releases the slot
+	testServer.Response(200, nil, vdcExample)
+	resp, err := s.client.Client.doRequest(s.client.Client.NewRequest(map[string]string{}, "GET", *u, nil))
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+	c.Assert(slotFree(), Equals, false)
+	resp.Body.Close()
+	c.Assert(slotFree(), Equals, true)
+	resp.Body.Close()
+	c.Assert(slotFree(), Equals, true)
+
+	// So does reading it to the end
+	testServer.Response(200, nil, vdcExample)
+	resp, err = s.client.Client.doRequest(s.client.Client.NewRequest(map[string]string{}, "GET", *u, nil))
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+	c.Assert(slotFree(), Equals, false)
+	_, err = ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	c.Assert(slotFree(), Equals, true)
+
+	// Error responses don't hold it
+	testServer.Response(500, nil, vcdError)
+	_, err = s.client.Client.doRequest(s.client.Client.NewRequest(map[string]string{}, "GET", *u, nil))
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+	c.Assert(slotFree(), Equals, true)
+
+}
+
+func (s *S) Test_ConcurrentRequests(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp", "vm1")
+
+	client, _, vdc := fakeVCDLogin(c, fake)
+	client.EnableReauthentication(StaticCredentials(fake.User, fake.Password, fake.Org))
+	client.Client.SetMaxConcurrentRequests(3)
+	fake.Latency = 10 * time.Millisecond
+
+	// Fan out over one shared session, which expires half way
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 40; i++ {
+		if i == 20 {
+			fake.ExpireSession()
+		}
+		wg.Add(1)
+		go func(v Vdc) {
+			defer wg.Done()
+			vapp, err := v.FindVAppByName("vapp")
+			if err == nil {
+				_, err = vapp.GetStatus()
+			}
+			errs <- err
+		}(vdc)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Assert(err, IsNil)
+	}
+	c.Assert(fake.MaxInFlight() <= 3, Equals, true, Commentf("%d requests in flight", fake.MaxInFlight()))
+
+	// Waiting for a slot gives up with the context
+	client.Client.SetMaxConcurrentRequests(1)
+	release, err := client.Client.acquireSlot(context.Background())
+	c.Assert(err, IsNil)
+	defer release()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = vdc.RefreshWithContext(ctx)
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true, Commentf("got %v", err))
+
+}
@@ -6,14 +6,10 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
-	"log"
-	"net/http"
 	"net/url"
-	"os"
-	"regexp"
-	"time"
 
 	types "github.com/ukcloud/govcloudair/types/v56"
 )
@@ -31,9 +27,13 @@ func NewEdgeGateway(c *Client) *EdgeGateway {
 }
 
 func (e *EdgeGateway) AddDhcpPool(network *types.OrgVDCNetwork, dhcppool []interface{}) (Task, error) {
+	return e.AddDhcpPoolWithContext(context.Background(), network, dhcppool)
+}
+
+func (e *EdgeGateway) AddDhcpPoolWithContext(ctx context.Context, network *types.OrgVDCNetwork, dhcppool []interface{}) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.AddDhcpPool")
+	defer func() { endSpan(span, err) }()
 	newedgeconfig := e.EdgeGateway.Configuration.EdgeGatewayServiceConfiguration
-	log.Printf("[DEBUG] EDGE GATEWAY: %#v", newedgeconfig)
-	log.Printf("[DEBUG] EDGE GATEWAY SERVICE: %#v", newedgeconfig.GatewayDhcpService)
 	newdchpservice := &types.GatewayDhcpService{}
 	if newedgeconfig.GatewayDhcpService == nil {
 		newdchpservice.IsEnabled = true
@@ -84,37 +84,27 @@ func (e *EdgeGateway) AddDhcpPool(network *types.OrgVDCNetwork, dhcppool []inter
 
 	output, err := xml.MarshalIndent(newRules, "  ", "    ")
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
-	var resp *http.Response
-	for {
-		b := bytes.NewBufferString(xml.Header + string(output))
+	b := bytes.NewBufferString(xml.Header + string(output))
 
-		s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
-		s.Path += "/action/configureServices"
+	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
+	s.Path += "/action/configureServices"
 
-		req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
-		log.Printf("[DEBUG] POSTING TO URL: %s", s.Path)
-		log.Printf("[DEBUG] XML TO SEND:\n%s", b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
-		req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
+	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-		resp, err = checkResp(e.c.Http.Do(req))
-		if err != nil {
-			if v, _ := regexp.MatchString("is busy completing an operation.$", err.Error()); v {
-				time.Sleep(3 * time.Second)
-				continue
-			}
-			return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
-		}
-		break
+	resp, err := e.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -123,10 +113,22 @@ func (e *EdgeGateway) AddDhcpPool(network *types.OrgVDCNetwork, dhcppool []inter
 }
 
 func (e *EdgeGateway) RemoveNATMapping(nattype, externalIP, internalIP, port string) (Task, error) {
-	return e.RemoveNATPortMapping(nattype, externalIP, port, internalIP, port)
+	return e.RemoveNATMappingWithContext(context.Background(), nattype, externalIP, internalIP, port)
+}
+
+func (e *EdgeGateway) RemoveNATMappingWithContext(ctx context.Context, nattype, externalIP, internalIP, port string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.RemoveNATMapping")
+	defer func() { endSpan(span, err) }()
+	return e.RemoveNATPortMappingWithContext(ctx, nattype, externalIP, port, internalIP, port)
 }
 
 func (e *EdgeGateway) RemoveNATPortMapping(nattype, externalIP, externalPort string, internalIP, internalPort string) (Task, error) {
+	return e.RemoveNATPortMappingWithContext(context.Background(), nattype, externalIP, externalPort, internalIP, internalPort)
+}
+
+func (e *EdgeGateway) RemoveNATPortMappingWithContext(ctx context.Context, nattype, externalIP, externalPort string, internalIP, internalPort string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.RemoveNATPortMapping")
+	defer func() { endSpan(span, err) }()
 	// Find uplink interface
 	var uplink types.Reference
 	for _, gi := range e.EdgeGateway.Configuration.GatewayInterfaces.GatewayInterface {
@@ -154,10 +156,8 @@ func (e *EdgeGateway) RemoveNATPortMapping(nattype, externalIP, externalPort str
 			v.GatewayNatRule.OriginalIP == externalIP &&
 			v.GatewayNatRule.OriginalPort == externalPort &&
 			v.GatewayNatRule.Interface.HREF == uplink.HREF {
-			log.Printf("[DEBUG] REMOVING %s Rule: %#v", v.RuleType, v.GatewayNatRule)
 			continue
 		}
-		log.Printf("[DEBUG] KEEPING %s Rule: %#v", v.RuleType, v.GatewayNatRule)
 		newnatservice.NatRule = append(newnatservice.NatRule, v)
 	}
 
@@ -170,7 +170,7 @@ func (e *EdgeGateway) RemoveNATPortMapping(nattype, externalIP, externalPort str
 
 	output, err := xml.MarshalIndent(newRules, "  ", "    ")
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -178,22 +178,19 @@ func (e *EdgeGateway) RemoveNATPortMapping(nattype, externalIP, externalPort str
 	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
 	s.Path += "/action/configureServices"
 
-	req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
-	log.Printf("[DEBUG] POSTING TO URL: %s", s.Path)
-	log.Printf("[DEBUG] XML TO SEND:\n%s", b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-	resp, err := checkResp(e.c.Http.Do(req))
+	resp, err := e.c.doRequest(req)
 	if err != nil {
-		log.Printf("[DEBUG] Error is: %#v", err)
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -202,11 +199,23 @@ func (e *EdgeGateway) RemoveNATPortMapping(nattype, externalIP, externalPort str
 }
 
 func (e *EdgeGateway) AddNATMapping(nattype, externalIP, internalIP, port string) (Task, error) {
-	return e.AddNATPortMapping(nattype, externalIP, port, internalIP, port)
+	return e.AddNATMappingWithContext(context.Background(), nattype, externalIP, internalIP, port)
+}
+
+func (e *EdgeGateway) AddNATMappingWithContext(ctx context.Context, nattype, externalIP, internalIP, port string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.AddNATMapping")
+	defer func() { endSpan(span, err) }()
+	return e.AddNATPortMappingWithContext(ctx, nattype, externalIP, port, internalIP, port)
 }
 
 func (e *EdgeGateway) AddNATPortMapping(nattype, externalIP, externalPort string, internalIP, internalPort string) (Task, error) {
-	return e.AddNATPortMappingWithUplink(nil, nattype, externalIP, externalPort, internalIP, internalPort)
+	return e.AddNATPortMappingWithContext(context.Background(), nattype, externalIP, externalPort, internalIP, internalPort)
+}
+
+func (e *EdgeGateway) AddNATPortMappingWithContext(ctx context.Context, nattype, externalIP, externalPort string, internalIP, internalPort string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.AddNATPortMapping")
+	defer func() { endSpan(span, err) }()
+	return e.AddNATPortMappingWithUplinkWithContext(ctx, nil, nattype, externalIP, externalPort, internalIP, internalPort)
 }
 
 func (e *EdgeGateway) getFirstUplink() types.Reference {
@@ -221,6 +230,12 @@ func (e *EdgeGateway) getFirstUplink() types.Reference {
 }
 
 func (e *EdgeGateway) AddNATPortMappingWithUplink(network *types.OrgVDCNetwork, nattype, externalIP, externalPort string, internalIP, internalPort string) (Task, error) {
+	return e.AddNATPortMappingWithUplinkWithContext(context.Background(), network, nattype, externalIP, externalPort, internalIP, internalPort)
+}
+
+func (e *EdgeGateway) AddNATPortMappingWithUplinkWithContext(ctx context.Context, network *types.OrgVDCNetwork, nattype, externalIP, externalPort string, internalIP, internalPort string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.AddNATPortMappingWithUplink")
+	defer func() { endSpan(span, err) }()
 	// if a network is provided take it, otherwise find first uplink on the edgegateway
 	var uplinkRef string
 
@@ -286,7 +301,7 @@ func (e *EdgeGateway) AddNATPortMappingWithUplink(network *types.OrgVDCNetwork,
 
 	output, err := xml.MarshalIndent(newRules, "  ", "    ")
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -294,22 +309,19 @@ func (e *EdgeGateway) AddNATPortMappingWithUplink(network *types.OrgVDCNetwork,
 	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
 	s.Path += "/action/configureServices"
 
-	req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
-	log.Printf("[DEBUG] POSTING TO URL: %s", s.Path)
-	log.Printf("[DEBUG] XML TO SEND:\n%s", b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-	resp, err := checkResp(e.c.Http.Do(req))
+	resp, err := e.c.doRequest(req)
 	if err != nil {
-		log.Printf("[DEBUG] Error is: %#v", err)
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -318,9 +330,15 @@ func (e *EdgeGateway) AddNATPortMappingWithUplink(network *types.OrgVDCNetwork,
 }
 
 func (e *EdgeGateway) CreateFirewallRules(defaultAction string, rules []*types.FirewallRule) (Task, error) {
-	err := e.Refresh()
+	return e.CreateFirewallRulesWithContext(context.Background(), defaultAction, rules)
+}
+
+func (e *EdgeGateway) CreateFirewallRulesWithContext(ctx context.Context, defaultAction string, rules []*types.FirewallRule) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.CreateFirewallRules")
+	defer func() { endSpan(span, err) }()
+	err = e.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error: %w\n", err)
 	}
 
 	newRules := &types.EdgeGatewayServiceConfiguration{
@@ -335,37 +353,27 @@ func (e *EdgeGateway) CreateFirewallRules(defaultAction string, rules []*types.F
 
 	output, err := xml.MarshalIndent(newRules, "  ", "    ")
 	if err != nil {
-		return Task{}, fmt.Errorf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error: %w\n", err)
 	}
 
-	var resp *http.Response
-	for {
-		b := bytes.NewBufferString(xml.Header + string(output))
+	b := bytes.NewBufferString(xml.Header + string(output))
 
-		s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
-		s.Path += "/action/configureServices"
+	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
+	s.Path += "/action/configureServices"
 
-		req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
-		log.Printf("[DEBUG] POSTING TO URL: %s", s.Path)
-		log.Printf("[DEBUG] XML TO SEND:\n%s", b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
-		req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
+	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-		resp, err = checkResp(e.c.Http.Do(req))
-		if err != nil {
-			if v, _ := regexp.MatchString("is busy completing an operation.$", err.Error()); v {
-				time.Sleep(3 * time.Second)
-				continue
-			}
-			return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
-		}
-		break
+	resp, err := e.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -373,38 +381,43 @@ func (e *EdgeGateway) CreateFirewallRules(defaultAction string, rules []*types.F
 }
 
 func (e *EdgeGateway) Refresh() error {
+	return e.RefreshWithContext(context.Background())
+}
+
+func (e *EdgeGateway) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.Refresh")
+	defer func() { endSpan(span, err) }()
 
 	if e.EdgeGateway == nil {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
-
-	req := e.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.EdgeGateway{}
 
-	resp, err := checkResp(e.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retreiving Edge Gateway: %s", err)
+	if err = e.c.getEntity(ctx, e.EdgeGateway.HREF, "Edge Gateway", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	e.EdgeGateway = &types.EdgeGateway{}
-
-	if err = decodeBody(resp, e.EdgeGateway); err != nil {
-		return fmt.Errorf("error decoding Edge Gateway response: %s", err)
-	}
+	e.EdgeGateway = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (e *EdgeGateway) Remove1to1Mapping(internal, external string) (Task, error) {
+	return e.Remove1to1MappingWithContext(context.Background(), internal, external)
+}
+
+func (e *EdgeGateway) Remove1to1MappingWithContext(ctx context.Context, internal, external string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.Remove1to1Mapping")
+	defer func() { endSpan(span, err) }()
 
 	// Refresh EdgeGateway rules
-	err := e.Refresh()
+	err = e.RefreshWithContext(ctx)
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error refreshing edge gateway: %w", err)
 	}
 
 	var uplinkif string
@@ -503,13 +516,7 @@ func (e *EdgeGateway) Remove1to1Mapping(internal, external string) (Task, error)
 
 	output, err := xml.MarshalIndent(newedgeconfig, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling edge gateway configuration: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -517,19 +524,19 @@ func (e *EdgeGateway) Remove1to1Mapping(internal, external string) (Task, error)
 	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
 	s.Path += "/action/configureServices"
 
-	req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-	resp, err := checkResp(e.c.Http.Do(req))
+	resp, err := e.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -538,11 +545,17 @@ func (e *EdgeGateway) Remove1to1Mapping(internal, external string) (Task, error)
 }
 
 func (e *EdgeGateway) Create1to1Mapping(internal, external, description string) (Task, error) {
+	return e.Create1to1MappingWithContext(context.Background(), internal, external, description)
+}
+
+func (e *EdgeGateway) Create1to1MappingWithContext(ctx context.Context, internal, external, description string) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.Create1to1Mapping")
+	defer func() { endSpan(span, err) }()
 
 	// Refresh EdgeGateway rules
-	err := e.Refresh()
+	err = e.RefreshWithContext(ctx)
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error refreshing edge gateway: %w", err)
 	}
 
 	var uplinkif string
@@ -622,13 +635,7 @@ func (e *EdgeGateway) Create1to1Mapping(internal, external, description string)
 
 	output, err := xml.MarshalIndent(newedgeconfig, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling edge gateway configuration: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -636,19 +643,19 @@ func (e *EdgeGateway) Create1to1Mapping(internal, external, description string)
 	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
 	s.Path += "/action/configureServices"
 
-	req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-	resp, err := checkResp(e.c.Http.Do(req))
+	resp, err := e.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -657,42 +664,41 @@ func (e *EdgeGateway) Create1to1Mapping(internal, external, description string)
 }
 
 func (e *EdgeGateway) AddIpsecVPN(ipsecVPNConfig *types.EdgeGatewayServiceConfiguration) (Task, error) {
+	return e.AddIpsecVPNWithContext(context.Background(), ipsecVPNConfig)
+}
+
+func (e *EdgeGateway) AddIpsecVPNWithContext(ctx context.Context, ipsecVPNConfig *types.EdgeGatewayServiceConfiguration) (_ Task, err error) {
+	ctx, span := e.c.startSpan(ctx, "EdgeGateway.AddIpsecVPN")
+	defer func() { endSpan(span, err) }()
 
-	err := e.Refresh()
+	err = e.RefreshWithContext(ctx)
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error refreshing edge gateway: %w", err)
 	}
 
 	output, err := xml.MarshalIndent(ipsecVPNConfig, "  ", "    ")
 	if err != nil {
-		fmt.Errorf("error marshaling ipsecVPNConfig compose: %s", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling ipsecVPNConfig compose: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
-	log.Printf("[DEBUG] ipsecVPN configuration: %s", b)
 
 	s, _ := url.ParseRequestURI(e.EdgeGateway.HREF)
 	s.Path += "/action/configureServices"
 
-	req := e.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := e.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml")
 
-	resp, err := checkResp(e.c.Http.Do(req))
+	resp, err := e.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %s", err)
+		return Task{}, fmt.Errorf("error reconfiguring Edge Gateway: %w", err)
 	}
 
 	task := NewTask(e.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
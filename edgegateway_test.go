@@ -14,8 +14,8 @@ func (s *S) Test_Refresh(c *C) {
 
 	// Get the Org populated
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{200, nil, edgegatewayqueryresultsExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, edgegatewayExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: edgegatewayqueryresultsExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
 	})
 
 	edge, err := s.vdc.FindEdgeGateway("M916272752-5793")
@@ -35,10 +35,31 @@ func (s *S) Test_Refresh(c *C) {
 
 }
 
+func (s *S) Test_FindEdgeGatewayByName(c *C) {
+
+	testServer.ResponseMap(2, testutil.ResponseMap{
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: queryEdgeGatewaysExample},
+		"/api/admin/edgeGateway/22222222-2222-2222-2222-222222222222": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+	})
+
+	_, err := s.vdc.FindEdgeGateway("edge-2")
+	_ = testServer.WaitRequests(2)
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+
+	testServer.Response(200, nil, queryEdgeGatewaysExample)
+	_, err = s.vdc.FindEdgeGateway("edge-3")
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, ErrorMatches, "can't find Edge Gateway edge-3")
+}
+
 func (s *S) Test_NATMapping(c *C) {
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{200, nil, edgegatewayqueryresultsExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, edgegatewayExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: edgegatewayqueryresultsExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
 	})
 
 	edge, err := s.vdc.FindEdgeGateway("M916272752-5793")
@@ -49,8 +70,8 @@ func (s *S) Test_NATMapping(c *C) {
 	c.Assert(edge.EdgeGateway.Name, Equals, "M916272752-5793")
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.AddNATMapping("DNAT", "10.0.0.1", "20.0.0.2", "77")
@@ -59,8 +80,8 @@ func (s *S) Test_NATMapping(c *C) {
 	c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.RemoveNATMapping("DNAT", "10.0.0.1", "20.0.0.2", "77")
@@ -72,8 +93,8 @@ func (s *S) Test_NATMapping(c *C) {
 
 func (s *S) Test_NATPortMapping(c *C) {
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{200, nil, edgegatewayqueryresultsExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, edgegatewayExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: edgegatewayqueryresultsExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
 	})
 
 	edge, err := s.vdc.FindEdgeGateway("M916272752-5793")
@@ -84,8 +105,8 @@ func (s *S) Test_NATPortMapping(c *C) {
 	c.Assert(edge.EdgeGateway.Name, Equals, "M916272752-5793")
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.AddNATPortMapping("DNAT", "10.0.0.1", "1177", "20.0.0.2", "77")
@@ -94,8 +115,8 @@ func (s *S) Test_NATPortMapping(c *C) {
 	c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.RemoveNATPortMapping("DNAT", "10.0.0.1", "1177", "20.0.0.2", "77")
@@ -108,8 +129,8 @@ func (s *S) Test_NATPortMapping(c *C) {
 func (s *S) Test_1to1Mappings(c *C) {
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{200, nil, edgegatewayqueryresultsExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, edgegatewayExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: edgegatewayqueryresultsExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
 	})
 
 	edge, err := s.vdc.FindEdgeGateway("M916272752-5793")
@@ -120,8 +141,8 @@ func (s *S) Test_1to1Mappings(c *C) {
 	c.Assert(edge.EdgeGateway.Name, Equals, "M916272752-5793")
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.Create1to1Mapping("10.0.0.1", "20.0.0.2", "description")
@@ -130,8 +151,8 @@ func (s *S) Test_1to1Mappings(c *C) {
 	c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	_, err = edge.Remove1to1Mapping("10.0.0.1", "20.0.0.2")
@@ -143,8 +164,8 @@ func (s *S) Test_1to1Mappings(c *C) {
 
 func (s *S) Test_AddIpsecVPN(c *C) {
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{200, nil, edgegatewayqueryresultsExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, edgegatewayExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/edgeGateways":  testutil.Response{Status: 200, Headers: nil, Body: edgegatewayqueryresultsExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
 	})
 
 	edge, err := s.vdc.FindEdgeGateway("M916272752-5793")
@@ -155,8 +176,8 @@ func (s *S) Test_AddIpsecVPN(c *C) {
 	c.Assert(edge.EdgeGateway.Name, Equals, "M916272752-5793")
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	tunnel := &types.GatewayIpsecVpnTunnel{
@@ -189,8 +210,8 @@ func (s *S) Test_AddIpsecVPN(c *C) {
 	c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, edgegatewayExample},
-		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{200, nil, taskExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: edgegatewayExample},
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000/action/configureServices": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 }
 
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// entityKind tells how to fetch the entities of a MIME type: their name in
+// errors, and how to make a new wrapper around c along with the struct to
+// decode the entity into.
+type entityKind struct {
+	name string
+	new  func(c *Client) (entity, body interface{})
+}
+
+var entityKinds = map[string]entityKind{
+	types.MimeOrg: {"org", func(c *Client) (interface{}, interface{}) {
+		org := NewOrg(c)
+		return org, org.Org
+	}},
+	types.MimeVDC: {"vdc", func(c *Client) (interface{}, interface{}) {
+		vdc := NewVdc(c)
+		return vdc, vdc.Vdc
+	}},
+	types.MimeVApp: {"vApp", func(c *Client) (interface{}, interface{}) {
+		vapp := NewVApp(c)
+		return vapp, vapp.VApp
+	}},
+	types.MimeVM: {"VM", func(c *Client) (interface{}, interface{}) {
+		vm := NewVM(c)
+		return vm, vm.VM
+	}},
+	types.MimeVAppTemplate: {"vApp template", func(c *Client) (interface{}, interface{}) {
+		template := NewVAppTemplate(c)
+		return template, template.VAppTemplate
+	}},
+	types.MimeCatalog: {"catalog", func(c *Client) (interface{}, interface{}) {
+		catalog := NewCatalog(c)
+		return catalog, catalog.Catalog
+	}},
+	types.MimeCatalogItem: {"catalog item", func(c *Client) (interface{}, interface{}) {
+		item := NewCatalogItem(c)
+		return item, item.CatalogItem
+	}},
+	types.MimeTask: {"task", func(c *Client) (interface{}, interface{}) {
+		task := NewTask(c)
+		return task, task.Task
+	}},
+	types.MimeNetwork: {"network", func(c *Client) (interface{}, interface{}) {
+		network := NewOrgVDCNetwork(c)
+		return network, network.OrgVDCNetwork
+	}},
+	types.MimeOrgVdcNetwork: {"network", func(c *Client) (interface{}, interface{}) {
+		network := NewOrgVDCNetwork(c)
+		return network, network.OrgVDCNetwork
+	}},
+	types.MimeEdgeGateway: {"Edge Gateway", func(c *Client) (interface{}, interface{}) {
+		edge := NewEdgeGateway(c)
+		return edge, edge.EdgeGateway
+	}},
+}
+
+// ResolveLink fetches the entity link points to, see Resolve.
+func (c *Client) ResolveLink(link *types.Link) (interface{}, error) {
+	return c.ResolveWithContext(context.Background(), link.HREF, link.Type)
+}
+
+func (c *Client) ResolveLinkWithContext(ctx context.Context, link *types.Link) (interface{}, error) {
+	return c.ResolveWithContext(ctx, link.HREF, link.Type)
+}
+
+// ResolveReference fetches the entity ref points to, see Resolve.
+func (c *Client) ResolveReference(ref *types.Reference) (interface{}, error) {
+	return c.ResolveWithContext(context.Background(), ref.HREF, ref.Type)
+}
+
+func (c *Client) ResolveReferenceWithContext(ctx context.Context, ref *types.Reference) (interface{}, error) {
+	return c.ResolveWithContext(ctx, ref.HREF, ref.Type)
+}
+
+// Resolve fetches the entity at href, of the given MIME type, and returns it
+// in its wrapper: an *Org, *Vdc, *VApp, *VM, *VAppTemplate, *Catalog,
+// *CatalogItem, *Task, *OrgVDCNetwork or *EdgeGateway, ready for operations.
+// This makes it possible to walk the links of the API generically:
+//
+//	entity, err := client.ResolveLink(catalog.Catalog.Link.ForType(types.MimeOrg, "up"))
+//	if org, ok := entity.(*Org); ok {
+//		...
+//	}
+func (c *Client) Resolve(href, mimeType string) (interface{}, error) {
+	return c.ResolveWithContext(context.Background(), href, mimeType)
+}
+
+func (c *Client) ResolveWithContext(ctx context.Context, href, mimeType string) (_ interface{}, err error) {
+	ctx, span := c.startSpan(ctx, "Client.Resolve")
+	defer func() { endSpan(span, err) }()
+
+	// Drop the parameters, such as the API version, from the MIME type
+	mimeType = strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0])
+
+	kind, ok := entityKinds[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("can't resolve %s: unsupported entity type %q", href, mimeType)
+	}
+
+	entity, body := kind.new(c)
+	if err = c.getEntity(ctx, href, kind.name, body); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// getEntity fetches the entity at href and decodes it into v. what names the
+// entity in the errors.
+func (c *Client) getEntity(ctx context.Context, href, what string, v interface{}) error {
+	u, err := url.ParseRequestURI(href)
+	if err != nil {
+		return fmt.Errorf("error decoding %s HREF: %w", what, err)
+	}
+
+	req := c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("error retrieving %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if err = decodeBody(resp, v); err != nil {
+		return fmt.Errorf("error decoding %s response: %w", what, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"github.com/ukcloud/govcloudair/testutil"
+	types "github.com/ukcloud/govcloudair/types/v56"
+	. "gopkg.in/check.v1"
+)
+
+// Test_Resolve walks the link graph from the org down to a task and back up
+// from a catalog item without knowing the entity types up front.
+func (s *S) Test_Resolve(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("web", "web01")
+	fake.AddCatalogItem("catalog", "template", "vm1")
+
+	client, org, _ := fakeVCDLogin(c, fake)
+
+	var vdc *Vdc
+	for _, link := range org.Org.Link {
+		entity, err := client.Client.Resolve(link.HREF, link.Type)
+		if link.Type == types.MimeTasksList {
+			c.Assert(err, ErrorMatches, ".*unsupported entity type.*")
+			continue
+		}
+		c.Assert(err, IsNil)
+		if v, ok := entity.(*Vdc); ok {
+			vdc = v
+		}
+	}
+	c.Assert(vdc, NotNil)
+	c.Assert(vdc.Vdc.Name, Equals, fake.Vdc)
+
+	ref := vdc.Vdc.ResourceEntities[0].ResourceEntity[0]
+	entity, err := client.Client.Resolve(ref.HREF, ref.Type)
+	c.Assert(err, IsNil)
+	vapp, ok := entity.(*VApp)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(vapp.VApp.Name, Equals, "web")
+
+	child := vapp.VApp.Children.VM[0]
+	entity, err = client.Client.Resolve(child.HREF, child.Type)
+	c.Assert(err, IsNil)
+	vm, ok := entity.(*VM)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(vm.VM.Name, Equals, "web01")
+
+	task, err := vm.PowerOn()
+	c.Assert(err, IsNil)
+	// Parameters of the MIME type are ignored
+	entity, err = client.Client.Resolve(task.Task.HREF, types.MimeTask+";version=5.6")
+	c.Assert(err, IsNil)
+	resolved, ok := entity.(*Task)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(resolved.Task.HREF, Equals, task.Task.HREF)
+	c.Assert(resolved.WaitTaskCompletion(), IsNil)
+
+	// References and links of catalogs
+	catalog, err := org.FindCatalog("catalog")
+	c.Assert(err, IsNil)
+	entity, err = client.Client.ResolveReference(catalog.Catalog.CatalogItems[0].CatalogItem[0])
+	c.Assert(err, IsNil)
+	item, ok := entity.(*CatalogItem)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(item.CatalogItem.Name, Equals, "template")
+
+	entity, err = client.Client.ResolveLink(item.CatalogItem.Link.ForType(types.MimeCatalog, "up"))
+	c.Assert(err, IsNil)
+	parent, ok := entity.(*Catalog)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(parent.Catalog.Name, Equals, "catalog")
+
+	entity, err = client.Client.ResolveLink(parent.Catalog.Link.ForType(types.MimeOrg, "up"))
+	c.Assert(err, IsNil)
+	resolvedOrg, ok := entity.(*Org)
+	c.Assert(ok, Equals, true, Commentf("got %T", entity))
+	c.Assert(resolvedOrg.Org.HREF, Equals, org.Org.HREF)
+
+}
@@ -0,0 +1,19 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+// fakeVCDLogin logs a new client in to fake, a stateful fake vCloud
+// Director set up by the caller, who closes it at the end of the test.
+func fakeVCDLogin(c *C, fake *testutil.FakeVCD) (*VCDClient, Org, Vdc) {
+	client := NewVCDClient(fake.Endpoint(), false)
+	org, vdc, err := client.Authenticate(fake.User, fake.Password, fake.Org, fake.Vdc)
+	c.Assert(err, IsNil)
+	return client, org, vdc
+}
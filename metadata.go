@@ -0,0 +1,518 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// MetadataValue is a typed metadata value, together with the domain and the
+// visibility of its entry. Build one with StringMetadata, NumberMetadata,
+// BooleanMetadata or DateTimeMetadata.
+type MetadataValue struct {
+	Type       string // One of the types.Metadata*Value constants
+	Value      string // The value, as formatted by the API
+	Domain     string // types.MetadataDomainGeneral if empty
+	Visibility string // types.MetadataReadWrite if empty
+}
+
+// StringMetadata returns a string metadata value.
+func StringMetadata(s string) MetadataValue {
+	return MetadataValue{Type: types.MetadataStringValue, Value: s}
+}
+
+// NumberMetadata returns a number metadata value.
+func NumberMetadata(n int64) MetadataValue {
+	return MetadataValue{Type: types.MetadataNumberValue, Value: strconv.FormatInt(n, 10)}
+}
+
+// BooleanMetadata returns a boolean metadata value.
+func BooleanMetadata(b bool) MetadataValue {
+	return MetadataValue{Type: types.MetadataBooleanValue, Value: strconv.FormatBool(b)}
+}
+
+// DateTimeMetadata returns a date and time metadata value.
+func DateTimeMetadata(t time.Time) MetadataValue {
+	return MetadataValue{Type: types.MetadataDateTimeValue, Value: t.UTC().Format("2006-01-02T15:04:05.000Z07:00")}
+}
+
+// InDomain returns the value placed in the given domain, with the given
+// visibility. Only system administrators can write to the SYSTEM domain.
+func (m MetadataValue) InDomain(domain, visibility string) MetadataValue {
+	m.Domain, m.Visibility = domain, visibility
+	return m
+}
+
+// String returns the value as formatted by the API.
+func (m MetadataValue) String() string {
+	return m.Value
+}
+
+// Number returns the value of a number metadata value.
+func (m MetadataValue) Number() (int64, error) {
+	if m.Type != types.MetadataNumberValue {
+		return 0, fmt.Errorf("metadata value is a %s, not a number", m.Type)
+	}
+	return strconv.ParseInt(m.Value, 10, 64)
+}
+
+// Bool returns the value of a boolean metadata value.
+func (m MetadataValue) Bool() (bool, error) {
+	if m.Type != types.MetadataBooleanValue {
+		return false, fmt.Errorf("metadata value is a %s, not a boolean", m.Type)
+	}
+	return strconv.ParseBool(m.Value)
+}
+
+// Time returns the value of a date and time metadata value.
+func (m MetadataValue) Time() (time.Time, error) {
+	if m.Type != types.MetadataDateTimeValue {
+		return time.Time{}, fmt.Errorf("metadata value is a %s, not a date and time", m.Type)
+	}
+	return time.Parse(time.RFC3339Nano, m.Value)
+}
+
+// system reports whether the value belongs to the SYSTEM domain.
+func (m MetadataValue) system() bool {
+	return m.Domain == types.MetadataDomainSystem
+}
+
+func (m MetadataValue) domainTag() *types.MetadataDomainTag {
+	if m.Domain == "" && m.Visibility == "" {
+		return nil
+	}
+	tag := &types.MetadataDomainTag{Domain: m.Domain, Visibility: m.Visibility}
+	if tag.Domain == "" {
+		tag.Domain = types.MetadataDomainGeneral
+	}
+	if tag.Visibility == "" {
+		tag.Visibility = types.MetadataReadWrite
+	}
+	return tag
+}
+
+func (m MetadataValue) typedValue() *types.TypedValue {
+	return &types.TypedValue{XsiType: m.Type, Value: m.Value}
+}
+
+func newMetadataValue(domain *types.MetadataDomainTag, value *types.TypedValue) MetadataValue {
+	m := MetadataValue{Domain: types.MetadataDomainGeneral, Visibility: types.MetadataReadWrite}
+	if value != nil {
+		m.Type, m.Value = value.XsiType, value.Value
+	}
+	if domain != nil {
+		m.Domain, m.Visibility = domain.Domain, domain.Visibility
+	}
+	return m
+}
+
+// MetadataMap returns the entries of metadata by key, e.g. the metadata of a
+// query record. An entry of the GENERAL domain wins over an entry of the
+// SYSTEM domain with the same key.
+func MetadataMap(metadata *types.Metadata) map[string]MetadataValue {
+	values := make(map[string]MetadataValue)
+	if metadata == nil {
+		return values
+	}
+	for _, entry := range metadata.MetadataEntry {
+		value := newMetadataValue(entry.Domain, entry.TypedValue)
+		if previous, ok := values[entry.Key]; ok && !previous.system() {
+			continue
+		}
+		values[entry.Key] = value
+	}
+	return values
+}
+
+// MetadataService reads and writes the metadata of an entity. Get one with
+// the Metadata method of VApp, VM, Vdc, Catalog, CatalogItem, VAppTemplate or
+// OrgVDCNetwork, or with NewMetadataService for other entities such as
+// independent disks and media.
+type MetadataService struct {
+	HREF string // HREF of the entity
+	c    *Client
+}
+
+// NewMetadataService returns the metadata service of the entity at href.
+func NewMetadataService(c *Client, href string) *MetadataService {
+	return &MetadataService{HREF: href, c: c}
+}
+
+// Metadata returns the metadata service of the vApp.
+func (v *VApp) Metadata() *MetadataService {
+	return NewMetadataService(v.c, v.VApp.HREF)
+}
+
+// Metadata returns the metadata service of the VM.
+func (v *VM) Metadata() *MetadataService {
+	return NewMetadataService(v.c, v.VM.HREF)
+}
+
+// Metadata returns the metadata service of the vDC.
+func (v *Vdc) Metadata() *MetadataService {
+	return NewMetadataService(v.c, v.Vdc.HREF)
+}
+
+// Metadata returns the metadata service of the catalog.
+func (c *Catalog) Metadata() *MetadataService {
+	return NewMetadataService(c.c, c.Catalog.HREF)
+}
+
+// Metadata returns the metadata service of the catalog item.
+func (c *CatalogItem) Metadata() *MetadataService {
+	return NewMetadataService(c.c, c.CatalogItem.HREF)
+}
+
+// Metadata returns the metadata service of the vApp template.
+func (v *VAppTemplate) Metadata() *MetadataService {
+	return NewMetadataService(v.c, v.VAppTemplate.HREF)
+}
+
+// Metadata returns the metadata service of the network.
+func (o *OrgVDCNetwork) Metadata() *MetadataService {
+	return NewMetadataService(o.c, o.OrgVDCNetwork.HREF)
+}
+
+// url returns the URL of the metadata, or of a single key of it.
+func (m *MetadataService) url(domain, key string) (url.URL, error) {
+	u, err := url.ParseRequestURI(m.HREF)
+	if err != nil {
+		return url.URL{}, fmt.Errorf("error decoding entity HREF: %w", err)
+	}
+	u.Path += "/metadata"
+	if domain == types.MetadataDomainSystem {
+		u.Path += "/" + types.MetadataDomainSystem
+	}
+	if key != "" {
+		u.Path += "/" + key
+	}
+	return *u, nil
+}
+
+// GetAll returns every metadata entry of the entity, by key. An entry of the
+// GENERAL domain wins over an entry of the SYSTEM domain with the same key.
+func (m *MetadataService) GetAll() (map[string]MetadataValue, error) {
+	return m.GetAllWithContext(context.Background())
+}
+
+func (m *MetadataService) GetAllWithContext(ctx context.Context) (_ map[string]MetadataValue, err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	metadata, err := m.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return MetadataMap(metadata), nil
+}
+
+func (m *MetadataService) entries(ctx context.Context) (*types.Metadata, error) {
+
+	u, err := m.url("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	req := m.c.NewRequestWithContext(ctx, map[string]string{}, "GET", u, nil)
+
+	resp, err := m.c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving metadata: %w", err)
+	}
+
+	metadata := new(types.Metadata)
+
+	if err = decodeBody(resp, metadata); err != nil {
+		return nil, fmt.Errorf("error decoding metadata response: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// Get returns the value of key, in the GENERAL domain.
+func (m *MetadataService) Get(key string) (MetadataValue, error) {
+	return m.GetWithContext(context.Background(), key)
+}
+
+func (m *MetadataService) GetWithContext(ctx context.Context, key string) (_ MetadataValue, err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.Get")
+	defer func() { endSpan(span, err) }()
+
+	u, err := m.url("", key)
+	if err != nil {
+		return MetadataValue{}, err
+	}
+
+	req := m.c.NewRequestWithContext(ctx, map[string]string{}, "GET", u, nil)
+
+	resp, err := m.c.doRequest(req)
+	if err != nil {
+		return MetadataValue{}, fmt.Errorf("error retrieving metadata %s: %w", key, err)
+	}
+
+	value := new(types.MetadataValue)
+
+	if err = decodeBody(resp, value); err != nil {
+		return MetadataValue{}, fmt.Errorf("error decoding metadata response: %w", err)
+	}
+
+	return newMetadataValue(value.Domain, value.TypedValue), nil
+}
+
+// Set sets key to value, in the domain of value.
+func (m *MetadataService) Set(key string, value MetadataValue) (Task, error) {
+	return m.SetWithContext(context.Background(), key, value)
+}
+
+func (m *MetadataService) SetWithContext(ctx context.Context, key string, value MetadataValue) (_ Task, err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.Set")
+	defer func() { endSpan(span, err) }()
+
+	u, err := m.url(value.Domain, key)
+	if err != nil {
+		return Task{}, err
+	}
+
+	newmetadata := &types.MetadataValue{
+		Xmlns:      types.NsVCloud,
+		Xsi:        types.NsXMLSchema,
+		Domain:     value.domainTag(),
+		TypedValue: value.typedValue(),
+	}
+
+	output, err := xml.MarshalIndent(newmetadata, "  ", "    ")
+	if err != nil {
+		return Task{}, fmt.Errorf("error encoding metadata: %w", err)
+	}
+
+	b := bytes.NewBufferString(xml.Header + string(output))
+
+	req := m.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", u, b)
+
+	req.Header.Add("Content-Type", types.MimeMetadataValue)
+
+	resp, err := m.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error setting metadata %s: %w", key, err)
+	}
+
+	task := NewTask(m.c)
+
+	if err = decodeBody(resp, task.Task); err != nil {
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
+	}
+
+	// The request was successful
+	return *task, nil
+}
+
+// Delete deletes key, in the GENERAL domain.
+func (m *MetadataService) Delete(key string) (Task, error) {
+	return m.DeleteWithContext(context.Background(), key)
+}
+
+func (m *MetadataService) DeleteWithContext(ctx context.Context, key string) (_ Task, err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.Delete")
+	defer func() { endSpan(span, err) }()
+
+	return m.delete(ctx, types.MetadataDomainGeneral, key)
+}
+
+func (m *MetadataService) delete(ctx context.Context, domain, key string) (Task, error) {
+
+	u, err := m.url(domain, key)
+	if err != nil {
+		return Task{}, err
+	}
+
+	req := m.c.NewRequestWithContext(ctx, map[string]string{}, "DELETE", u, nil)
+
+	resp, err := m.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error deleting metadata %s: %w", key, err)
+	}
+
+	task := NewTask(m.c)
+
+	if err = decodeBody(resp, task.Task); err != nil {
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
+	}
+
+	return *task, nil
+}
+
+// Merge sets every key of values in a single request, leaving the other keys
+// of the entity untouched.
+func (m *MetadataService) Merge(values map[string]MetadataValue) (Task, error) {
+	return m.MergeWithContext(context.Background(), values)
+}
+
+func (m *MetadataService) MergeWithContext(ctx context.Context, values map[string]MetadataValue) (_ Task, err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.Merge")
+	defer func() { endSpan(span, err) }()
+
+	u, err := m.url("", "")
+	if err != nil {
+		return Task{}, err
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	newmetadata := &types.Metadata{
+		Xmlns: types.NsVCloud,
+		Xsi:   types.NsXMLSchema,
+	}
+	for _, key := range keys {
+		newmetadata.MetadataEntry = append(newmetadata.MetadataEntry, &types.MetadataEntry{
+			Domain:     values[key].domainTag(),
+			Key:        key,
+			TypedValue: values[key].typedValue(),
+		})
+	}
+
+	output, err := xml.MarshalIndent(newmetadata, "  ", "    ")
+	if err != nil {
+		return Task{}, fmt.Errorf("error encoding metadata: %w", err)
+	}
+
+	b := bytes.NewBufferString(xml.Header + string(output))
+
+	req := m.c.NewRequestWithContext(ctx, map[string]string{}, "POST", u, b)
+
+	req.Header.Add("Content-Type", types.MimeMetadata)
+
+	resp, err := m.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error merging metadata: %w", err)
+	}
+
+	task := NewTask(m.c)
+
+	if err = decodeBody(resp, task.Task); err != nil {
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
+	}
+
+	// The request was successful
+	return *task, nil
+}
+
+// Replace makes values the whole metadata of the entity: the keys missing
+// from values are deleted and the other ones are merged. The API has no
+// single request for it, so Replace waits for the tasks of the deletions and
+// of the merge to complete.
+func (m *MetadataService) Replace(values map[string]MetadataValue) error {
+	return m.ReplaceWithContext(context.Background(), values)
+}
+
+func (m *MetadataService) ReplaceWithContext(ctx context.Context, values map[string]MetadataValue) (err error) {
+	ctx, span := m.c.startSpan(ctx, "Metadata.Replace")
+	defer func() { endSpan(span, err) }()
+
+	metadata, err := m.entries(ctx)
+	if err != nil {
+		return err
+	}
+
+	deletions := &TaskGroup{FailFast: true}
+	for _, entry := range metadata.MetadataEntry {
+		current := newMetadataValue(entry.Domain, entry.TypedValue)
+		if value, ok := values[entry.Key]; ok && value.system() == current.system() {
+			continue
+		}
+		task, err := m.delete(ctx, current.Domain, entry.Key)
+		if err != nil {
+			return err
+		}
+		deletions.Add(task)
+	}
+	if _, err = deletions.WaitAll(ctx); err != nil {
+		return fmt.Errorf("error deleting metadata: %w", err)
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	task, err := m.MergeWithContext(ctx, values)
+	if err != nil {
+		return err
+	}
+
+	return task.WaitTaskCompletionWithContext(ctx)
+}
+
+// metadataFilterTypes are the types of metadata values in query filters.
+var metadataFilterTypes = map[string]string{
+	types.MetadataStringValue:   "STRING",
+	types.MetadataNumberValue:   "NUMBER",
+	types.MetadataBooleanValue:  "BOOLEAN",
+	types.MetadataDateTimeValue: "DATETIME",
+}
+
+// MetadataField returns the query field of a metadata key, to ask for its
+// values with Query.Fields or to sort on it. The values are in the Metadata
+// of the records, see MetadataMap.
+func MetadataField(key string) string {
+	return "metadata:" + key
+}
+
+// MetadataEq matches the entities whose metadata key is equal to value. The
+// key is looked up in the domain of value.
+func MetadataEq(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "==", value)
+}
+
+// MetadataNe matches the entities whose metadata key is not equal to value.
+func MetadataNe(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "!=", value)
+}
+
+// MetadataGt matches the entities whose metadata key is greater than value.
+func MetadataGt(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "=gt=", value)
+}
+
+// MetadataGe matches the entities whose metadata key is greater than or
+// equal to value.
+func MetadataGe(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "=ge=", value)
+}
+
+// MetadataLt matches the entities whose metadata key is lower than value.
+func MetadataLt(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "=lt=", value)
+}
+
+// MetadataLe matches the entities whose metadata key is lower than or equal
+// to value.
+func MetadataLe(key string, value MetadataValue) FilterExpr {
+	return metadataCondition(key, "=le=", value)
+}
+
+func metadataCondition(key, operator string, value MetadataValue) FilterExpr {
+	field := MetadataField(key)
+	if value.system() {
+		field = "metadata@" + types.MetadataDomainSystem + ":" + key
+	}
+	valueType, ok := metadataFilterTypes[value.Type]
+	if !ok {
+		valueType = "STRING"
+	}
+	return condition(field, operator, valueType+":"+value.Value)
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// FindVAppsByMetadata returns the vApps whose metadata key is equal to value,
+// looked up through the query service. A * in a string value is a wildcard.
+// The vApps are fetched in full, ready for operations.
+func (c *VCDClient) FindVAppsByMetadata(key string, value MetadataValue) ([]VApp, error) {
+	return c.FindVAppsByMetadataWithContext(context.Background(), key, value)
+}
+
+func (c *VCDClient) FindVAppsByMetadataWithContext(ctx context.Context, key string, value MetadataValue) (_ []VApp, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.FindVAppsByMetadata")
+	defer func() { endSpan(span, err) }()
+
+	refs, err := c.queryReferences(ctx, NewQuery(types.QtVapp).Filter(MetadataEq(key, value)))
+	if err != nil {
+		return nil, err
+	}
+
+	vapps := make([]VApp, 0, len(refs))
+	for _, ref := range refs {
+		vapp := NewVApp(&c.Client)
+		vapp.VApp.HREF = ref.HREF
+		if err = vapp.RefreshWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("error retrieving vApp %s: %w", ref.Name, err)
+		}
+		vapps = append(vapps, *vapp)
+	}
+
+	return vapps, nil
+}
+
+// FindVMsByMetadata returns the VMs whose metadata key is equal to value,
+// looked up through the query service. The VMs of vApp templates are left
+// out. A * in a string value is a wildcard. The VMs are fetched in full, ready
+// for operations.
+func (c *VCDClient) FindVMsByMetadata(key string, value MetadataValue) ([]VM, error) {
+	return c.FindVMsByMetadataWithContext(context.Background(), key, value)
+}
+
+func (c *VCDClient) FindVMsByMetadataWithContext(ctx context.Context, key string, value MetadataValue) (_ []VM, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.FindVMsByMetadata")
+	defer func() { endSpan(span, err) }()
+
+	refs, err := c.queryReferences(ctx, NewQuery(types.QtVm).
+		Filter(MetadataEq(key, value)).
+		Filter(Eq("isVAppTemplate", "false")))
+	if err != nil {
+		return nil, err
+	}
+
+	vms := make([]VM, 0, len(refs))
+	for _, ref := range refs {
+		vm := NewVM(&c.Client)
+		vm.VM.HREF = ref.HREF
+		if err = vm.RefreshWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("error retrieving VM %s: %w", ref.Name, err)
+		}
+		vms = append(vms, *vm)
+	}
+
+	return vms, nil
+}
+
+// FindVAppTemplatesByMetadata returns the vApp templates whose metadata key
+// is equal to value, looked up through the query service. A * in a string
+// value is a wildcard.
+func (c *VCDClient) FindVAppTemplatesByMetadata(key string, value MetadataValue) ([]VAppTemplate, error) {
+	return c.FindVAppTemplatesByMetadataWithContext(context.Background(), key, value)
+}
+
+func (c *VCDClient) FindVAppTemplatesByMetadataWithContext(ctx context.Context, key string, value MetadataValue) (_ []VAppTemplate, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.FindVAppTemplatesByMetadata")
+	defer func() { endSpan(span, err) }()
+
+	refs, err := c.queryReferences(ctx, NewQuery(types.QtVappTemplate).Filter(MetadataEq(key, value)))
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]VAppTemplate, 0, len(refs))
+	for _, ref := range refs {
+		template := NewVAppTemplate(&c.Client)
+
+		if err = c.Client.getEntity(ctx, ref.HREF, "vApp template", template.VAppTemplate); err != nil {
+			return nil, fmt.Errorf("error retrieving vApp template %s: %w", ref.Name, err)
+		}
+
+		templates = append(templates, *template)
+	}
+
+	return templates, nil
+}
+
+// queryReferences returns the references to every entity matching q.
+func (c *VCDClient) queryReferences(ctx context.Context, q *Query) ([]*types.Reference, error) {
+	it := c.QueryIteratorWithContext(ctx, q.References().PageSize(128))
+	defer it.Close()
+
+	var refs []*types.Reference
+	for it.Next() {
+		refs = append(refs, it.Reference())
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("error querying %s: %w", q.Type(), err)
+	}
+
+	return refs, nil
+}
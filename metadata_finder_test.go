@@ -0,0 +1,64 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_FindByMetadata(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddCatalogItem("catalog", "centos", "centos-vm")
+	fake.AddCatalogItem("catalog", "ubuntu", "ubuntu-vm")
+	fake.AddVApp("web", "web01", "web02")
+	fake.AddVApp("db", "db01")
+
+	client, org, vdc := fakeVCDLogin(c, fake)
+	web, err := vdc.FindVAppByName("web")
+	c.Assert(err, IsNil)
+	db, err := vdc.FindVAppByName("db")
+	c.Assert(err, IsNil)
+	catalog, err := org.FindCatalog("catalog")
+	c.Assert(err, IsNil)
+	item, err := catalog.FindCatalogItem("ubuntu")
+	c.Assert(err, IsNil)
+	template, err := item.GetVAppTemplate()
+	c.Assert(err, IsNil)
+
+	fake.SetMetadata(web.VApp.HREF, "app", "shop")
+	fake.SetMetadata(db.VApp.HREF, "app", "billing")
+	fake.SetMetadata(web.VApp.Children.VM[1].HREF, "role", "frontend")
+	fake.SetMetadata(db.VApp.Children.VM[0].HREF, "role", "database")
+	fake.SetMetadata(template.VAppTemplate.HREF, "os", "linux")
+	fake.SetMetadata(template.VAppTemplate.Children.VM[0].HREF, "role", "frontend")
+
+	vapps, err := client.FindVAppsByMetadata("app", StringMetadata("shop"))
+	c.Assert(err, IsNil)
+	c.Assert(vapps, HasLen, 1)
+	c.Assert(vapps[0].VApp.Name, Equals, "web")
+	c.Assert(vapps[0].VApp.Children, NotNil)
+
+	// The VMs of templates are left out
+	vms, err := client.FindVMsByMetadata("role", StringMetadata("front*"))
+	c.Assert(err, IsNil)
+	c.Assert(vms, HasLen, 1)
+	c.Assert(vms[0].VM.Name, Equals, "web02")
+	task, err := vms[0].PowerOn()
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+
+	templates, err := client.FindVAppTemplatesByMetadata("os", StringMetadata("linux"))
+	c.Assert(err, IsNil)
+	c.Assert(templates, HasLen, 1)
+	c.Assert(templates[0].VAppTemplate.Name, Equals, "ubuntu")
+
+	vapps, err = client.FindVAppsByMetadata("app", StringMetadata("none"))
+	c.Assert(err, IsNil)
+	c.Assert(vapps, HasLen, 0)
+
+}
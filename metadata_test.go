@@ -0,0 +1,166 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"time"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	types "github.com/ukcloud/govcloudair/types/v56"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_MetadataGetAll(c *C) {
+
+	metadata := NewMetadataService(&s.client.Client, "http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000")
+
+	testServer.Response(200, nil, metadataExample)
+	values, err := metadata.GetAll()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 3)
+	c.Assert(values["owner"], Equals, MetadataValue{types.MetadataStringValue, "team-a", types.MetadataDomainGeneral, types.MetadataReadWrite})
+	c.Assert(values["tier"], Equals, MetadataValue{types.MetadataStringValue, "gold", types.MetadataDomainSystem, types.MetadataReadOnly})
+	cost, err := values["cost"].Number()
+	c.Assert(err, IsNil)
+	c.Assert(cost, Equals, int64(42))
+
+	testServer.Response(200, nil, metadataValueExample)
+	value, err := metadata.Get("cost")
+	req := testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(req.URL.Path, Equals, "/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata/cost")
+	c.Assert(value.Type, Equals, types.MetadataNumberValue)
+	c.Assert(value.Value, Equals, "42")
+}
+
+func (s *S) Test_MetadataFilters(c *C) {
+
+	c.Assert(MetadataEq("env", StringMetadata("prod,eu")).String(), Equals, "metadata:env==STRING:prod%2Ceu")
+	c.Assert(MetadataGe("cost", NumberMetadata(-3)).String(), Equals, "metadata:cost=ge=NUMBER:-3")
+	c.Assert(MetadataNe("billed", BooleanMetadata(false)).String(), Equals, "metadata:billed!=BOOLEAN:false")
+	c.Assert(MetadataEq("tier", StringMetadata("gold").InDomain(types.MetadataDomainSystem, types.MetadataReadOnly)).String(), Equals, "metadata@SYSTEM:tier==STRING:gold")
+	c.Assert(MetadataField("env"), Equals, "metadata:env")
+}
+
+func (s *S) Test_Metadata(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("web", "web01", "web02")
+	fake.AddVApp("db", "db01")
+
+	client, _, vdc := fakeVCDLogin(c, fake)
+	vapp, err := vdc.FindVAppByName("web")
+	c.Assert(err, IsNil)
+	wait := func(task Task, err error) {
+		c.Assert(err, IsNil)
+		c.Assert(task.WaitTaskCompletion(), IsNil)
+	}
+
+	created := time.Date(2016, 3, 1, 10, 30, 0, 0, time.UTC)
+	metadata := vapp.Metadata()
+	wait(metadata.Set("owner", StringMetadata("team-a")))
+	wait(metadata.Merge(map[string]MetadataValue{
+		"cost":    NumberMetadata(42),
+		"billed":  BooleanMetadata(true),
+		"created": DateTimeMetadata(created),
+		"tier":    StringMetadata("gold").InDomain(types.MetadataDomainSystem, types.MetadataReadOnly),
+	}))
+
+	values, err := metadata.GetAll()
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 5)
+	c.Assert(values["owner"].String(), Equals, "team-a")
+	cost, err := values["cost"].Number()
+	c.Assert(err, IsNil)
+	c.Assert(cost, Equals, int64(42))
+	billed, err := values["billed"].Bool()
+	c.Assert(err, IsNil)
+	c.Assert(billed, Equals, true)
+	at, err := values["created"].Time()
+	c.Assert(err, IsNil)
+	c.Assert(at.Equal(created), Equals, true, Commentf("created at %s", at))
+	c.Assert(values["tier"].Domain, Equals, types.MetadataDomainSystem)
+	c.Assert(values["tier"].Visibility, Equals, types.MetadataReadOnly)
+	_, err = values["owner"].Number()
+	c.Assert(err, NotNil)
+
+	value, err := metadata.Get("cost")
+	c.Assert(err, IsNil)
+	c.Assert(value.Type, Equals, types.MetadataNumberValue)
+	c.Assert(value.Value, Equals, "42")
+
+	// Queries filter on metadata and return the values asked for
+	vm := vapp.VApp.Children.VM[0]
+	fake.SetMetadata(vm.HREF, "role", "frontend")
+	it := client.QueryIterator(NewQuery(types.QtVm).
+		Filter(MetadataEq("role", StringMetadata("front*"))).
+		Fields("name", MetadataField("role")))
+	var found []string
+	for it.Next() {
+		found = append(found, it.VM().Name+"="+MetadataMap(it.VM().Metadata)["role"].String())
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Assert(found, DeepEquals, []string{"web01=frontend"})
+
+	it = client.QueryIterator(NewQuery(types.QtVapp).Filter(MetadataGt("cost", NumberMetadata(40))))
+	found = nil
+	for it.Next() {
+		found = append(found, it.VApp().Name)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Assert(found, DeepEquals, []string{"web"})
+
+	// Replace deletes the keys it isn't given, in every domain
+	c.Assert(metadata.Replace(map[string]MetadataValue{"owner": StringMetadata("team-b")}), IsNil)
+	values, err = metadata.GetAll()
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 1)
+	c.Assert(values["owner"].String(), Equals, "team-b")
+
+	wait(metadata.Delete("owner"))
+	_, err = metadata.Get("owner")
+	c.Assert(err, NotNil)
+
+}
+
+var metadataExample = `<?xml version="1.0" encoding="UTF-8"?>
+<Metadata xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" type="application/vnd.vmware.vcloud.metadata+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata">
+    <Link rel="up" type="application/vnd.vmware.vcloud.vApp+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000"/>
+    <MetadataEntry type="application/vnd.vmware.vcloud.metadata.value+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata/owner">
+        <Key>owner</Key>
+        <TypedValue xsi:type="MetadataStringValue">
+            <Value>team-a</Value>
+        </TypedValue>
+    </MetadataEntry>
+    <MetadataEntry type="application/vnd.vmware.vcloud.metadata.value+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata/cost">
+        <Key>cost</Key>
+        <TypedValue xsi:type="MetadataNumberValue">
+            <Value>42</Value>
+        </TypedValue>
+    </MetadataEntry>
+    <MetadataEntry type="application/vnd.vmware.vcloud.metadata.value+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata/SYSTEM/tier">
+        <Domain visibility="READONLY">SYSTEM</Domain>
+        <Key>tier</Key>
+        <TypedValue xsi:type="MetadataStringValue">
+            <Value>gold</Value>
+        </TypedValue>
+    </MetadataEntry>
+</Metadata>
+`
+
+var metadataValueExample = `<?xml version="1.0" encoding="UTF-8"?>
+<MetadataValue xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" type="application/vnd.vmware.vcloud.metadata.value+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata/cost">
+    <Link rel="up" type="application/vnd.vmware.vcloud.metadata+xml" href="http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000/metadata"/>
+    <TypedValue xsi:type="MetadataNumberValue">
+        <Value>42</Value>
+    </TypedValue>
+</MetadataValue>
+`
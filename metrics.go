@@ -0,0 +1,193 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives measurements of the requests and tasks of a client. It is
+// the extension point for Prometheus, OpenTelemetry or any other metrics
+// system: counters are the Inc methods, histograms the Observe ones. The
+// methods are called from every goroutine sharing the client, so
+// implementations must be safe for concurrent use.
+//
+// Labels are kept to a bounded set of values: entity types are taken from
+// the request path without identifiers, e.g. "vApp", "vm" or "edgeGateway",
+// and tasks are labelled with their operation name, e.g. "vappDeploy".
+type Metrics interface {
+	// ObserveRequest is called once for every HTTP request sent, retries
+	// included. statusCode is 0 when no response was received.
+	ObserveRequest(method, entityType string, statusCode int, duration time.Duration)
+	// IncRetry is called every time a failed request is sent again.
+	IncRetry(method, entityType string)
+	// IncBusyWait is called every time a request is sent again because the
+	// target entity was busy with another operation. Such retries are
+	// counted by IncRetry too.
+	IncBusyWait(method, entityType string)
+	// ObserveTask is called when WaitTaskCompletion sees a task finish.
+	// duration is the run time reported by the server, or the time spent
+	// waiting if the server didn't report it.
+	ObserveTask(operation, status string, duration time.Duration)
+}
+
+func (c *Client) observeRequest(req *http.Request, resp *http.Response, duration time.Duration) {
+	if c.Metrics == nil {
+		return
+	}
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.Metrics.ObserveRequest(req.Method, entityType(req.URL), status, duration)
+}
+
+func (c *Client) countRetry(req *http.Request, err error) {
+	if c.Metrics == nil {
+		return
+	}
+	method, entity := req.Method, entityType(req.URL)
+	c.Metrics.IncRetry(method, entity)
+	if IsBusy(err) {
+		c.Metrics.IncBusyWait(method, entity)
+	}
+}
+
+// entityType returns the type of entity u refers to, e.g. "vApp" for
+// /api/vApp/vapp-<id> or "edgeGateway" for /api/admin/edgeGateway/<id>. VMs
+// share the vApp path and are told apart by their identifier prefix.
+func entityType(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	// vCloud Air nests the vCloud Director API under its own /api prefix.
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] == "api" {
+			segments = segments[i+1:]
+			break
+		}
+	}
+	for len(segments) > 1 && (segments[0] == "admin" || segments[0] == "extension") {
+		segments = segments[1:]
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	if segments[0] == "vApp" && len(segments) > 1 && strings.HasPrefix(segments[1], "vm-") {
+		return "vm"
+	}
+	return segments[0]
+}
+
+// MemoryMetrics is a Metrics implementation keeping everything in memory. It
+// is meant for tests and for quick inspection, e.g. printing a summary at the
+// end of a provisioning run.
+type MemoryMetrics struct {
+	mu        sync.Mutex
+	requests  map[requestMetric][]time.Duration
+	retries   map[requestMetric]int64
+	busyWaits map[requestMetric]int64
+	tasks     map[taskMetric][]time.Duration
+}
+
+type requestMetric struct {
+	method     string
+	entityType string
+	statusCode int
+}
+
+type taskMetric struct {
+	operation string
+	status    string
+}
+
+// NewMemoryMetrics returns an empty MemoryMetrics.
+func NewMemoryMetrics() *MemoryMetrics {
+	return &MemoryMetrics{
+		requests:  make(map[requestMetric][]time.Duration),
+		retries:   make(map[requestMetric]int64),
+		busyWaits: make(map[requestMetric]int64),
+		tasks:     make(map[taskMetric][]time.Duration),
+	}
+}
+
+// ObserveRequest implements Metrics.
+func (m *MemoryMetrics) ObserveRequest(method, entityType string, statusCode int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := requestMetric{method, entityType, statusCode}
+	m.requests[key] = append(m.requests[key], duration)
+}
+
+// IncRetry implements Metrics.
+func (m *MemoryMetrics) IncRetry(method, entityType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries[requestMetric{method: method, entityType: entityType}]++
+}
+
+// IncBusyWait implements Metrics.
+func (m *MemoryMetrics) IncBusyWait(method, entityType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.busyWaits[requestMetric{method: method, entityType: entityType}]++
+}
+
+// ObserveTask implements Metrics.
+func (m *MemoryMetrics) ObserveTask(operation, status string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := taskMetric{operation, status}
+	m.tasks[key] = append(m.tasks[key], duration)
+}
+
+// Requests returns the durations of the requests sent with the given method
+// to the given entity type and answered with statusCode.
+func (m *MemoryMetrics) Requests(method, entityType string, statusCode int) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.requests[requestMetric{method, entityType, statusCode}]...)
+}
+
+// RequestCount returns the number of requests sent, whatever their method,
+// entity type or outcome.
+func (m *MemoryMetrics) RequestCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, durations := range m.requests {
+		n += len(durations)
+	}
+	return n
+}
+
+// Retries returns the number of requests sent again with the given method to
+// the given entity type.
+func (m *MemoryMetrics) Retries(method, entityType string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.retries[requestMetric{method: method, entityType: entityType}]
+}
+
+// BusyWaits returns the number of requests sent again with the given method
+// because the entity was busy.
+func (m *MemoryMetrics) BusyWaits(method, entityType string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.busyWaits[requestMetric{method: method, entityType: entityType}]
+}
+
+// Tasks returns the durations of the tasks with the given operation name that
+// finished with status.
+func (m *MemoryMetrics) Tasks(operation, status string) []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]time.Duration(nil), m.tasks[taskMetric{operation, status}]...)
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_MetricsRequests(c *C) {
+
+	metrics := NewMemoryMetrics()
+	s.client.Client.Metrics = metrics
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() {
+		s.client.Client.Metrics = nil
+		s.client.Client.RetryPolicy = nil
+	}()
+
+	testServer.Response(400, nil, vcdBusyError)
+	testServer.Response(200, nil, vdcExample)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequests(2)
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	c.Assert(metrics.RequestCount(), Equals, 2)
+	c.Assert(metrics.Requests("GET", "vdc", 400), HasLen, 1)
+	c.Assert(metrics.Requests("GET", "vdc", 200), HasLen, 1)
+	c.Assert(metrics.Retries("GET", "vdc"), Equals, int64(1))
+	c.Assert(metrics.BusyWaits("GET", "vdc"), Equals, int64(1))
+}
+
+func (s *S) Test_MetricsTask(c *C) {
+
+	metrics := NewMemoryMetrics()
+	s.client.Client.Metrics = metrics
+	defer func() { s.client.Client.Metrics = nil }()
+
+	task := NewTask(&s.client.Client)
+	task.Task.HREF = "http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05"
+
+	testServer.Response(200, nil, taskExample)
+	err := task.WaitTaskCompletion()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	// The duration comes from the start and end times of the task.
+	durations := metrics.Tasks("vdcComposeVapp", "success")
+	c.Assert(durations, HasLen, 1)
+	c.Assert(durations[0], Equals, 14856*time.Millisecond)
+	c.Assert(metrics.Requests("GET", "task", 200), HasLen, 1)
+}
+
+func (s *S) Test_EntityType(c *C) {
+
+	for path, expected := range map[string]string{
+		"/api/vApp/vapp-fdb86157-2e1f-4889-9942-0463836d10e1":               "vApp",
+		"/api/vApp/vm-fdb86157-2e1f-4889-9942-0463836d10e1/power/action/on": "vm",
+		"/api/admin/edgeGateway/00000000-0000-0000-0000-000000000000":       "edgeGateway",
+		"/api/compute/api/vdc/00000000-0000-0000-0000-000000000000":         "vdc",
+		"/api/vchs/services": "vchs",
+		"/api/query":         "query",
+		"/":                  "",
+	} {
+		u, _ := url.Parse("http://localhost:4444" + path)
+		c.Assert(entityType(u), Equals, expected, Commentf("path %s", path))
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Middleware wraps the RoundTripper used to talk to the API. It is the hook
+// for logging, tracing, metrics, header injection and anything else that
+// needs to see every request and response.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts an ordinary function to the http.RoundTripper
+// interface.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req).
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Chain wraps rt with the given middleware. The first middleware is the
+// outermost one, so it sees the request first and the response last. A nil
+// rt stands for http.DefaultTransport.
+func Chain(rt http.RoundTripper, middleware ...Middleware) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		rt = middleware[i](rt)
+	}
+	return rt
+}
+
+// Use appends middleware to the client chain.
+func (c *Client) Use(middleware ...Middleware) {
+	c.Middleware = append(c.Middleware, middleware...)
+}
+
+// httpClient returns a copy of c.Http sending requests through the
+// middleware chain.
+func (c *Client) httpClient() *http.Client {
+	hc := c.Http
+	hc.Transport = Chain(c.Http.Transport, c.Middleware...)
+	return &hc
+}
+
+// HeaderMiddleware returns a middleware setting the given headers on every
+// request.
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// SensitiveHeaders lists the headers whose values are masked by
+// RedactHeaders.
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Vchs-Authorization",
+	"X-Vchs-Authorization",
+	"X-Vcloud-Authorization",
+	"X-Vmware-Vcloud-Access-Token",
+}
+
+// RedactHeaders returns a copy of h with the values of SensitiveHeaders
+// masked. The scheme of Authorization headers, e.g. Basic or Bearer, is kept.
+func RedactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range SensitiveHeaders {
+		values := redacted.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = "***"
+			if name == "Authorization" {
+				if j := strings.Index(v, " "); j > 0 {
+					masked[i] = v[:j] + " ***"
+				}
+			}
+		}
+		redacted[http.CanonicalHeaderKey(name)] = masked
+	}
+	return redacted
+}
+
+// SensitiveBodyFields lists the form fields and JSON keys whose values are
+// masked by RedactBody.
+var SensitiveBodyFields = []string{
+	"access_token",
+	"client_secret",
+	"id_token",
+	"password",
+	"refresh_token",
+}
+
+// RedactBody returns body with the values of SensitiveBodyFields masked, be
+// it a form such as grant_type=refresh_token&refresh_token=... or a JSON
+// document such as {"access_token":"..."}.
+func RedactBody(body string) string {
+	if body == "" || len(SensitiveBodyFields) == 0 {
+		return body
+	}
+	names := make([]string, len(SensitiveBodyFields))
+	for i, name := range SensitiveBodyFields {
+		names[i] = regexp.QuoteMeta(name)
+	}
+	fields := strings.Join(names, "|")
+	formField := regexp.MustCompile(`(^|&)(` + fields + `)=[^&]*`)
+	body = formField.ReplaceAllString(body, "${1}${2}=***")
+	jsonField := regexp.MustCompile(`("(?:` + fields + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	return jsonField.ReplaceAllString(body, `${1}"***"`)
+}
+
+// Logger is the logging interface used by LoggingMiddleware, a *log.Logger
+// satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// LoggingMiddleware returns a middleware writing one key=value line for each
+// request and response to logger. Credentials are masked with RedactHeaders.
+// Bodies are logged too when logBodies is set, masked with RedactBody.
+func LoggingMiddleware(logger Logger, logBodies bool) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+
+			if logBodies {
+				logger.Printf("[DEBUG] govcloudair request method=%s url=%q headers=%v body=%q",
+					req.Method, req.URL, RedactHeaders(req.Header), RedactBody(requestBody(req)))
+			} else {
+				logger.Printf("[DEBUG] govcloudair request method=%s url=%q headers=%v",
+					req.Method, req.URL, RedactHeaders(req.Header))
+			}
+
+			start := time.Now()
+			resp, err := next.RoundTrip(req)
+			elapsed := time.Since(start)
+
+			if err != nil {
+				logger.Printf("[DEBUG] govcloudair response method=%s url=%q duration=%s error=%q",
+					req.Method, req.URL, elapsed, err)
+				return resp, err
+			}
+
+			if logBodies {
+				logger.Printf("[DEBUG] govcloudair response method=%s url=%q duration=%s status=%d headers=%v body=%q",
+					req.Method, req.URL, elapsed, resp.StatusCode, RedactHeaders(resp.Header), RedactBody(responseBody(resp)))
+			} else {
+				logger.Printf("[DEBUG] govcloudair response method=%s url=%q duration=%s status=%d headers=%v",
+					req.Method, req.URL, elapsed, resp.StatusCode, RedactHeaders(resp.Header))
+			}
+
+			return resp, nil
+		})
+	}
+}
+
+// requestBody returns a copy of the request body without consuming it.
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := ioutil.ReadAll(body)
+	return string(data)
+}
+
+// responseBody reads the response body and puts back a copy for the caller.
+func responseBody(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return string(data)
+}
+
+// debugMiddleware returns the logging middleware installed by the client
+// constructors when GOVCLOUDAIR_DEBUG is set to true.
+func debugMiddleware() []Middleware {
+	if os.Getenv("GOVCLOUDAIR_DEBUG") != "true" {
+		return nil
+	}
+	return []Middleware{LoggingMiddleware(log.Default(), true)}
+}
@@ -5,10 +5,11 @@
 package govcloudair
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 
-	types "github.com/ukcloud/govcloudair/types/v56"
+	types "github.com/stasian/govcloudair/types/v56"
 )
 
 type Org struct {
@@ -24,26 +25,32 @@ func NewOrg(c *Client) *Org {
 }
 
 func (o *Org) FindCatalog(catalog string) (Catalog, error) {
+	return o.FindCatalogWithContext(context.Background(), catalog)
+}
+
+func (o *Org) FindCatalogWithContext(ctx context.Context, catalog string) (_ Catalog, err error) {
+	ctx, span := o.c.startSpan(ctx, "Org.FindCatalog")
+	defer func() { endSpan(span, err) }()
 
 	for _, av := range o.Org.Link {
 		if av.Rel == "down" && av.Type == "application/vnd.vmware.vcloud.catalog+xml" && av.Name == catalog {
 			u, err := url.ParseRequestURI(av.HREF)
 
 			if err != nil {
-				return Catalog{}, fmt.Errorf("error decoding org response: %s", err)
+				return Catalog{}, fmt.Errorf("error decoding org response: %w", err)
 			}
 
-			req := o.c.NewRequest(map[string]string{}, "GET", *u, nil)
+			req := o.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
-			resp, err := checkResp(o.c.Http.Do(req))
+			resp, err := o.c.doRequest(req)
 			if err != nil {
-				return Catalog{}, fmt.Errorf("error retreiving catalog: %s", err)
+				return Catalog{}, fmt.Errorf("error retreiving catalog: %w", err)
 			}
 
 			cat := NewCatalog(o.c)
 
 			if err = decodeBody(resp, cat.Catalog); err != nil {
-				return Catalog{}, fmt.Errorf("error decoding catalog response: %s", err)
+				return Catalog{}, fmt.Errorf("error decoding catalog response: %w", err)
 			}
 
 			// The request was successful
@@ -54,3 +61,75 @@ func (o *Org) FindCatalog(catalog string) (Catalog, error) {
 
 	return Catalog{}, fmt.Errorf("can't find catalog: %s", catalog)
 }
+
+// TaskFilter selects the tasks returned by Org.ListTasks. The zero value
+// selects every task.
+type TaskFilter struct {
+	Status    []string // Statuses to keep, e.g. "queued", "running" or "error", every status if empty
+	Operation string   // Operation name to keep, e.g. "vappDeploy", every operation if empty
+	OwnerHREF string   // HREF of the entity the tasks act on, every entity if empty
+}
+
+// match reports whether task is selected by f.
+func (f TaskFilter) match(task *types.Task) bool {
+	if f.Operation != "" && task.OperationName != f.Operation {
+		return false
+	}
+	if f.OwnerHREF != "" && (task.Owner == nil || task.Owner.HREF != f.OwnerHREF) {
+		return false
+	}
+	if len(f.Status) == 0 {
+		return true
+	}
+	for _, status := range f.Status {
+		if task.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Org) ListTasks(filter TaskFilter) ([]Task, error) {
+	return o.ListTasksWithContext(context.Background(), filter)
+}
+
+// ListTasksWithContext returns the tasks of the organization selected by
+// filter, as listed by the task list of the organization. Each task carries
+// its owner, operation, start time and, for failed ones, the Error element.
+// Use it to find conflicting work in flight before starting an operation.
+func (o *Org) ListTasksWithContext(ctx context.Context, filter TaskFilter) (_ []Task, err error) {
+	ctx, span := o.c.startSpan(ctx, "Org.ListTasks")
+	defer func() { endSpan(span, err) }()
+
+	link := o.Org.Link.ForType(types.MimeTasksList, types.RelDown)
+	if link == nil {
+		return nil, fmt.Errorf("can't find the task list of org %s", o.Org.Name)
+	}
+
+	u, err := url.ParseRequestURI(link.HREF)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding org response: %w", err)
+	}
+
+	req := o.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
+
+	resp, err := o.c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving task list: %w", err)
+	}
+
+	list := new(types.TasksList)
+	if err = decodeBody(resp, list); err != nil {
+		return nil, fmt.Errorf("error decoding task list response: %w", err)
+	}
+
+	var tasks []Task
+	for _, t := range list.Task {
+		if filter.match(t) {
+			tasks = append(tasks, Task{Task: t, c: o.c})
+		}
+	}
+
+	// The request was successful
+	return tasks, nil
+}
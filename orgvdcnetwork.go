@@ -6,14 +6,11 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
-	"log"
-	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
-	"time"
 
 	types "github.com/stasian/govcloudair/types/v56"
 )
@@ -33,58 +30,55 @@ func NewOrgVDCNetwork(c *Client) *OrgVDCNetwork {
 }
 
 func (o *OrgVDCNetwork) Refresh() error {
+	return o.RefreshWithContext(context.Background())
+}
+
+func (o *OrgVDCNetwork) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := o.c.startSpan(ctx, "OrgVDCNetwork.Refresh")
+	defer func() { endSpan(span, err) }()
 	if o.OrgVDCNetwork.HREF == "" {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(o.OrgVDCNetwork.HREF)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.OrgVDCNetwork{}
 
-	req := o.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-	resp, err := checkResp(o.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retrieving task: %s", err)
+	if err = o.c.getEntity(ctx, o.OrgVDCNetwork.HREF, "network", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	o.OrgVDCNetwork = &types.OrgVDCNetwork{}
-
-	if err = decodeBody(resp, o.OrgVDCNetwork); err != nil {
-		return fmt.Errorf("error decoding task response: %s", err)
-	}
+	o.OrgVDCNetwork = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (o *OrgVDCNetwork) Delete() (Task, error) {
-	err := o.Refresh()
+	return o.DeleteWithContext(context.Background())
+}
+
+func (o *OrgVDCNetwork) DeleteWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := o.c.startSpan(ctx, "OrgVDCNetwork.Delete")
+	defer func() { endSpan(span, err) }()
+	err = o.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("Error refreshing network: %s", err)
+		return Task{}, fmt.Errorf("Error refreshing network: %w", err)
 	}
 	pathArr := strings.Split(o.OrgVDCNetwork.HREF, "/")
 	s, _ := url.ParseRequestURI(o.OrgVDCNetwork.HREF)
 	s.Path = "/api/admin/network/" + pathArr[len(pathArr)-1]
 
-	var resp *http.Response
-	for {
-		req := o.c.NewRequest(map[string]string{}, "DELETE", *s, nil)
-		resp, err = checkResp(o.c.Http.Do(req))
-		if err != nil {
-			if v, _ := regexp.MatchString("is busy, cannot proceed with the operation.$", err.Error()); v {
-				time.Sleep(3 * time.Second)
-				continue
-			}
-			return Task{}, fmt.Errorf("error deleting Network: %s", err)
-		}
-		break
+	req := o.c.NewRequestWithContext(ctx, map[string]string{}, "DELETE", *s, nil)
+	resp, err := o.c.doRequest(req)
+	if err != nil {
+		return Task{}, fmt.Errorf("error deleting Network: %w", err)
 	}
 
 	task := NewTask(o.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -92,49 +86,43 @@ func (o *OrgVDCNetwork) Delete() (Task, error) {
 }
 
 func (v *Vdc) CreateOrgVDCNetwork(networkConfig *types.OrgVDCNetwork) error {
+	return v.CreateOrgVDCNetworkWithContext(context.Background(), networkConfig)
+}
+
+func (v *Vdc) CreateOrgVDCNetworkWithContext(ctx context.Context, networkConfig *types.OrgVDCNetwork) (err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.CreateOrgVDCNetwork")
+	defer func() { endSpan(span, err) }()
 	for _, av := range v.Vdc.Link {
 		if av.Rel == "add" && av.Type == "application/vnd.vmware.vcloud.orgVdcNetwork+xml" {
 			u, err := url.ParseRequestURI(av.HREF)
 			//return fmt.Errorf("Test output: %#v")
 
 			if err != nil {
-				return fmt.Errorf("error decoding vdc response: %s", err)
+				return fmt.Errorf("error decoding vdc response: %w", err)
 			}
 
 			output, err := xml.MarshalIndent(networkConfig, "  ", "    ")
 			if err != nil {
-				return fmt.Errorf("error marshaling OrgVDCNetwork compose: %s", err)
+				return fmt.Errorf("error marshaling OrgVDCNetwork compose: %w", err)
 			}
 
 			//return fmt.Errorf("Test output: %s\n%#v", b, v.c)
 
-			var resp *http.Response
-			for {
-				b := bytes.NewBufferString(xml.Header + string(output))
-				log.Printf("[DEBUG] VCD Client configuration: %s", b)
-				req := v.c.NewRequest(map[string]string{}, "POST", *u, b)
-				req.Header.Add("Content-Type", av.Type)
-				resp, err = checkResp(v.c.Http.Do(req))
-				if err != nil {
-					if v, _ := regexp.MatchString("is busy, cannot proceed with the operation.$", err.Error()); v {
-						time.Sleep(3 * time.Second)
-						continue
-					}
-					return fmt.Errorf("error instantiating a new OrgVDCNetwork: %s", err)
-				}
-				break
+			b := bytes.NewBufferString(xml.Header + string(output))
+			req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *u, b)
+			req.Header.Add("Content-Type", av.Type)
+			resp, err := v.c.doRequest(req)
+			if err != nil {
+				return fmt.Errorf("error instantiating a new OrgVDCNetwork: %w", err)
 			}
 			newstuff := NewOrgVDCNetwork(v.c)
 			if err = decodeBody(resp, newstuff.OrgVDCNetwork); err != nil {
-				return fmt.Errorf("error decoding orgvdcnetwork response: %s", err)
+				return fmt.Errorf("error decoding orgvdcnetwork response: %w", err)
 			}
-			task := NewTask(v.c)
-			for _, t := range newstuff.OrgVDCNetwork.Tasks.Task {
-				task.Task = t
-				err = task.WaitTaskCompletion()
-				if err != nil {
-					return fmt.Errorf("Error performing task: %#v", err)
-				}
+			tasks := &TaskGroup{FailFast: true}
+			tasks.addTasks(v.c, newstuff.OrgVDCNetwork.Tasks)
+			if _, err = tasks.WaitAll(ctx); err != nil {
+				return fmt.Errorf("Error performing task: %w", err)
 			}
 		}
 	}
@@ -5,14 +5,20 @@
 package govcloudair
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 
 	types "github.com/ukcloud/govcloudair/types/v56"
 )
 
 type Results struct {
-	Results *types.QueryResultRecordsType
-	c       *Client
+	Results    *types.QueryResultRecordsType
+	References *types.QueryResultReferencesType // Set instead of Results in references format
+	Type       string                           // Type of entities queried
+	c          *Client
 }
 
 func NewResults(c *Client) *Results {
@@ -23,20 +29,322 @@ func NewResults(c *Client) *Results {
 }
 
 func (c *VCDClient) Query(params map[string]string) (Results, error) {
+	return c.QueryWithContext(context.Background(), params)
+}
+
+func (c *VCDClient) QueryWithContext(ctx context.Context, params map[string]string) (_ Results, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.Query")
+	defer func() { endSpan(span, err) }()
 
-	req := c.Client.NewRequest(params, "GET", c.QueryHREF, nil)
-	req.Header.Add("Accept", "vnd.vmware.vcloud.org+xml;version=5.5")
+	req := c.Client.NewRequestWithContext(ctx, params, "GET", c.QueryHREF, nil)
+	req.Header.Add("Accept", c.Client.acceptHeader("vnd.vmware.vcloud.org+xml"))
 
-	resp, err := checkResp(c.Client.Http.Do(req))
+	resp, err := c.Client.doRequest(req)
 	if err != nil {
-		return Results{}, fmt.Errorf("error retreiving query: %s", err)
+		return Results{}, fmt.Errorf("error retreiving query: %w", err)
 	}
 
 	results := NewResults(&c.Client)
+	results.Type = params["type"]
 
-	if err = decodeBody(resp, results.Results); err != nil {
-		return Results{}, fmt.Errorf("error decoding query results: %s", err)
+	if params["format"] == types.QueryFormatReferences {
+		results.References = new(types.QueryResultReferencesType)
+		err = decodeBody(resp, results.References)
+	} else {
+		err = decodeBody(resp, results.Results)
+	}
+	if err != nil {
+		return Results{}, fmt.Errorf("error decoding query results: %w", err)
 	}
 
 	return *results, nil
 }
+
+// Records returns the records of the type queried, e.g. the
+// *types.QueryResultCatalogRecordType of a catalog query, or the references
+// in references format.
+func (r Results) Records() []interface{} {
+	if r.References != nil {
+		items := make([]interface{}, 0, len(r.References.Reference))
+		for _, ref := range r.References.Reference {
+			items = append(items, ref)
+		}
+		return items
+	}
+	return queryRecords(r.Type, r.Results)
+}
+
+// queryRecordFields maps the query types to the field of
+// types.QueryResultRecordsType holding their records.
+var queryRecordFields = map[string]string{
+	types.QtVapp:                          "VAppRecord",
+	types.QtAdminVapp:                     "AdminVAppRecord",
+	types.QtVm:                            "VMRecord",
+	types.QtAdminVm:                       "AdminVMRecord",
+	types.QtVappTemplate:                  "VAppTemplateRecord",
+	types.QtAdminVappTemplate:             "AdminVAppTemplateRecord",
+	types.QtCatalog:                       "CatalogRecord",
+	types.QtAdminCatalog:                  "AdminCatalogRecord",
+	types.QtCatalogItem:                   "CatalogItemRecord",
+	types.QtAdminCatalogItem:              "AdminCatalogItemRecord",
+	types.QtMedia:                         "MediaRecord",
+	types.QtAdminMedia:                    "AdminMediaRecord",
+	types.QtOrgVdcNetwork:                 "OrgVdcNetworkRecord",
+	types.QtOrgVdc:                        "OrgVdcRecord",
+	types.QtAdminOrgVdc:                   "AdminOrgVdcRecord",
+	types.QtTask:                          "TaskRecord",
+	types.QtAdminTask:                     "AdminTaskRecord",
+	types.QtEvent:                         "EventRecord",
+	types.QtAdminEvent:                    "AdminEventRecord",
+	types.QtDisk:                          "DiskRecord",
+	types.QtAdminDisk:                     "AdminDiskRecord",
+	types.QtUser:                          "UserRecord",
+	types.QtAdminUser:                     "AdminUserRecord",
+	types.QtGroup:                         "GroupRecord",
+	types.QtAdminGroup:                    "AdminGroupRecord",
+	types.QtEdgeGateway:                   "EdgeGatewayRecord",
+	types.QtOrgVdcStorageProfile:          "OrgVdcStorageProfileRecord",
+	types.QtAdminOrgVdcStorageProfile:     "AdminOrgVdcStorageProfileRecord",
+	types.QtAllocatedExternalAddress:      "AllocatedExternalAddressRecord",
+	types.QtAdminAllocatedExternalAddress: "AdminAllocatedExternalAddressRecord",
+}
+
+// queryRecords returns the records of queryType held by records, as pointers
+// to the record types. The records of every type are returned if queryType is
+// unknown.
+func queryRecords(queryType string, records *types.QueryResultRecordsType) []interface{} {
+	if records == nil {
+		return nil
+	}
+	v := reflect.ValueOf(records).Elem()
+	var items []interface{}
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if field, ok := queryRecordFields[queryType]; ok && field != name {
+			continue
+		}
+		if !strings.HasSuffix(name, "Record") || v.Field(i).Kind() != reflect.Slice {
+			continue
+		}
+		for j := 0; j < v.Field(i).Len(); j++ {
+			items = append(items, v.Field(i).Index(j).Interface())
+		}
+	}
+	return items
+}
+
+// Query builds the parameters of a request to the query service, e.g.
+//
+//	q := NewQuery(types.QtVm).
+//		Filter(And(Eq("status", "POWERED_ON"), Ne("name", "web*"))).
+//		SortAsc("name").
+//		Fields("name", "status").
+//		PageSize(128)
+//	results, err := client.Query(q.Params())
+//
+// Use QueryIterator to go through every page of the results.
+type Query struct {
+	queryType string
+	format    string
+	filters   []FilterExpr
+	sortAsc   string
+	sortDesc  string
+	fields    []string
+	page      int
+	pageSize  int
+	prefetch  int
+}
+
+// NewQuery returns a query for entities of the given type, one of the
+// types.Qt constants, in records format.
+func NewQuery(queryType string) *Query {
+	return &Query{queryType: queryType, format: types.QueryFormatRecords}
+}
+
+// Type returns the type of entities queried.
+func (q *Query) Type() string {
+	return q.queryType
+}
+
+// Format sets the format of the results, one of the types.QueryFormat
+// constants.
+func (q *Query) Format(format string) *Query {
+	q.format = format
+	return q
+}
+
+// References asks for the results in references format.
+func (q *Query) References() *Query {
+	return q.Format(types.QueryFormatReferences)
+}
+
+// Filter restricts the results to the entities matching expr. Filters added
+// by successive calls must all match.
+func (q *Query) Filter(expr FilterExpr) *Query {
+	q.filters = append(q.filters, expr)
+	return q
+}
+
+// SortAsc sorts the results by field, in ascending order.
+func (q *Query) SortAsc(field string) *Query {
+	q.sortAsc, q.sortDesc = field, ""
+	return q
+}
+
+// SortDesc sorts the results by field, in descending order.
+func (q *Query) SortDesc(field string) *Query {
+	q.sortAsc, q.sortDesc = "", field
+	return q
+}
+
+// Fields restricts the attributes returned in each record to fields.
+func (q *Query) Fields(fields ...string) *Query {
+	q.fields = append(q.fields, fields...)
+	return q
+}
+
+// Page asks for the given page of results, counting from 1.
+func (q *Query) Page(page int) *Query {
+	q.page = page
+	return q
+}
+
+// PageSize sets the number of results per page. The server caps it, usually
+// at 128 records.
+func (q *Query) PageSize(size int) *Query {
+	q.pageSize = size
+	return q
+}
+
+// Prefetch makes a QueryIterator fetch up to n pages ahead, concurrently,
+// while the current one is being read. It has no effect on the parameters.
+func (q *Query) Prefetch(n int) *Query {
+	q.prefetch = n
+	return q
+}
+
+// Params returns the query as request parameters, as expected by
+// VCDClient.Query.
+func (q *Query) Params() map[string]string {
+	params := map[string]string{"type": q.queryType}
+	if q.format != "" {
+		params["format"] = q.format
+	}
+	if len(q.filters) > 0 {
+		params["filter"] = And(q.filters...).String()
+	}
+	if q.sortAsc != "" {
+		params["sortAsc"] = q.sortAsc
+	}
+	if q.sortDesc != "" {
+		params["sortDesc"] = q.sortDesc
+	}
+	if len(q.fields) > 0 {
+		params["fields"] = strings.Join(q.fields, ",")
+	}
+	if q.page > 0 {
+		params["page"] = strconv.Itoa(q.page)
+	}
+	if q.pageSize > 0 {
+		params["pageSize"] = strconv.Itoa(q.pageSize)
+	}
+	return params
+}
+
+// FilterExpr is a filter expression of the query service, built with Eq, Ne,
+// Gt, Ge, Lt, Le, And and Or. Values are escaped, so they can hold any
+// character; a * in a value is a wildcard.
+type FilterExpr struct {
+	expr     string
+	compound bool
+}
+
+// String returns the expression in the syntax of the filter parameter.
+func (f FilterExpr) String() string {
+	return f.expr
+}
+
+// RawFilter wraps an expression already in the syntax of the filter
+// parameter. It is not escaped.
+func RawFilter(expr string) FilterExpr {
+	return FilterExpr{expr: expr, compound: strings.ContainsAny(expr, ";,")}
+}
+
+// Eq matches the entities whose field is equal to value.
+func Eq(field, value string) FilterExpr {
+	return condition(field, "==", value)
+}
+
+// Ne matches the entities whose field is not equal to value.
+func Ne(field, value string) FilterExpr {
+	return condition(field, "!=", value)
+}
+
+// Gt matches the entities whose field is greater than value.
+func Gt(field, value string) FilterExpr {
+	return condition(field, "=gt=", value)
+}
+
+// Ge matches the entities whose field is greater than or equal to value.
+func Ge(field, value string) FilterExpr {
+	return condition(field, "=ge=", value)
+}
+
+// Lt matches the entities whose field is lower than value.
+func Lt(field, value string) FilterExpr {
+	return condition(field, "=lt=", value)
+}
+
+// Le matches the entities whose field is lower than or equal to value.
+func Le(field, value string) FilterExpr {
+	return condition(field, "=le=", value)
+}
+
+// And matches the entities matching every expression.
+func And(exprs ...FilterExpr) FilterExpr {
+	return combine(";", exprs)
+}
+
+// Or matches the entities matching any of the expressions.
+func Or(exprs ...FilterExpr) FilterExpr {
+	return combine(",", exprs)
+}
+
+func condition(field, operator, value string) FilterExpr {
+	return FilterExpr{expr: field + operator + escapeFilterValue(value)}
+}
+
+func combine(separator string, exprs []FilterExpr) FilterExpr {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		if e.expr == "" {
+			continue
+		}
+		if e.compound {
+			parts = append(parts, "("+e.expr+")")
+		} else {
+			parts = append(parts, e.expr)
+		}
+	}
+	return FilterExpr{expr: strings.Join(parts, separator), compound: len(parts) > 1}
+}
+
+// escapeFilterValue percent-encodes the characters of value that have a
+// meaning in filter expressions, or in URLs, keeping * wildcards.
+func escapeFilterValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '*', c == ':':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
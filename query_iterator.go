@@ -0,0 +1,341 @@
+/*
+ * Copyright 2016 Skyscape Cloud Services.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// QueryIterator goes through every result of a query, page after page:
+//
+//	it := client.QueryIterator(NewQuery(types.QtVm).PageSize(128))
+//	defer it.Close()
+//	for it.Next() {
+//		vm := it.VM()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Pages are fetched by following the nextPage link of the previous one, or
+// concurrently ahead of time if the query sets Prefetch. A QueryIterator must
+// not be used from several goroutines at once.
+type QueryIterator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *VCDClient
+	query  *Query
+
+	page    *queryPage
+	index   int
+	current interface{}
+	started bool
+	err     error
+
+	prefetched chan chan queryPageResult // Pages fetched ahead, in order, with Prefetch
+	slots      chan struct{}             // Pages fetched ahead and not read yet
+}
+
+// queryPage is a page of results, in records or references format.
+type queryPage struct {
+	total    int
+	pageSize int
+	next     *url.URL
+	items    []interface{}
+}
+
+type queryPageResult struct {
+	page *queryPage
+	err  error
+}
+
+// QueryIterator returns an iterator over every result of q.
+func (c *VCDClient) QueryIterator(q *Query) *QueryIterator {
+	return c.QueryIteratorWithContext(context.Background(), q)
+}
+
+// QueryIteratorWithContext returns an iterator over every result of q. The
+// iteration stops with the context error when ctx is cancelled.
+func (c *VCDClient) QueryIteratorWithContext(ctx context.Context, q *Query) *QueryIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	return &QueryIterator{ctx: ctx, cancel: cancel, client: c, query: q}
+}
+
+// Next moves to the next result, fetching the next page if needed. It returns
+// false once every result has been read or when an error occurred, see Err.
+func (it *QueryIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.page == nil || it.index >= len(it.page.items) {
+		if err := it.ctx.Err(); err != nil {
+			it.fail(err)
+			return false
+		}
+		page, err := it.nextPage()
+		if err != nil {
+			it.fail(err)
+			return false
+		}
+		if page == nil {
+			it.Close()
+			return false
+		}
+		it.page, it.index = page, 0
+	}
+	it.current = it.page.items[it.index]
+	it.index++
+	return true
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and the pages being fetched ahead. Breaking out of
+// a loop over Next early should be followed by Close.
+func (it *QueryIterator) Close() {
+	it.cancel()
+}
+
+// Total returns the total number of results reported by the server, once
+// Next has been called.
+func (it *QueryIterator) Total() int {
+	if it.page == nil {
+		return 0
+	}
+	return it.page.total
+}
+
+// Record returns the current result, a pointer to one of the types.QueryResult
+// record types, or a *types.Reference in references format.
+func (it *QueryIterator) Record() interface{} {
+	return it.current
+}
+
+// Reference returns the current result in references format, nil otherwise.
+func (it *QueryIterator) Reference() *types.Reference {
+	r, _ := it.current.(*types.Reference)
+	return r
+}
+
+// VM returns the current result if it is a VM record, nil otherwise.
+func (it *QueryIterator) VM() *types.QueryResultVMRecordType {
+	r, _ := it.current.(*types.QueryResultVMRecordType)
+	return r
+}
+
+// VApp returns the current result if it is a vApp record, nil otherwise.
+func (it *QueryIterator) VApp() *types.QueryResultVAppRecordType {
+	r, _ := it.current.(*types.QueryResultVAppRecordType)
+	return r
+}
+
+// EdgeGateway returns the current result if it is an edge gateway record, nil
+// otherwise.
+func (it *QueryIterator) EdgeGateway() *types.QueryResultEdgeGatewayRecordType {
+	r, _ := it.current.(*types.QueryResultEdgeGatewayRecordType)
+	return r
+}
+
+// OrgVdcStorageProfile returns the current result if it is a storage profile
+// record, nil otherwise.
+func (it *QueryIterator) OrgVdcStorageProfile() *types.QueryResultOrgVdcStorageProfileRecordType {
+	r, _ := it.current.(*types.QueryResultOrgVdcStorageProfileRecordType)
+	return r
+}
+
+// Catalog returns the current result if it is a catalog record, nil otherwise.
+func (it *QueryIterator) Catalog() *types.QueryResultCatalogRecordType {
+	r, _ := it.current.(*types.QueryResultCatalogRecordType)
+	return r
+}
+
+// CatalogItem returns the current result if it is a catalog item record, nil otherwise.
+func (it *QueryIterator) CatalogItem() *types.QueryResultCatalogItemRecordType {
+	r, _ := it.current.(*types.QueryResultCatalogItemRecordType)
+	return r
+}
+
+// VAppTemplate returns the current result if it is a vApp template record, nil otherwise.
+func (it *QueryIterator) VAppTemplate() *types.QueryResultVAppTemplateRecordType {
+	r, _ := it.current.(*types.QueryResultVAppTemplateRecordType)
+	return r
+}
+
+// Media returns the current result if it is a media record, nil otherwise.
+func (it *QueryIterator) Media() *types.QueryResultMediaRecordType {
+	r, _ := it.current.(*types.QueryResultMediaRecordType)
+	return r
+}
+
+// OrgVdcNetwork returns the current result if it is an org VDC network record, nil otherwise.
+func (it *QueryIterator) OrgVdcNetwork() *types.QueryResultOrgVdcNetworkRecordType {
+	r, _ := it.current.(*types.QueryResultOrgVdcNetworkRecordType)
+	return r
+}
+
+// OrgVdc returns the current result if it is an org VDC record, nil otherwise.
+func (it *QueryIterator) OrgVdc() *types.QueryResultOrgVdcRecordType {
+	r, _ := it.current.(*types.QueryResultOrgVdcRecordType)
+	return r
+}
+
+// Task returns the current result if it is a task record, nil otherwise.
+func (it *QueryIterator) Task() *types.QueryResultTaskRecordType {
+	r, _ := it.current.(*types.QueryResultTaskRecordType)
+	return r
+}
+
+// Event returns the current result if it is an event record, nil otherwise.
+func (it *QueryIterator) Event() *types.QueryResultEventRecordType {
+	r, _ := it.current.(*types.QueryResultEventRecordType)
+	return r
+}
+
+// Disk returns the current result if it is a disk record, nil otherwise.
+func (it *QueryIterator) Disk() *types.QueryResultDiskRecordType {
+	r, _ := it.current.(*types.QueryResultDiskRecordType)
+	return r
+}
+
+// User returns the current result if it is an user record, nil otherwise.
+func (it *QueryIterator) User() *types.QueryResultUserRecordType {
+	r, _ := it.current.(*types.QueryResultUserRecordType)
+	return r
+}
+
+// Group returns the current result if it is a group record, nil otherwise.
+func (it *QueryIterator) Group() *types.QueryResultGroupRecordType {
+	r, _ := it.current.(*types.QueryResultGroupRecordType)
+	return r
+}
+
+// AllocatedExternalAddress returns the current result if it is an allocated external address record, nil otherwise.
+func (it *QueryIterator) AllocatedExternalAddress() *types.QueryResultAllocatedExternalAddressRecordType {
+	r, _ := it.current.(*types.QueryResultAllocatedExternalAddressRecordType)
+	return r
+}
+
+func (it *QueryIterator) fail(err error) {
+	it.err = err
+	it.Close()
+}
+
+// nextPage returns the page following the current one, or nil after the last
+// page.
+func (it *QueryIterator) nextPage() (*queryPage, error) {
+	if !it.started {
+		it.started = true
+		page, err := it.client.queryPage(it.ctx, it.client.QueryHREF, it.query.Params())
+		if err == nil && it.query.prefetch > 0 {
+			it.startPrefetch(page)
+		}
+		return page, err
+	}
+
+	if it.prefetched != nil {
+		pending, ok := <-it.prefetched
+		if !ok {
+			return nil, it.ctx.Err()
+		}
+		result := <-pending
+		<-it.slots
+		return result.page, result.err
+	}
+
+	if it.page.next == nil {
+		return nil, nil
+	}
+	params := map[string]string{}
+	for k, v := range it.page.next.Query() {
+		params[k] = v[0]
+	}
+	return it.client.queryPage(it.ctx, *it.page.next, params)
+}
+
+// startPrefetch fetches the pages following first by page number, up to
+// Prefetch of them at the same time. A page keeps its slot until Next reads
+// it, so the iterator never gets more than Prefetch pages ahead of the caller.
+func (it *QueryIterator) startPrefetch(first *queryPage) {
+	if first.next == nil || first.pageSize <= 0 {
+		return
+	}
+	firstPage := it.query.page
+	if firstPage < 1 {
+		firstPage = 1
+	}
+	lastPage := int(math.Ceil(float64(first.total) / float64(first.pageSize)))
+
+	it.prefetched = make(chan chan queryPageResult, it.query.prefetch)
+	it.slots = make(chan struct{}, it.query.prefetch)
+	go func() {
+		defer close(it.prefetched)
+		for n := firstPage + 1; n <= lastPage; n++ {
+			select {
+			case it.slots <- struct{}{}:
+			case <-it.ctx.Done():
+				return
+			}
+			params := it.query.Params()
+			params["page"] = strconv.Itoa(n)
+			params["pageSize"] = strconv.Itoa(first.pageSize)
+			result := make(chan queryPageResult, 1)
+			it.prefetched <- result
+			go func() {
+				page, err := it.client.queryPage(it.ctx, it.client.QueryHREF, params)
+				result <- queryPageResult{page, err}
+			}()
+		}
+	}()
+}
+
+// queryPage fetches and decodes a single page of query results.
+func (c *VCDClient) queryPage(ctx context.Context, u url.URL, params map[string]string) (*queryPage, error) {
+
+	req := c.Client.NewRequestWithContext(ctx, params, "GET", u, nil)
+
+	resp, err := c.Client.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retreiving query: %w", err)
+	}
+
+	page := &queryPage{}
+	var links types.LinkList
+
+	if params["format"] == types.QueryFormatReferences {
+		refs := new(types.QueryResultReferencesType)
+		if err = decodeBody(resp, refs); err != nil {
+			return nil, fmt.Errorf("error decoding query results: %w", err)
+		}
+		page.total, page.pageSize, links = int(refs.Total), refs.PageSize, refs.Link
+		for _, r := range refs.Reference {
+			page.items = append(page.items, r)
+		}
+	} else {
+		records := new(types.QueryResultRecordsType)
+		if err = decodeBody(resp, records); err != nil {
+			return nil, fmt.Errorf("error decoding query results: %w", err)
+		}
+		page.total, page.pageSize, links = int(records.Total), records.PageSize, records.Link
+		page.items = queryRecords(params["type"], records)
+	}
+
+	if next := links.Find(func(l *types.Link) bool { return l != nil && l.Rel == types.RelNextPage }); next != nil {
+		if page.next, err = url.Parse(next.HREF); err != nil {
+			return nil, fmt.Errorf("error decoding next page link: %w", err)
+		}
+	}
+
+	return page, nil
+}
@@ -5,8 +5,14 @@
 package govcloudair
 
 import (
-	// "fmt"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
 	"github.com/ukcloud/govcloudair/testutil"
+	types "github.com/ukcloud/govcloudair/types/v56"
 	. "gopkg.in/check.v1"
 )
 
@@ -14,7 +20,7 @@ func (s *K) Test_Query(c *C) {
 
 	// Get the Org populated
 	testServer.ResponseMap(1, testutil.ResponseMap{
-		"/api/query?type=vm": testutil.Response{200, nil, queryVmExample},
+		"/api/query?type=vm": testutil.Response{Status: 200, Headers: nil, Body: queryVmExample},
 	})
 
 	results, err := s.client.Query(map[string]string{"type": "vm"})
@@ -26,6 +32,176 @@ func (s *K) Test_Query(c *C) {
 	c.Assert(len(results.Results.VMRecord), Equals, 4)
 }
 
+func (s *S) Test_QueryParams(c *C) {
+
+	q := NewQuery(types.QtVm).
+		Filter(Or(Eq("name", "web,db"), Gt("numberOfCpus", "2"))).
+		Filter(Ne("status", "POWERED_OFF")).
+		SortDesc("name").
+		Fields("name", "status").
+		Page(2).
+		PageSize(128)
+
+	c.Assert(q.Params(), DeepEquals, map[string]string{
+		"type":     "vm",
+		"format":   "records",
+		"filter":   "(name==web%2Cdb,numberOfCpus=gt=2);status!=POWERED_OFF",
+		"sortDesc": "name",
+		"fields":   "name,status",
+		"page":     "2",
+		"pageSize": "128",
+	})
+
+	c.Assert(NewQuery(types.QtVapp).References().Params(), DeepEquals, map[string]string{"type": "vApp", "format": "references"})
+	c.Assert(Eq("name", "a;b (c)=d*").String(), Equals, "name==a%3Bb%20%28c%29%3Dd*")
+	c.Assert(And(Eq("a", "1"), RawFilter("b==2,c==3")).String(), Equals, "a==1;(b==2,c==3)")
+}
+
+func (s *S) Test_QueryRecords(c *C) {
+
+	testServer.Response(200, nil, queryCatalogExample)
+	results, err := queryClient().Query(NewQuery(types.QtCatalog).Params())
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(results.Results.CatalogRecord, HasLen, 2)
+	records := results.Records()
+	c.Assert(records, HasLen, 2)
+	catalog, ok := records[1].(*types.QueryResultCatalogRecordType)
+	c.Assert(ok, Equals, true)
+	c.Assert(catalog.Name, Equals, "Public")
+	c.Assert(catalog.NumberOfVAppTemplates, Equals, 12)
+	c.Assert(catalog.IsPublished, Equals, true)
+
+	testServer.Response(200, nil, queryEdgeGatewaysExample)
+	results, err = queryClient().Query(NewQuery(types.QtEdgeGateway).Params())
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(results.Results.EdgeGatewayRecord, HasLen, 2)
+	c.Assert(results.Records()[1].(*types.QueryResultEdgeGatewayRecordType).Name, Equals, "edge-2")
+}
+
+func (s *S) Test_QueryReferences(c *C) {
+
+	testServer.Response(200, nil, queryVmReferencesExample)
+	results, err := queryClient().Query(NewQuery(types.QtVm).References().Params())
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(results.References.Total, Equals, float64(2))
+	records := results.Records()
+	c.Assert(records, HasLen, 2)
+	c.Assert(records[0].(*types.Reference).Name, Equals, "web01")
+	c.Assert(records[1].(*types.Reference).HREF, Equals, "http://localhost:4444/api/vApp/vm-22222222-2222-2222-2222-222222222222")
+}
+
+func (s *S) Test_QueryIterator(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	for i := 0; i < 30; i++ {
+		fake.AddVApp(fmt.Sprintf("vapp%02d", i), fmt.Sprintf("web%02d", i), fmt.Sprintf("db%02d", i))
+	}
+
+	client, _, _ := fakeVCDLogin(c, fake)
+
+	collect := func(it *QueryIterator) []string {
+		defer it.Close()
+		var names []string
+		for it.Next() {
+			names = append(names, it.VM().Name)
+		}
+		c.Assert(it.Err(), IsNil)
+		return names
+	}
+
+	// Every page is read, not only the first 25 records
+	names := collect(client.QueryIterator(NewQuery(types.QtVm).SortAsc("name").PageSize(7)))
+	c.Assert(names, HasLen, 60)
+	c.Assert(names[0], Equals, "db00")
+	c.Assert(names[59], Equals, "web29")
+
+	// Pages fetched ahead are still delivered in order
+	fake.Latency = 20 * time.Millisecond
+	prefetched := collect(client.QueryIterator(NewQuery(types.QtVm).SortAsc("name").PageSize(7).Prefetch(4)))
+	fake.Latency = 0
+	c.Assert(prefetched, DeepEquals, names)
+	c.Assert(fake.MaxInFlight() >= 2, Equals, true, Commentf("max in flight %d", fake.MaxInFlight()))
+
+	// No more than Prefetch pages are fetched ahead of the reader
+	it := client.QueryIterator(NewQuery(types.QtVm).PageSize(7).Prefetch(2))
+	c.Assert(it.Next(), Equals, true)
+	time.Sleep(50 * time.Millisecond)
+	c.Assert(len(it.slots), Equals, 2)
+	c.Assert(len(it.prefetched), Equals, 2)
+	it.Close()
+
+	// Filters and references
+	it = client.QueryIterator(NewQuery(types.QtVm).References().
+		Filter(Or(Eq("name", "web1*"), Eq("containerName", "vapp2*"))).
+		Filter(Ne("name", "db2*")).
+		PageSize(5))
+	var refs []string
+	for it.Next() {
+		refs = append(refs, it.Reference().Name)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Assert(refs, HasLen, 20)
+	c.Assert(it.Total(), Equals, 20)
+	c.Assert(it.VM(), IsNil)
+
+	// Cancelling the context stops the iteration
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it = client.QueryIteratorWithContext(ctx, NewQuery(types.QtVm).PageSize(10))
+	read := 0
+	for it.Next() {
+		read++
+		if read == 15 {
+			cancel()
+		}
+	}
+	c.Assert(errors.Is(it.Err(), context.Canceled), Equals, true, Commentf("got %v", it.Err()))
+	c.Assert(read, Equals, 20)
+
+}
+
+// queryClient returns a VCDClient sending its queries to the test server.
+func queryClient() *VCDClient {
+	u, _ := url.Parse("http://localhost:4444/api")
+	client := NewVCDClient(*u, false)
+	client.QueryHREF = *u
+	client.QueryHREF.Path += "/query"
+	return client
+}
+
+var queryCatalogExample = `<?xml version="1.0" encoding="UTF-8"?>
+<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" name="catalog" page="1" pageSize="25" total="2" href="http://localhost:4444/api/query?type=catalog&amp;page=1&amp;pageSize=25&amp;format=records" type="application/vnd.vmware.vcloud.query.records+xml">
+    <Link rel="alternate" href="http://localhost:4444/api/query?type=catalog&amp;page=1&amp;pageSize=25&amp;format=references" type="application/vnd.vmware.vcloud.query.references+xml"/>
+    <CatalogRecord createdDate="2016-03-01T10:00:00.000Z" description="" isPublished="false" isShared="false" name="DevOps" numberOfMedia="0" numberOfVAppTemplates="3" orgName="org" ownerName="admin" href="http://localhost:4444/api/catalog/11111111-1111-1111-1111-111111111111"/>
+    <CatalogRecord createdDate="2015-01-12T09:30:00.000Z" description="Public templates" isPublished="true" isShared="true" name="Public" numberOfMedia="2" numberOfVAppTemplates="12" orgName="system" ownerName="system" href="http://localhost:4444/api/catalog/22222222-2222-2222-2222-222222222222"/>
+</QueryResultRecords>
+`
+
+var queryEdgeGatewaysExample = `<?xml version="1.0" encoding="UTF-8"?>
+<QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" name="edgeGateway" page="1" pageSize="25" total="2" href="http://localhost:4444/api/query?type=edgeGateway&amp;page=1&amp;pageSize=25&amp;format=records" type="application/vnd.vmware.vcloud.query.records+xml">
+    <EdgeGatewayRecord gatewayStatus="READY" haStatus="UP" isBusy="false" name="edge-1" numberOfExtNetworks="1" numberOfOrgNetworks="2" vdc="http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" href="http://localhost:4444/api/admin/edgeGateway/11111111-1111-1111-1111-111111111111"/>
+    <EdgeGatewayRecord gatewayStatus="READY" haStatus="DISABLED" isBusy="false" name="edge-2" numberOfExtNetworks="1" numberOfOrgNetworks="0" vdc="http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" href="http://localhost:4444/api/admin/edgeGateway/22222222-2222-2222-2222-222222222222"/>
+</QueryResultRecords>
+`
+
+var queryVmReferencesExample = `<?xml version="1.0" encoding="UTF-8"?>
+<QueryResultReferences xmlns="http://www.vmware.com/vcloud/v1.5" name="vm" page="1" pageSize="25" total="2" href="http://localhost:4444/api/query?type=vm&amp;page=1&amp;pageSize=25&amp;format=references" type="application/vnd.vmware.vcloud.query.references+xml">
+    <Link rel="alternate" href="http://localhost:4444/api/query?type=vm&amp;page=1&amp;pageSize=25&amp;format=records" type="application/vnd.vmware.vcloud.query.records+xml"/>
+    <VMReference type="application/vnd.vmware.vcloud.vm+xml" name="web01" id="urn:vcloud:vm:11111111-1111-1111-1111-111111111111" href="http://localhost:4444/api/vApp/vm-11111111-1111-1111-1111-111111111111"/>
+    <VMReference type="application/vnd.vmware.vcloud.vm+xml" name="web02" id="urn:vcloud:vm:22222222-2222-2222-2222-222222222222" href="http://localhost:4444/api/vApp/vm-22222222-2222-2222-2222-222222222222"/>
+</QueryResultReferences>
+`
+
 var queryVmExample = `<?xml version="1.0" encoding="UTF-8"?>
 <QueryResultRecords xmlns="http://www.vmware.com/vcloud/v1.5" name="vm" page="1" pageSize="25" total="4" href="http://localhost:4444/api/query?type=vm&amp;page=1&amp;pageSize=25&amp;format=records" type="application/vnd.vmware.vcloud.query.records+xml" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.vmware.com/vcloud/v1.5 http://10.10.6.15/api/v1.5/schema/master.xsd">
     <Link rel="alternate" href="http://localhost:4444/api/query?type=vm&amp;page=1&amp;pageSize=25&amp;format=references" type="application/vnd.vmware.vcloud.query.references+xml"/>
@@ -0,0 +1,198 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket limit: requests are let through at Rate per
+// second on average, in bursts of up to Burst requests.
+type RateLimit struct {
+	Rate  float64 // Requests per second, zero or less removes the limit
+	Burst int     // Requests let through at once, 1 if zero or less
+}
+
+// RateLimitStats reports how the client rate limiter held requests back.
+type RateLimitStats struct {
+	Requests  int64         // Requests that went through the limiter
+	Delayed   int64         // Requests that had to wait
+	TotalWait time.Duration // Time spent waiting, summed over all requests
+	MaxWait   time.Duration // Longest single wait
+	Throttled int64         // Responses asking the client to slow down with a Retry-After header
+}
+
+// SetRateLimit limits the rate of all the requests sent by the client.
+func (c *Client) SetRateLimit(limit RateLimit) {
+	c.rateLimiter().setLimit("", limit)
+}
+
+// SetMethodRateLimit limits the rate of the requests using the given HTTP
+// method, on top of the limit set with SetRateLimit. Use it to slow down
+// POST and DELETE requests, which start tasks, more than GET ones.
+func (c *Client) SetMethodRateLimit(method string, limit RateLimit) {
+	c.rateLimiter().setLimit(strings.ToUpper(method), limit)
+}
+
+// RateLimitStats returns the rate limiter statistics since the client was
+// created.
+func (c *Client) RateLimitStats() RateLimitStats {
+	l := c.rateLimiter()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+func (c *Client) rateLimiter() *rateLimiter {
+	if l, ok := c.limiter.Load().(*rateLimiter); ok {
+		return l
+	}
+	c.limiter.CompareAndSwap(nil, &rateLimiter{buckets: make(map[string]*tokenBucket)})
+	return c.limiter.Load().(*rateLimiter)
+}
+
+// rateLimiter holds the token buckets of a client. The bucket with the empty
+// key applies to every request, the other ones to a single HTTP method. When
+// the server asks to slow down, every request is held back until the delay
+// it asked for has passed.
+type rateLimiter struct {
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	pausedUntil time.Time
+	stats       RateLimitStats
+}
+
+func (l *rateLimiter) setLimit(method string, limit RateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit.Rate <= 0 {
+		delete(l.buckets, method)
+		return
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	l.buckets[method] = &tokenBucket{limit: limit, tokens: float64(limit.Burst)}
+}
+
+// wait blocks until a request using method can be sent, or until ctx is
+// cancelled.
+func (l *rateLimiter) wait(ctx context.Context, method string) error {
+
+	l.mu.Lock()
+	now := time.Now()
+	var d time.Duration
+	var taken []*tokenBucket
+	for _, key := range []string{"", method} {
+		b, ok := l.buckets[key]
+		if !ok {
+			continue
+		}
+		if bd := b.take(now); bd > d {
+			d = bd
+		}
+		taken = append(taken, b)
+	}
+	if pause := l.pausedUntil.Sub(now); pause > d {
+		d = pause
+	}
+	l.stats.Requests++
+	if d > 0 {
+		l.stats.Delayed++
+		l.stats.TotalWait += d
+		if d > l.stats.MaxWait {
+			l.stats.MaxWait = d
+		}
+	}
+	l.mu.Unlock()
+
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		for _, b := range taken {
+			b.tokens++
+		}
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// throttle holds every request back for d, as asked by the server.
+func (l *rateLimiter) throttle(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Throttled++
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// tokenBucket is guarded by the mutex of its rateLimiter.
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// take removes a token from the bucket and returns how long to wait for it
+// to be available.
+func (b *tokenBucket) take(now time.Time) time.Duration {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+		if burst := float64(b.limit.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// retryAfter returns the delay asked for in the Retry-After header of resp,
+// given either in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) time.Duration {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// serverRetryAfter returns the Retry-After delay carried by err, if any.
+func serverRetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_RateLimit(c *C) {
+
+	s.client.Client.SetRateLimit(RateLimit{Rate: 20, Burst: 1})
+	defer s.client.Client.SetRateLimit(RateLimit{})
+	before := s.client.Client.RateLimitStats()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, vdcExample)
+		c.Assert(s.vdc.Refresh(), IsNil)
+		_ = testServer.WaitRequest()
+	}
+	testServer.Flush()
+
+	// One request goes through straight away, the next ones wait 50ms each.
+	c.Assert(time.Since(start) >= 90*time.Millisecond, Equals, true)
+	stats := s.client.Client.RateLimitStats()
+	c.Assert(stats.Requests-before.Requests, Equals, int64(3))
+	c.Assert(stats.Delayed-before.Delayed, Equals, int64(2))
+	c.Assert(stats.TotalWait > before.TotalWait, Equals, true)
+	c.Assert(stats.MaxWait > 0, Equals, true)
+}
+
+func (s *S) Test_MethodRateLimit(c *C) {
+
+	s.client.Client.SetMethodRateLimit("post", RateLimit{Rate: 0.1, Burst: 1})
+	defer s.client.Client.SetMethodRateLimit("POST", RateLimit{})
+	before := s.client.Client.RateLimitStats()
+
+	// GET requests are not affected by the POST limit
+	for i := 0; i < 3; i++ {
+		testServer.Response(200, nil, vdcExample)
+		c.Assert(s.vdc.Refresh(), IsNil)
+		_ = testServer.WaitRequest()
+	}
+	testServer.Flush()
+
+	stats := s.client.Client.RateLimitStats()
+	c.Assert(stats.Delayed-before.Delayed, Equals, int64(0))
+}
+
+func (s *S) Test_RetryAfter(c *C) {
+
+	policy := testRetryPolicy
+	policy.RetryableStatusCodes = []int{429}
+	s.client.Client.RetryPolicy = &policy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+	before := s.client.Client.RateLimitStats()
+
+	testServer.Response(429, map[string]string{"Retry-After": "1"}, "")
+	testServer.Response(200, nil, vdcExample)
+	start := time.Now()
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequests(2)
+	testServer.Flush()
+
+	c.Assert(err, IsNil)
+	c.Assert(time.Since(start) >= time.Second, Equals, true)
+	stats := s.client.Client.RateLimitStats()
+	c.Assert(stats.Throttled-before.Throttled, Equals, int64(1))
+}
+
+func (s *S) Test_Throttled(c *C) {
+
+	s.client.Client.RetryPolicy = &NoRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	testServer.Response(429, map[string]string{"Retry-After": "Thu, 01 Jan 1970 00:00:00 GMT"}, "")
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+
+	c.Assert(IsThrottled(err), Equals, true)
+	c.Assert(err, ErrorMatches, "error retrieving vdc: API Error: 429: Too Many Requests .*")
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"120"}}}
+	c.Assert(retryAfter(resp), Equals, 2*time.Minute)
+	resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	c.Assert(retryAfter(resp) > 59*time.Minute, Equals, true)
+}
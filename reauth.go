@@ -0,0 +1,110 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CredentialsProvider returns the credentials used to log in again once the
+// session has expired. It is called each time the client re-authenticates, so
+// it can fetch rotated credentials from a vault for instance.
+type CredentialsProvider func(ctx context.Context) (username, password, org string, err error)
+
+// StaticCredentials returns a CredentialsProvider always returning the given
+// credentials.
+func StaticCredentials(username, password, org string) CredentialsProvider {
+	return func(ctx context.Context) (string, string, string, error) {
+		return username, password, org, nil
+	}
+}
+
+// EnableReauthentication makes the client log in again with the credentials
+// returned by provider when a request fails because the session token has
+// expired. Idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are then
+// sent again with the new token, other requests return the original error.
+// Concurrent requests failing on the same expired token trigger a single
+// login.
+func (c *VCDClient) EnableReauthentication(provider CredentialsProvider) {
+	c.Client.reauth = func(ctx context.Context) error {
+		username, password, org, err := provider(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting credentials: %w", err)
+		}
+		if c.loginHREF.Host == "" {
+			return fmt.Errorf("cannot log in again, the session has no LoginUrl")
+		}
+		_, err = c.vcdlogin(ctx, username, password, org)
+		return err
+	}
+}
+
+// authToken returns the authorization header name and session token.
+func (c *Client) authToken() (header, token string) {
+	c.authMu.RLock()
+	defer c.authMu.RUnlock()
+	return c.VCDAuthHeader, c.VCDToken
+}
+
+// authHeader returns the authorization header name and value.
+func (c *Client) authHeader() (header, value string) {
+	header, token := c.authToken()
+	return header, authHeaderValue(header, token)
+}
+
+// authHeaderValue returns the value of the authorization header for token,
+// bearer tokens are sent in the Authorization header with the Bearer scheme.
+func authHeaderValue(header, token string) string {
+	if token != "" && strings.EqualFold(header, bearerAuthHeader) {
+		return "Bearer " + token
+	}
+	return token
+}
+
+// setAuthToken stores the authorization header name and session token.
+func (c *Client) setAuthToken(header, token string) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	c.VCDAuthHeader, c.VCDToken = header, token
+}
+
+// reauthenticatingKey marks the context of the requests sent while logging in
+// again, which must not trigger another login.
+type reauthenticatingKey struct{}
+
+// sessionExpired reports whether req failed with err because its session
+// token is no longer valid and the client is able to log in again.
+func (c *Client) sessionExpired(req *http.Request, err error) bool {
+	if c.reauth == nil || !(IsUnauthorized(err) || IsForbidden(err)) {
+		return false
+	}
+	if req.Context().Value(reauthenticatingKey{}) != nil {
+		return false
+	}
+	header, _ := c.authToken()
+	return header != "" && req.Header.Get(header) != ""
+}
+
+// reauthenticate logs in again unless another request already did since the
+// stale authorization header value was used, and returns the value to use
+// from now on.
+func (c *Client) reauthenticate(ctx context.Context, stale string) (string, error) {
+	c.reauthMu.Lock()
+	defer c.reauthMu.Unlock()
+
+	if _, value := c.authHeader(); value != stale {
+		return value, nil
+	}
+	// Requests sent by the login itself fail rather than log in again, which
+	// would wait on reauthMu forever.
+	if err := c.reauth(context.WithValue(ctx, reauthenticatingKey{}, true)); err != nil {
+		return "", err
+	}
+	_, value := c.authHeader()
+	return value, nil
+}
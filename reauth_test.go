@@ -0,0 +1,75 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"sync"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_Reauthentication(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp", "vm1")
+
+	client, _, vdc := fakeVCDLogin(c, fake)
+
+	// Disabled by default
+	fake.ExpireSession()
+	err := vdc.Refresh()
+	c.Assert(IsUnauthorized(err), Equals, true, Commentf("got %v", err))
+
+	client.EnableReauthentication(StaticCredentials(fake.User, fake.Password, fake.Org))
+	vapp, err := vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+	c.Assert(fake.Logins(), Equals, 2)
+
+	// Concurrent requests share a single login
+	fake.ExpireSession()
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(v Vdc) {
+			defer wg.Done()
+			errs <- v.Refresh()
+		}(vdc)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		c.Assert(err, IsNil)
+	}
+	c.Assert(fake.Logins(), Equals, 3)
+
+	// Non idempotent requests are not replayed, the next ones work
+	fake.ExpireSession()
+	_, err = vapp.PowerOn()
+	c.Assert(IsUnauthorized(err), Equals, true, Commentf("got %v", err))
+	_, err = vapp.PowerOn()
+	c.Assert(err, IsNil)
+	c.Assert(fake.Logins(), Equals, 4)
+
+	// Resumed sessions log in again too
+	resumed := NewVCDClient(fake.Endpoint(), false)
+	c.Assert(resumed.ResumeSession(client.ExportSession()), IsNil)
+	resumed.EnableReauthentication(StaticCredentials(fake.User, fake.Password, fake.Org))
+	fake.ExpireSession()
+	_, err = resumed.RetrieveVDC()
+	c.Assert(err, IsNil)
+	c.Assert(fake.Logins(), Equals, 5)
+
+	// A login refused in turn is reported instead of logging in again
+	client.EnableReauthentication(StaticCredentials(fake.User, "wrong", fake.Org))
+	fake.ExpireSession()
+	err = vdc.Refresh()
+	c.Assert(IsUnauthorized(err), Equals, true, Commentf("got %v", err))
+	c.Assert(err, ErrorMatches, ".*re-authentication failed.*")
+	c.Assert(fake.Logins(), Equals, 5)
+
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy describes how requests failing with a transient API error are
+// tried again. Delays grow exponentially from BaseDelay up to MaxDelay and are
+// randomised by Jitter so that concurrent callers don't retry in lockstep.
+// Status code failures are only retried for idempotent methods: a POST timing
+// out at a gateway may have been accepted, and sending it again would repeat
+// the operation. Busy entity rejections are retried for every method, the
+// server refused the request without applying it.
+type RetryPolicy struct {
+	MaxAttempts          int           // Total number of attempts, 1 or less disables retries
+	BaseDelay            time.Duration // Delay before the first retry
+	MaxDelay             time.Duration // Upper bound for a single delay
+	Jitter               float64       // Fraction of each delay to randomise, between 0 and 1
+	RetryableStatusCodes []int         // HTTP status codes considered transient for idempotent methods
+	RetryBusy            bool          // Retry when the target entity is busy with another operation
+}
+
+// DefaultRetryPolicy is used by clients that don't set their own RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:          10,
+	BaseDelay:            1 * time.Second,
+	MaxDelay:             30 * time.Second,
+	Jitter:               0.2,
+	RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+	RetryBusy:            true,
+}
+
+// NoRetryPolicy disables retries altogether.
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// retryable reports whether req failing with err is worth another attempt
+// under p.
+func (p RetryPolicy) retryable(req *http.Request, err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if p.RetryBusy && apiErr.IsBusy() {
+		return true
+	}
+	if !idempotent(req) {
+		return false
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// idempotent reports whether req can safely be sent twice.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// delay returns how long to wait before the given retry, counting from 1.
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(d))
+	}
+	if d < 0 {
+		d = 0
+	}
+	return d
+}
+
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.RetryPolicy != nil {
+		return *c.RetryPolicy
+	}
+	return DefaultRetryPolicy
+}
+
+// doRequest sends req and checks the response with checkResp, retrying
+// transient failures according to the client RetryPolicy. Requests go through
+// the client rate limiter and a server asking to slow down with Retry-After
+// holds back every request of the client. Request bodies are
+// rewound through req.GetBody, requests whose body can't be rewound are sent
+// only once. When re-authentication is enabled, requests failing on an expired
+// session log in again and idempotent ones are sent again.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+
+	policy := c.retryPolicy()
+	ctx := req.Context()
+	hc := c.httpClient()
+	limiter := c.rateLimiter()
+	reauthenticated := false
+
+	for attempt := 1; ; attempt++ {
+		if err := limiter.wait(ctx, req.Method); err != nil {
+			return nil, fmt.Errorf("error waiting for the rate limiter: %w", err)
+		}
+		release, err := c.acquireSlot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for a request slot: %w", err)
+		}
+		spanCtx, span := c.startRequestSpan(ctx, req)
+		start := time.Now()
+		raw, err := hc.Do(req.WithContext(spanCtx))
+		if raw != nil && raw.Body != nil {
+			raw.Body = &slotBody{ReadCloser: raw.Body, release: release}
+		} else {
+			release()
+		}
+		c.observeRequest(req, raw, time.Since(start))
+		if raw != nil {
+			span.SetAttribute("http.status_code", raw.StatusCode)
+		}
+		resp, err := checkResp(raw, err)
+		endSpan(span, err)
+		if err == nil {
+			return resp, nil
+		}
+
+		retryAfter := serverRetryAfter(err)
+		if retryAfter > 0 {
+			limiter.throttle(retryAfter)
+		}
+
+		if !reauthenticated && c.sessionExpired(req, err) {
+			reauthenticated = true
+			header, _ := c.authHeader()
+			value, rerr := c.reauthenticate(ctx, req.Header.Get(header))
+			if rerr != nil {
+				return resp, fmt.Errorf("%w (re-authentication failed: %s)", err, rerr)
+			}
+			if !idempotent(req) {
+				return resp, err
+			}
+			rewound, ok := rewindRequest(req)
+			if !ok {
+				return resp, err
+			}
+			req = rewound
+			req.Header.Set(header, value)
+			// Sending the request again with the new token isn't a retry.
+			attempt--
+			continue
+		}
+
+		if attempt >= policy.MaxAttempts || !policy.retryable(req, err) {
+			return resp, err
+		}
+
+		rewound, ok := rewindRequest(req)
+		if !ok {
+			return resp, err
+		}
+		req = rewound
+		c.countRetry(req, err)
+
+		// The limiter already waits for Retry-After before the next attempt.
+		delay := policy.delay(attempt)
+		if retryAfter > 0 {
+			delay = 0
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (giving up retrying: %s)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// rewindRequest returns a copy of req that can be sent again, with a fresh
+// body. It returns false if the body can't be rewound.
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return clone, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	clone.Body = body
+	return clone, true
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:          3,
+	BaseDelay:            time.Millisecond,
+	MaxDelay:             5 * time.Millisecond,
+	RetryableStatusCodes: []int{503, 504},
+	RetryBusy:            true,
+}
+
+func (s *S) Test_RetryBusy(c *C) {
+
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	testServer.Response(400, nil, vcdBusyError)
+	testServer.Response(200, nil, vdcExample)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequests(2)
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+}
+
+func (s *S) Test_RetryGivesUp(c *C) {
+
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	testServer.Response(503, nil, "")
+	testServer.Response(504, nil, "")
+	testServer.Response(503, nil, "")
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequests(3)
+	testServer.Flush()
+	c.Assert(err, NotNil)
+	c.Assert(err, ErrorMatches, ".*503.*")
+
+}
+
+func (s *S) Test_RetryNotRetryable(c *C) {
+
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	testServer.Response(500, nil, vcdError)
+	testServer.Response(200, nil, vdcExample)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+}
+
+func (s *S) Test_RetryRewindsBody(c *C) {
+
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	u, _ := url.ParseRequestURI("http://localhost:4444/api/action")
+	req := s.client.Client.NewRequest(map[string]string{}, "PUT", *u, bytes.NewBufferString("payload"))
+
+	testServer.Response(503, nil, "")
+	testServer.Response(200, nil, "")
+	_, err := s.client.Client.doRequest(req)
+	reqs := testServer.WaitRequests(2)
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	for _, r := range reqs {
+		body, _ := ioutil.ReadAll(r.Body)
+		c.Assert(string(body), Equals, "payload")
+	}
+
+}
+
+func (s *S) Test_RetryNotIdempotent(c *C) {
+
+	s.client.Client.RetryPolicy = &testRetryPolicy
+	defer func() { s.client.Client.RetryPolicy = nil }()
+
+	u, _ := url.ParseRequestURI("http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp")
+
+	// The server may have accepted a POST that timed out
+	req := s.client.Client.NewRequest(map[string]string{}, "POST", *u, bytes.NewBufferString("payload"))
+	testServer.Response(504, nil, "")
+	testServer.Response(200, nil, "")
+	_, err := s.client.Client.doRequest(req)
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, ErrorMatches, ".*504.*")
+
+	// A busy entity rejects the request without applying it
+	req = s.client.Client.NewRequest(map[string]string{}, "POST", *u, bytes.NewBufferString("payload"))
+	testServer.Response(400, nil, vcdBusyError)
+	testServer.Response(200, nil, "")
+	_, err = s.client.Client.doRequest(req)
+	_ = testServer.WaitRequests(2)
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+}
+
+func (s *S) Test_RetryDelay(c *C) {
+
+	p := RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	c.Assert(p.delay(1), Equals, time.Second)
+	c.Assert(p.delay(2), Equals, 2*time.Second)
+	c.Assert(p.delay(3), Equals, 4*time.Second)
+	c.Assert(p.delay(4), Equals, 5*time.Second)
+	c.Assert(p.delay(40), Equals, 5*time.Second)
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.delay(1)
+		c.Assert(d >= 500*time.Millisecond && d <= 1500*time.Millisecond, Equals, true)
+	}
+
+}
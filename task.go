@@ -5,6 +5,7 @@
 package govcloudair
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"time"
@@ -25,53 +26,270 @@ func NewTask(c *Client) *Task {
 }
 
 func (t *Task) Refresh() error {
+	return t.RefreshWithContext(context.Background())
+}
+
+func (t *Task) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := t.c.startSpan(ctx, "Task.Refresh")
+	defer func() { endSpan(span, err) }()
 
 	if t.Task == nil {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(t.Task.HREF)
-
-	req := t.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.Task{}
 
-	resp, err := checkResp(t.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retrieving task: %s", err)
+	if err = t.c.getEntity(ctx, t.Task.HREF, "task", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	t.Task = &types.Task{}
-
-	if err = decodeBody(resp, t.Task); err != nil {
-		return fmt.Errorf("error decoding task response: %s", err)
-	}
+	t.Task = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (t *Task) WaitTaskCompletion() error {
+	return t.WaitTaskCompletionWithContext(context.Background())
+}
+
+// TaskWaitOptions controls how WaitTaskCompletionWithOptions polls a task.
+// The delay between two polls starts at PollInterval and is multiplied by
+// Backoff after every poll, up to MaxPollInterval.
+type TaskWaitOptions struct {
+	PollInterval    time.Duration          // Delay before the second poll, 3 seconds if zero
+	MaxPollInterval time.Duration          // Upper bound for the delay, no bound if zero
+	Backoff         float64                // Factor applied to the delay after every poll, 1 or less keeps it constant
+	Timeout         time.Duration          // Overall time to wait for, no limit if zero
+	OnProgress      func(task *types.Task) // Called on the first poll and whenever the status or progress changes
+}
+
+// DefaultTaskWaitOptions is used by WaitTaskCompletion: the task is polled
+// every 3 seconds until it completes.
+var DefaultTaskWaitOptions = TaskWaitOptions{PollInterval: 3 * time.Second}
+
+// delay returns how long to wait after the given poll, counting from 1.
+func (o TaskWaitOptions) delay(poll int) time.Duration {
+	d := o.PollInterval
+	if d <= 0 {
+		d = DefaultTaskWaitOptions.PollInterval
+	}
+	for i := 1; i < poll && o.Backoff > 1 && (o.MaxPollInterval <= 0 || d < o.MaxPollInterval); i++ {
+		d = time.Duration(float64(d) * o.Backoff)
+	}
+	if o.MaxPollInterval > 0 && d > o.MaxPollInterval {
+		d = o.MaxPollInterval
+	}
+	return d
+}
+
+// WaitTaskCompletionWithContext polls the task until it leaves the queued and
+// running states. Polling stops early when ctx is cancelled. Tasks that fail
+// or are aborted return a *TaskError.
+func (t *Task) WaitTaskCompletionWithContext(ctx context.Context) error {
+	return t.WaitTaskCompletionWithOptions(ctx, DefaultTaskWaitOptions)
+}
+
+// WaitTaskCompletionWithOptions polls the task until it leaves the queued and
+// running states, as set by opts. Polling stops early when ctx is cancelled
+// or opts.Timeout has passed.
+func (t *Task) WaitTaskCompletionWithOptions(ctx context.Context, opts TaskWaitOptions) (err error) {
+	ctx, span := t.c.startSpan(ctx, "Task.WaitTaskCompletion")
+	defer func() { endSpan(span, err) }()
 
 	if t.Task == nil {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	for {
-		err := t.Refresh()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	span.SetAttribute("task.href", t.Task.HREF)
+	start := time.Now()
+	status, progress := "", -1
+
+	for polls := 1; ; polls++ {
+		err = t.RefreshWithContext(ctx)
 		if err != nil {
-			return fmt.Errorf("error retreiving task: %s", err)
+			return fmt.Errorf("error retreiving task: %w", err)
+		}
+
+		if opts.OnProgress != nil && (t.Task.Status != status || t.Task.Progress != progress) {
+			status, progress = t.Task.Status, t.Task.Progress
+			opts.OnProgress(t.Task)
 		}
 
 		// If task is not in a waiting status we're done, check if there's an error and return it.
 		if t.Task.Status != "queued" && t.Task.Status != "preRunning" && t.Task.Status != "running" {
-			if t.Task.Status == "error" {
-				return fmt.Errorf("task did not complete succesfully: %s", t.Task.Description)
+			span.SetAttribute("task.operation", t.Task.OperationName)
+			span.SetAttribute("task.status", t.Task.Status)
+			span.SetAttribute("task.polls", polls)
+			t.observe(time.Since(start))
+			if t.Task.Status == "error" || t.Task.Status == "aborted" {
+				return newTaskError(t.Task)
 			}
 			return nil
 		}
 
-		// Sleep for 3 seconds and try again.
-		time.Sleep(3 * time.Second)
+		// Wait and try again, unless the caller gave up.
+		timer := time.NewTimer(opts.delay(polls))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("error waiting for task completion: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *Task) Cancel() error {
+	return t.CancelWithContext(context.Background())
+}
+
+// CancelWithContext asks vCloud Director to cancel the task through its
+// cancel link. The task is aborted asynchronously, WaitTaskCompletion returns
+// once it is. Tasks that already completed, or that can't be cancelled, have
+// no cancel link and return an error.
+func (t *Task) CancelWithContext(ctx context.Context) (err error) {
+	ctx, span := t.c.startSpan(ctx, "Task.Cancel")
+	defer func() { endSpan(span, err) }()
+
+	if t.Task == nil {
+		return fmt.Errorf("cannot cancel, Object is empty")
+	}
+
+	link := t.Task.Link.Find(func(l *types.Link) bool { return l != nil && l.Rel == types.RelTaskCancel })
+	if link == nil {
+		return fmt.Errorf("task %s can't be cancelled, it has no cancel link", t.Task.HREF)
+	}
+
+	u, err := url.ParseRequestURI(link.HREF)
+	if err != nil {
+		return fmt.Errorf("error decoding task cancel link: %w", err)
+	}
+
+	req := t.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *u, nil)
+
+	resp, err := t.c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("error cancelling task: %w", err)
+	}
+	resp.Body.Close()
+
+	// The request was successful
+	return nil
+}
+
+// ResultReference returns a reference to the entity a successful task acted
+// on, e.g. the vApp created by an instantiation or the VM that was powered on.
+// It returns a *TaskError if the task failed, and an error if it is still in
+// flight.
+func (t *Task) ResultReference() (*types.Reference, error) {
+	if err := t.completed(); err != nil {
+		return nil, err
+	}
+	if t.Task.Owner == nil || t.Task.Owner.HREF == "" {
+		return nil, fmt.Errorf("task %s has no owner", t.Task.HREF)
+	}
+	return t.Task.Owner, nil
+}
+
+// Result returns the result of a successful task, for the operations that
+// return one in ResultContent. It returns nil if the task has no result, a
+// *TaskError if the task failed, and an error if it is still in flight.
+func (t *Task) Result() (*types.TaskResult, error) {
+	if err := t.completed(); err != nil {
+		return nil, err
+	}
+	return t.Task.Result, nil
+}
+
+// completed returns nil if the task succeeded, a *TaskError if it failed and
+// an error if it is still in flight.
+func (t *Task) completed() error {
+	if t.Task == nil {
+		return fmt.Errorf("cannot get result, Object is empty")
+	}
+	switch t.Task.Status {
+	case "success":
+		return nil
+	case "error", "aborted":
+		return newTaskError(t.Task)
+	default:
+		return fmt.Errorf("task %s has not completed, its status is %s", t.Task.HREF, t.Task.Status)
+	}
+}
+
+func (t *Task) ResultVApp() (VApp, error) {
+	return t.ResultVAppWithContext(context.Background())
+}
+
+// ResultVAppWithContext fetches the vApp a successful task acted on, see
+// ResultReference.
+func (t *Task) ResultVAppWithContext(ctx context.Context) (_ VApp, err error) {
+	ctx, span := t.c.startSpan(ctx, "Task.ResultVApp")
+	defer func() { endSpan(span, err) }()
+
+	ref, err := t.ResultReference()
+	if err != nil {
+		return VApp{}, err
+	}
+	if ref.Type != types.MimeVApp {
+		return VApp{}, fmt.Errorf("task %s acted on a %s, not a vApp", t.Task.HREF, ref.Type)
+	}
+
+	vapp := NewVApp(t.c)
+	vapp.VApp.HREF = ref.HREF
+	if err = vapp.RefreshWithContext(ctx); err != nil {
+		return VApp{}, fmt.Errorf("error retrieving vApp: %w", err)
+	}
+
+	return *vapp, nil
+}
+
+func (t *Task) ResultVM() (VM, error) {
+	return t.ResultVMWithContext(context.Background())
+}
+
+// ResultVMWithContext fetches the VM a successful task acted on, see
+// ResultReference.
+func (t *Task) ResultVMWithContext(ctx context.Context) (_ VM, err error) {
+	ctx, span := t.c.startSpan(ctx, "Task.ResultVM")
+	defer func() { endSpan(span, err) }()
+
+	ref, err := t.ResultReference()
+	if err != nil {
+		return VM{}, err
+	}
+	if ref.Type != types.MimeVM {
+		return VM{}, fmt.Errorf("task %s acted on a %s, not a VM", t.Task.HREF, ref.Type)
+	}
+
+	vm := NewVM(t.c)
+	vm.VM.HREF = ref.HREF
+	if err = vm.RefreshWithContext(ctx); err != nil {
+		return VM{}, fmt.Errorf("error retrieving VM: %w", err)
+	}
+
+	return *vm, nil
+}
+
+// observe reports the finished task to the client metrics. The run time
+// reported by the server is preferred over waited, the time spent polling.
+func (t *Task) observe(waited time.Duration) {
+	if t.c.Metrics == nil {
+		return
+	}
+	duration := waited
+	start, serr := time.Parse(time.RFC3339, t.Task.StartTime)
+	end, eerr := time.Parse(time.RFC3339, t.Task.EndTime)
+	if serr == nil && eerr == nil && !end.Before(start) {
+		duration = end.Sub(start)
 	}
+	t.c.Metrics.ObserveTask(t.Task.OperationName, t.Task.Status, duration)
 }
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"errors"
+	"fmt"
+
+	types "github.com/stasian/govcloudair/types/v56"
+)
+
+// TaskError is returned when a task completes with the error status or is
+// aborted. The vCloud error nested in the task is copied over, when the task
+// has one, together with the entity the task acted on. Callers can get hold of
+// it with errors.As.
+type TaskError struct {
+	HREF                    string           // HREF of the task
+	Operation               string           // Operation name of the task, e.g. vappDeploy
+	Status                  string           // Final status of the task, error or aborted
+	Description             string           // Description of the task
+	Owner                   *types.Reference // Entity the task acted on
+	Message                 string           // Error message
+	MajorErrorCode          int              // vCloud major error code
+	MinorErrorCode          string           // vCloud minor error code
+	VendorSpecificErrorCode string           // Vendor specific error code
+	StackTrace              string           // Server side stack trace, if any
+}
+
+func (e *TaskError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Description
+	}
+	if e.Status == "aborted" {
+		msg = "task was aborted"
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+	}
+	if e.MajorErrorCode != 0 {
+		msg = fmt.Sprintf("%d: %s", e.MajorErrorCode, msg)
+	}
+	if e.Owner != nil && e.Owner.Name != "" {
+		msg += fmt.Sprintf(" [%s %s]", e.Operation, e.Owner.Name)
+	}
+	return "task did not complete succesfully: " + msg
+}
+
+// IsTaskError reports whether err, or any error it wraps, is a TaskError.
+func IsTaskError(err error) bool {
+	var taskErr *TaskError
+	return errors.As(err, &taskErr)
+}
+
+// newTaskError builds a TaskError out of a task that failed.
+func newTaskError(task *types.Task) *TaskError {
+	taskErr := &TaskError{
+		HREF:        task.HREF,
+		Operation:   task.OperationName,
+		Status:      task.Status,
+		Description: task.Description,
+		Owner:       task.Owner,
+	}
+	if task.Error != nil {
+		taskErr.Message = task.Error.Message
+		taskErr.MajorErrorCode = task.Error.MajorErrorCode
+		taskErr.MinorErrorCode = task.Error.MinorErrorCode
+		taskErr.VendorSpecificErrorCode = task.Error.VendorSpecificErrorCode
+		taskErr.StackTrace = task.Error.StackTrace
+	}
+	return taskErr
+}
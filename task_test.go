@@ -5,6 +5,13 @@
 package govcloudair
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	types "github.com/stasian/govcloudair/types/v56"
+	"github.com/ukcloud/govcloudair/testutil"
 	. "gopkg.in/check.v1"
 )
 
@@ -24,6 +31,204 @@ func (s *S) Test_WaitTaskCompletion(c *C) {
 
 }
 
+func (s *S) Test_WaitTaskCompletionWithContext(c *C) {
+
+	testServer.Response(200, nil, taskExample)
+	task, err := s.vapp.Deploy()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	// The task never leaves the running state, the deadline must stop polling
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	testServer.Response(200, nil, taskRunningExample)
+	err = task.WaitTaskCompletionWithContext(ctx)
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+}
+
+func (s *S) Test_WaitTaskCompletionWithOptions(c *C) {
+
+	task := NewTask(&s.client.Client)
+	task.Task.HREF = "http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05"
+
+	var seen []string
+	opts := TaskWaitOptions{
+		PollInterval:    time.Millisecond,
+		MaxPollInterval: 4 * time.Millisecond,
+		Backoff:         2,
+		OnProgress: func(task *types.Task) {
+			seen = append(seen, fmt.Sprintf("%s %d", task.Status, task.Progress))
+		},
+	}
+
+	// The callback only fires when the status or progress changes
+	testServer.Response(200, nil, taskRunningExample)
+	testServer.Response(200, nil, taskRunningExample)
+	testServer.Response(200, nil, taskExample)
+	err := task.WaitTaskCompletionWithOptions(context.Background(), opts)
+	_ = testServer.WaitRequests(3)
+	testServer.Flush()
+	c.Assert(err, IsNil)
+	c.Assert(seen, DeepEquals, []string{"running 40", "success 100"})
+
+	// The timeout stops polling a task that never completes
+	opts = TaskWaitOptions{PollInterval: 20 * time.Millisecond, Timeout: 50 * time.Millisecond}
+	testServer.Responses(10, 200, nil, taskRunningExample)
+	err = task.WaitTaskCompletionWithOptions(context.Background(), opts)
+	testServer.Flush()
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+
+}
+
+func (s *S) Test_TaskWaitOptionsDelay(c *C) {
+
+	opts := TaskWaitOptions{PollInterval: time.Second, MaxPollInterval: 5 * time.Second, Backoff: 2}
+	c.Assert(opts.delay(1), Equals, time.Second)
+	c.Assert(opts.delay(2), Equals, 2*time.Second)
+	c.Assert(opts.delay(3), Equals, 4*time.Second)
+	c.Assert(opts.delay(4), Equals, 5*time.Second)
+	c.Assert(TaskWaitOptions{}.delay(10), Equals, 3*time.Second)
+
+}
+
+func (s *S) Test_TaskResult(c *C) {
+
+	task := NewTask(&s.client.Client)
+	task.Task.HREF = "http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05"
+
+	testServer.Response(200, nil, taskRunningExample)
+	err := task.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	_, err = task.Result()
+	c.Assert(err, ErrorMatches, "task .* has not completed, its status is running")
+
+	testServer.Response(200, nil, taskResultExample)
+	err = task.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	result, err := task.Result()
+	c.Assert(err, IsNil)
+	c.Assert(result, NotNil)
+	c.Assert(result.ResultContent, Equals, "urn:vcloud:vm:fdb86157-2e1f-4889-9942-0463836d10e1 is compliant")
+
+	ref, err := task.ResultReference()
+	c.Assert(err, IsNil)
+	c.Assert(ref.Type, Equals, types.MimeVM)
+
+}
+
+func (s *S) Test_CancelAndListTasks(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp1", "vm1")
+	fake.AddVApp("vapp2", "vm2")
+
+	_, org, vdc := fakeVCDLogin(c, fake)
+	vapp1, err := vdc.FindVAppByName("vapp1")
+	c.Assert(err, IsNil)
+	vapp2, err := vdc.FindVAppByName("vapp2")
+	c.Assert(err, IsNil)
+
+	// A failed task, then two tasks in flight
+	fake.FailNextTask("INTERNAL_SERVER_ERROR", "Unable to deploy the vApp.")
+	failed, err := vapp1.Deploy()
+	c.Assert(err, IsNil)
+	c.Assert(failed.WaitTaskCompletion(), NotNil)
+	fake.TaskDuration = time.Minute
+	running, err := vapp1.PowerOn()
+	c.Assert(err, IsNil)
+	_, err = vapp2.PowerOn()
+	c.Assert(err, IsNil)
+
+	tasks, err := org.ListTasks(TaskFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(tasks, HasLen, 3)
+
+	tasks, err = org.ListTasks(TaskFilter{Status: []string{"error"}})
+	c.Assert(err, IsNil)
+	c.Assert(tasks, HasLen, 1)
+	c.Assert(tasks[0].Task.Error, NotNil)
+	c.Assert(tasks[0].Task.Error.Message, Equals, "Unable to deploy the vApp.")
+
+	tasks, err = org.ListTasks(TaskFilter{Status: []string{"queued", "running"}, OwnerHREF: vapp1.VApp.HREF})
+	c.Assert(err, IsNil)
+	c.Assert(tasks, HasLen, 1)
+	c.Assert(tasks[0].Task.HREF, Equals, running.Task.HREF)
+	c.Assert(tasks[0].Task.OperationName, Equals, "powerOn")
+	c.Assert(tasks[0].Task.StartTime, Not(Equals), "")
+
+	// Cancelling aborts the task without applying its changes
+	c.Assert(tasks[0].Cancel(), IsNil)
+	c.Assert(running.Refresh(), IsNil)
+	c.Assert(running.Task.Status, Equals, "aborted")
+	status, err := vapp1.GetStatus()
+	c.Assert(err, IsNil)
+	c.Assert(status, Equals, "POWERED_OFF")
+	c.Assert(running.Cancel(), NotNil)
+
+}
+
+func (s *S) Test_TaskResults(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp", "vm1")
+
+	_, _, vdc := fakeVCDLogin(c, fake)
+	vapp, err := vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+
+	// A failed task carries the vCloud error and the entity it acted on
+	fake.FailNextTask("INTERNAL_SERVER_ERROR", "Unable to deploy the vApp.")
+	task, err := vapp.Deploy()
+	c.Assert(err, IsNil)
+	err = task.WaitTaskCompletion()
+	var taskErr *TaskError
+	c.Assert(errors.As(err, &taskErr), Equals, true, Commentf("got %v", err))
+	c.Assert(taskErr.MinorErrorCode, Equals, "INTERNAL_SERVER_ERROR")
+	c.Assert(taskErr.Message, Equals, "Unable to deploy the vApp.")
+	c.Assert(taskErr.Operation, Equals, "deploy")
+	c.Assert(taskErr.Owner, NotNil)
+	c.Assert(taskErr.Owner.HREF, Equals, vapp.VApp.HREF)
+	_, err = task.ResultReference()
+	c.Assert(IsTaskError(err), Equals, true, Commentf("got %v", err))
+
+	// A successful task leads to the entity it acted on
+	task, err = vapp.PowerOn()
+	c.Assert(err, IsNil)
+	_, err = task.ResultVApp()
+	c.Assert(err, NotNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	result, err := task.ResultVApp()
+	c.Assert(err, IsNil)
+	c.Assert(result.VApp.Name, Equals, "vapp")
+	c.Assert(result.VApp.Status, Equals, 4)
+	_, err = task.ResultVM()
+	c.Assert(err, NotNil)
+
+	vm, err := vdc.FindVMByName(result, "vm1")
+	c.Assert(err, IsNil)
+	task, err = vm.PowerOff()
+	c.Assert(err, IsNil)
+	c.Assert(task.WaitTaskCompletion(), IsNil)
+	resultVM, err := task.ResultVM()
+	c.Assert(err, IsNil)
+	c.Assert(resultVM.VM.HREF, Equals, vm.VM.HREF)
+	c.Assert(resultVM.VM.Status, Equals, 8)
+
+}
+
 var taskExample = `
 <Task cancelRequested="false" endTime="2014-11-10T09:09:31.483Z" expiryTime="2015-02-08T09:09:16.627Z" href="http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05" id="urn:vcloud:task:1b8f926c-eff5-4bea-9b13-4e49bdd50c05" name="task" operation="Composed Virtual Application Test API GO4(fdb86157-2e1f-4889-9942-0463836d10e1)" operationName="vdcComposeVapp" serviceNamespace="com.vmware.vcloud" startTime="2014-11-10T09:09:16.627Z" status="success" type="application/vnd.vmware.vcloud.task+xml" xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.vmware.com/vcloud/v1.5 http://10.6.32.3/api/v1.5/schema/master.xsd">
   <Owner href="http://localhost:4444/api/vApp/vapp-fdb86157-2e1f-4889-9942-0463836d10e1" name="Test API GO4" type="application/vnd.vmware.vcloud.vApp+xml"/>
@@ -33,3 +238,26 @@ var taskExample = `
   <Details/>
 </Task>
 	`
+
+var taskRunningExample = `
+<Task cancelRequested="false" expiryTime="2015-02-08T09:09:16.627Z" href="http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05" id="urn:vcloud:task:1b8f926c-eff5-4bea-9b13-4e49bdd50c05" name="task" operation="Deploying Virtual Application Test API GO4(fdb86157-2e1f-4889-9942-0463836d10e1)" operationName="vappDeploy" serviceNamespace="com.vmware.vcloud" startTime="2014-11-10T09:09:16.627Z" status="running" type="application/vnd.vmware.vcloud.task+xml" xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.vmware.com/vcloud/v1.5 http://10.6.32.3/api/v1.5/schema/master.xsd">
+  <Owner href="http://localhost:4444/api/vApp/vapp-fdb86157-2e1f-4889-9942-0463836d10e1" name="Test API GO4" type="application/vnd.vmware.vcloud.vApp+xml"/>
+  <User href="http://localhost:4444/api/admin/user/d8ac278a-5b49-4c85-9a81-468838e89eb9" name="frapposelli1@gts-vchs.com" type="application/vnd.vmware.admin.user+xml"/>
+  <Organization href="http://localhost:4444/api/org/23bd2339-c55f-403c-baf3-13109e8c8d57" name="M916272752-5793" type="application/vnd.vmware.vcloud.org+xml"/>
+  <Progress>40</Progress>
+  <Details/>
+</Task>
+	`
+
+var taskResultExample = `
+<Task cancelRequested="false" endTime="2014-11-10T09:09:31.483Z" expiryTime="2015-02-08T09:09:16.627Z" href="http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05" id="urn:vcloud:task:1b8f926c-eff5-4bea-9b13-4e49bdd50c05" name="task" operation="Checked compliance of Virtual Machine web01(fdb86157-2e1f-4889-9942-0463836d10e1)" operationName="vappCheckCompliance" serviceNamespace="com.vmware.vcloud" startTime="2014-11-10T09:09:16.627Z" status="success" type="application/vnd.vmware.vcloud.task+xml" xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.vmware.com/vcloud/v1.5 http://10.6.32.3/api/v1.5/schema/master.xsd">
+  <Owner href="http://localhost:4444/api/vApp/vm-fdb86157-2e1f-4889-9942-0463836d10e1" name="web01" type="application/vnd.vmware.vcloud.vm+xml"/>
+  <User href="http://localhost:4444/api/admin/user/d8ac278a-5b49-4c85-9a81-468838e89eb9" name="frapposelli1@gts-vchs.com" type="application/vnd.vmware.admin.user+xml"/>
+  <Organization href="http://localhost:4444/api/org/23bd2339-c55f-403c-baf3-13109e8c8d57" name="M916272752-5793" type="application/vnd.vmware.vcloud.org+xml"/>
+  <Progress>100</Progress>
+  <Details/>
+  <Result>
+    <ResultContent>urn:vcloud:vm:fdb86157-2e1f-4889-9942-0463836d10e1 is compliant</ResultContent>
+  </Result>
+</Task>
+	`
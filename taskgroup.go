@@ -0,0 +1,143 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	types "github.com/stasian/govcloudair/types/v56"
+)
+
+// TaskGroup waits on many tasks at once. Every task is polled by its own
+// goroutine, so waiting on fifty tasks takes about as long as waiting on the
+// slowest one. The requests still go through the limits of the client, see
+// SetMaxConcurrentRequests and SetRateLimit.
+type TaskGroup struct {
+	Tasks    []*Task
+	Options  TaskWaitOptions // How each task is polled, DefaultTaskWaitOptions if zero
+	FailFast bool            // Stop waiting on the other tasks as soon as one fails
+}
+
+// TaskResult is the outcome of a single task of a TaskGroup.
+type TaskResult struct {
+	Index int   // Position of the task in TaskGroup.Tasks
+	Task  *Task // The task, as last polled
+	Err   error // Why the task failed, or why waiting on it stopped
+}
+
+// NewTaskGroup returns a TaskGroup waiting on the given tasks.
+func NewTaskGroup(tasks ...Task) *TaskGroup {
+	g := &TaskGroup{}
+	g.Add(tasks...)
+	return g
+}
+
+// Add adds tasks to the group.
+func (g *TaskGroup) Add(tasks ...Task) {
+	for i := range tasks {
+		task := tasks[i]
+		g.Tasks = append(g.Tasks, &task)
+	}
+}
+
+// addTasks adds the tasks embedded in an entity to the group.
+func (g *TaskGroup) addTasks(c *Client, tasks *types.TasksInProgress) {
+	if tasks == nil {
+		return
+	}
+	for _, t := range tasks.Task {
+		g.Tasks = append(g.Tasks, &Task{Task: t, c: c})
+	}
+}
+
+// WaitAll waits for every task of the group to complete and returns their
+// results, in the order of Tasks. The error joins the errors of the tasks that
+// failed. With FailFast, waiting stops at the first failure: the error is
+// that failure and the tasks still running are reported with the
+// cancellation error.
+func (g *TaskGroup) WaitAll(ctx context.Context) (results []TaskResult, err error) {
+	ctx, span := g.client().startSpan(ctx, "TaskGroup.WaitAll")
+	defer func() { endSpan(span, err) }()
+	span.SetAttribute("task.count", len(g.Tasks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results = make([]TaskResult, len(g.Tasks))
+	var errs []error
+	for result := range g.start(ctx) {
+		results[result.Index] = result
+		if result.Err == nil {
+			continue
+		}
+		if g.FailFast {
+			if len(errs) == 0 {
+				errs = append(errs, result.Err)
+				cancel()
+			}
+			continue
+		}
+		errs = append(errs, result.Err)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// WaitAny waits for the first task of the group to complete, successfully or
+// not, and stops waiting on the other ones. The error is the one of the task
+// that completed, or the context error if none did.
+func (g *TaskGroup) WaitAny(ctx context.Context) (result TaskResult, err error) {
+	ctx, span := g.client().startSpan(ctx, "TaskGroup.WaitAny")
+	defer func() { endSpan(span, err) }()
+	span.SetAttribute("task.count", len(g.Tasks))
+
+	if len(g.Tasks) == 0 {
+		return TaskResult{Index: -1}, fmt.Errorf("no task to wait on")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := g.start(ctx)
+	result = <-results
+	cancel()
+	// Let the other pollers finish before returning.
+	for range results {
+	}
+
+	return result, result.Err
+}
+
+// start polls every task of the group in its own goroutine. The returned
+// channel yields the result of each task and is closed once all of them are
+// known.
+func (g *TaskGroup) start(ctx context.Context) <-chan TaskResult {
+	results := make(chan TaskResult, len(g.Tasks))
+	var wg sync.WaitGroup
+	for i, task := range g.Tasks {
+		wg.Add(1)
+		go func(i int, task *Task) {
+			defer wg.Done()
+			err := task.WaitTaskCompletionWithOptions(ctx, g.Options)
+			results <- TaskResult{Index: i, Task: task, Err: err}
+		}(i, task)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// client returns the client of the first task, used to trace the group.
+func (g *TaskGroup) client() *Client {
+	if len(g.Tasks) == 0 {
+		return nil
+	}
+	return g.Tasks[0].c
+}
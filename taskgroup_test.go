@@ -0,0 +1,83 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_TaskGroup(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	names := []string{"vapp1", "vapp2", "vapp3", "vapp4", "vapp5"}
+	for _, name := range names {
+		fake.AddVApp(name, name+"-vm")
+	}
+
+	client, _, vdc := fakeVCDLogin(c, fake)
+	fake.TaskDuration = 200 * time.Millisecond
+
+	powerOn := func() *TaskGroup {
+		group := &TaskGroup{Options: TaskWaitOptions{PollInterval: 20 * time.Millisecond}}
+		for _, name := range names {
+			vapp, err := vdc.FindVAppByName(name)
+			c.Assert(err, IsNil)
+			task, err := vapp.PowerOn()
+			c.Assert(err, IsNil)
+			group.Add(task)
+		}
+		return group
+	}
+
+	// The tasks are polled concurrently
+	start := time.Now()
+	results, err := powerOn().WaitAll(context.Background())
+	c.Assert(err, IsNil)
+	elapsed := time.Since(start)
+	c.Assert(elapsed < 2*fake.TaskDuration+100*time.Millisecond, Equals, true, Commentf("took %s", elapsed))
+	for i, result := range results {
+		c.Assert(result.Index, Equals, i)
+		c.Assert(result.Err, IsNil)
+		c.Assert(result.Task.Task.Status, Equals, "success")
+	}
+
+	// WaitAny returns as soon as one task completes
+	result, err := powerOn().WaitAny(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(result.Task.Task.Status, Equals, "success")
+	time.Sleep(fake.TaskDuration)
+
+	// A failing task stops the wait with FailFast, and is collected otherwise
+	missing := NewTask(&client.Client)
+	missing.Task.HREF = fake.URL + "/api/task/missing"
+	for _, failFast := range []bool{true, false} {
+		group := powerOn()
+		group.FailFast = failFast
+		group.Add(*missing)
+		results, err = group.WaitAll(context.Background())
+		c.Assert(IsForbidden(err), Equals, true, Commentf("got %v", err))
+		cancelled := 0
+		for _, result := range results[:len(names)] {
+			if errors.Is(result.Err, context.Canceled) {
+				cancelled++
+			} else {
+				c.Assert(result.Err, IsNil)
+			}
+		}
+		if failFast {
+			c.Assert(cancelled, Equals, len(names))
+		} else {
+			c.Assert(cancelled, Equals, 0)
+		}
+		time.Sleep(fake.TaskDuration)
+	}
+
+}
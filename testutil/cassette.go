@@ -0,0 +1,357 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Mode tells a Recorder whether to capture real traffic or to serve a
+// cassette.
+type Mode int
+
+const (
+	// ModeReplay serves the interactions stored in the cassette and never
+	// touches the network.
+	ModeReplay Mode = iota
+	// ModeRecord forwards requests to the real transport and stores the
+	// sanitized interactions in the cassette.
+	ModeRecord
+)
+
+// ModeFromEnv returns ModeRecord when GOVCLOUDAIR_RECORD is set to true and
+// ModeReplay otherwise.
+func ModeFromEnv() Mode {
+	if os.Getenv("GOVCLOUDAIR_RECORD") == "true" {
+		return ModeRecord
+	}
+	return ModeReplay
+}
+
+// RecordedRequest is the sanitized request half of an Interaction.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the sanitized response half of an Interaction.
+type RecordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Interaction is a request/response pair stored in a Cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette is an ordered list of interactions, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette from path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette: %w", err)
+	}
+	cassette := new(Cassette)
+	if err = json.Unmarshal(data, cassette); err != nil {
+		return nil, fmt.Errorf("error decoding cassette %s: %w", path, err)
+	}
+	return cassette, nil
+}
+
+// Save writes the cassette to path.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding cassette: %w", err)
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Redacted replaces every scrubbed value in a cassette.
+const Redacted = "REDACTED"
+
+// SanitizedHost replaces the host of every recorded URL.
+const SanitizedHost = "vcloud.example.com"
+
+// SensitiveHeaders are the headers whose values are never stored in a
+// cassette. Their values are also scrubbed from every other field, so a
+// session token echoed in a body doesn't leak either.
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"Vchs-Authorization",
+	"X-Vchs-Authorization",
+	"X-Vcloud-Authorization",
+	"X-Vmware-Vcloud-Access-Token",
+}
+
+// Recorder is a RoundTripper recording interactions into a cassette or
+// replaying them. Use Wrap to plug it into a client middleware chain, or set
+// it as the transport of an http.Client directly.
+type Recorder struct {
+	Mode      Mode
+	Path      string
+	Transport http.RoundTripper // Real transport used in ModeRecord, http.DefaultTransport if nil
+	Secrets   []string          // Extra values to scrub, e.g. usernames and passwords
+
+	mu       sync.Mutex
+	cassette *Cassette
+	hosts    []string
+	used     []bool
+}
+
+// NewRecorder returns a Recorder for the cassette at path. In ModeReplay the
+// cassette is loaded straight away.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{Mode: mode, Path: path, cassette: new(Cassette)}
+	if mode == ModeReplay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Wrap returns a RoundTripper recording through next, or replaying the
+// cassette. It has the shape of a client middleware.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return recorderTransport{r, next}
+}
+
+// RoundTrip records or replays req using r.Transport.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	return r.Wrap(r.Transport).RoundTrip(req)
+}
+
+// Stop saves the sanitized cassette when recording, it is a no-op when
+// replaying.
+func (r *Recorder) Stop() error {
+	if r.Mode != ModeRecord {
+		return nil
+	}
+	return r.Cassette().Save(r.Path)
+}
+
+// Cassette returns the interactions loaded so far, or a sanitized copy of the
+// interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.Mode != ModeRecord {
+		return r.cassette
+	}
+	sanitized := &Cassette{Interactions: make([]Interaction, len(r.cassette.Interactions))}
+	for i, in := range r.cassette.Interactions {
+		sanitized.Interactions[i] = Interaction{
+			Request: RecordedRequest{
+				Method:  in.Request.Method,
+				URL:     r.scrub(in.Request.URL),
+				Headers: r.scrubHeaders(in.Request.Headers),
+				Body:    r.scrub(in.Request.Body),
+			},
+			Response: RecordedResponse{
+				Status:  in.Response.Status,
+				Headers: r.scrubHeaders(in.Response.Headers),
+				Body:    r.scrub(in.Response.Body),
+			},
+		}
+	}
+	return sanitized
+}
+
+type recorderTransport struct {
+	r    *Recorder
+	next http.RoundTripper
+}
+
+func (t recorderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.r.Mode == ModeRecord {
+		return t.r.record(req, t.next)
+	}
+	return t.r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		reqBody = data
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.learn(req.URL.Host, req.Header, resp.Header)
+	if _, password, ok := req.BasicAuth(); ok && password != "" && !contains(r.Secrets, password) {
+		r.Secrets = append(r.Secrets, password)
+	}
+
+	// Interactions are kept raw and scrubbed when saving, so that secrets
+	// learned later in the session are removed from earlier ones too.
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: req.Header.Clone(),
+			Body:    string(reqBody),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: resp.Header.Clone(),
+			Body:    string(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching the method, path and
+// query of req. The host is ignored as it was sanitized when recording.
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	want := requestKey(req.Method, req.URL.RequestURI())
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] {
+			continue
+		}
+		if requestKey(in.Request.Method, requestURI(in.Request.URL)) != want {
+			continue
+		}
+		r.used[i] = true
+		header := in.Response.Headers.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s has no interaction left for %s", r.Path, want)
+}
+
+// learn remembers the host and the credentials seen in an interaction so
+// that they are scrubbed everywhere.
+func (r *Recorder) learn(host string, headers ...http.Header) {
+	if host != "" && !contains(r.hosts, host) {
+		r.hosts = append(r.hosts, host)
+	}
+	for _, h := range headers {
+		for _, name := range SensitiveHeaders {
+			for _, v := range h.Values(name) {
+				// Keep the scheme of Authorization headers.
+				if i := strings.Index(v, " "); name == "Authorization" && i > 0 {
+					v = v[i+1:]
+				}
+				if v != "" && !contains(r.Secrets, v) {
+					r.Secrets = append(r.Secrets, v)
+				}
+			}
+		}
+	}
+}
+
+func (r *Recorder) scrub(s string) string {
+	for _, host := range r.hosts {
+		s = strings.Replace(s, host, SanitizedHost, -1)
+	}
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			s = strings.Replace(s, secret, Redacted, -1)
+		}
+	}
+	return s
+}
+
+func (r *Recorder) scrubHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	scrubbed := make(http.Header, len(h))
+	for k, values := range h {
+		for _, v := range values {
+			scrubbed[k] = append(scrubbed[k], r.scrub(v))
+		}
+	}
+	return scrubbed
+}
+
+func requestKey(method, uri string) string {
+	return method + " " + uri
+}
+
+func requestURI(rawurl string) string {
+	i := strings.Index(rawurl, "://")
+	if i < 0 {
+		return rawurl
+	}
+	rest := rawurl[i+3:]
+	if j := strings.Index(rest, "/"); j >= 0 {
+		return rest[j:]
+	}
+	return "/"
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/sessions":
+			w.Header().Set("x-vcloud-authorization", "session-token")
+			w.Write([]byte(`<Session href="http://` + req.Host + `/api/session"/>`))
+		default:
+			if req.Header.Get("x-vcloud-authorization") != "session-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`<Org href="http://` + req.Host + req.URL.Path + `"/>`))
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	// Record a login followed by an authenticated request.
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: recorder}
+
+	req, _ := http.NewRequest("POST", server.URL+"/api/sessions", nil)
+	req.SetBasicAuth("user@org", "s3cr3t-passw0rd")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := resp.Header.Get("x-vcloud-authorization")
+	resp.Body.Close()
+
+	req, _ = http.NewRequest("GET", server.URL+"/api/org/1", nil)
+	req.Header.Set("x-vcloud-authorization", token)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err = recorder.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, leak := range []string{"session-token", "s3cr3t-passw0rd", host} {
+		if strings.Contains(string(data), leak) {
+			t.Errorf("cassette contains %q:\n%s", leak, data)
+		}
+	}
+
+	// Replay without any server, the host is irrelevant.
+	server.Close()
+	replayer, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = &http.Client{Transport: replayer}
+
+	resp, err = client.Post("http://"+SanitizedHost+"/api/sessions", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("x-vcloud-authorization") != Redacted {
+		t.Errorf("expected redacted token, got %q", resp.Header.Get("x-vcloud-authorization"))
+	}
+
+	resp, err = client.Get("http://" + SanitizedHost + "/api/org/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if want := strings.Replace(string(recorded), host, SanitizedHost, -1); string(replayed) != want {
+		t.Errorf("expected body %q, got %q", want, replayed)
+	}
+
+	// Every interaction is served only once.
+	if _, err = client.Get("http://" + SanitizedHost + "/api/org/1"); err == nil {
+		t.Error("expected an error once the cassette is exhausted")
+	}
+}
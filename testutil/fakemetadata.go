@@ -0,0 +1,165 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// SetMetadata sets a string metadata key on the entity at href, in the
+// GENERAL domain.
+func (f *FakeVCD) SetMetadata(href, key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.setMetadata(href, &types.MetadataEntry{
+		Key:        key,
+		TypedValue: &types.TypedValue{XsiType: types.MetadataStringValue, Value: value},
+	})
+}
+
+// metadataDomain returns the domain of a metadata entry.
+func metadataDomain(entry *types.MetadataEntry) string {
+	if entry.Domain == nil || entry.Domain.Domain == "" {
+		return types.MetadataDomainGeneral
+	}
+	return entry.Domain.Domain
+}
+
+func (f *FakeVCD) setMetadata(href string, entry *types.MetadataEntry) {
+	f.deleteMetadata(href, metadataDomain(entry), entry.Key)
+	f.metadata[href] = append(f.metadata[href], entry)
+}
+
+func (f *FakeVCD) deleteMetadata(href, domain, key string) bool {
+	entries := f.metadata[href]
+	for i, entry := range entries {
+		if entry.Key == key && metadataDomain(entry) == domain {
+			f.metadata[href] = append(entries[:i:i], entries[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// metadataFields returns the metadata of the entity at href as query fields,
+// metadata:key for the GENERAL domain and metadata@SYSTEM:key for the SYSTEM
+// one.
+func (f *FakeVCD) metadataFields(href string, fields map[string]string) {
+	for _, entry := range f.metadata[href] {
+		fields[metadataField(entry)] = entry.TypedValue.Value
+	}
+}
+
+func metadataField(entry *types.MetadataEntry) string {
+	if metadataDomain(entry) == types.MetadataDomainSystem {
+		return "metadata@SYSTEM:" + entry.Key
+	}
+	return "metadata:" + entry.Key
+}
+
+// recordMetadata returns the metadata of the entity at href asked for in the
+// fields parameter of a query, nil if there's none.
+func (f *FakeVCD) recordMetadata(href string, fields []string) *types.Metadata {
+	var metadata *types.Metadata
+	for _, entry := range f.metadata[href] {
+		for _, field := range fields {
+			if field == metadataField(entry) {
+				if metadata == nil {
+					metadata = &types.Metadata{}
+				}
+				metadata.MetadataEntry = append(metadata.MetadataEntry, entry)
+			}
+		}
+	}
+	return metadata
+}
+
+// serveMetadata answers the requests on the metadata of the entity at href,
+// parts being the path elements following metadata.
+func (f *FakeVCD) serveMetadata(w http.ResponseWriter, req *http.Request, href string, parts []string) {
+	domain := types.MetadataDomainGeneral
+	if len(parts) == 2 && parts[0] == types.MetadataDomainSystem {
+		domain, parts = types.MetadataDomainSystem, parts[1:]
+	}
+	owner := &types.Reference{HREF: href}
+
+	switch {
+	case len(parts) == 0 && req.Method == "GET":
+		f.writeXML(w, http.StatusOK, "Metadata", &types.Metadata{
+			HREF:          href + "/metadata",
+			Type:          types.MimeMetadata,
+			MetadataEntry: f.metadata[href],
+		})
+
+	case len(parts) == 0 && req.Method == "POST":
+		metadata := new(types.Metadata)
+		if !f.readXML(w, req, metadata) {
+			return
+		}
+		f.writeXML(w, http.StatusAccepted, "Task", f.newTask("metadataUpdate", owner, func() {
+			for _, entry := range metadata.MetadataEntry {
+				f.setMetadata(href, entry)
+			}
+		}))
+
+	case len(parts) == 1 && req.Method == "GET":
+		for _, entry := range f.metadata[href] {
+			if entry.Key == parts[0] && metadataDomain(entry) == domain {
+				f.writeXML(w, http.StatusOK, "MetadataValue", &types.MetadataValue{
+					Xsi:        types.NsXMLSchema,
+					Domain:     entry.Domain,
+					TypedValue: entry.TypedValue,
+				})
+				return
+			}
+		}
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No metadata "+parts[0]+" on "+href+".")
+
+	case len(parts) == 1 && req.Method == "PUT":
+		value := new(types.MetadataValue)
+		if !f.readXML(w, req, value) {
+			return
+		}
+		entry := &types.MetadataEntry{Domain: value.Domain, Key: parts[0], TypedValue: value.TypedValue}
+		if metadataDomain(entry) != domain {
+			f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "The domain of the value doesn't match the URL.")
+			return
+		}
+		f.writeXML(w, http.StatusAccepted, "Task", f.newTask("metadataUpdate", owner, func() {
+			f.setMetadata(href, entry)
+		}))
+
+	case len(parts) == 1 && req.Method == "DELETE":
+		key := parts[0]
+		f.writeXML(w, http.StatusAccepted, "Task", f.newTask("metadataDelete", owner, func() {
+			f.deleteMetadata(href, domain, key)
+		}))
+
+	default:
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "Resource "+req.Method+" "+req.URL.Path+" not found.")
+	}
+}
+
+// readXML decodes the body of req into v, answering with an error and
+// returning false if it can't.
+func (f *FakeVCD) readXML(w http.ResponseWriter, req *http.Request, v interface{}) bool {
+	body, err := ioutil.ReadAll(req.Body)
+	if err == nil {
+		err = xml.Unmarshal(body, v)
+	}
+	if err != nil {
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "Malformed request body: "+strings.TrimSpace(err.Error()))
+		return false
+	}
+	return true
+}
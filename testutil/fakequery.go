@@ -0,0 +1,456 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+// fakeQueryRow is an entity as seen by the query service: the fields that
+// can be filtered and sorted on, and the entity as a record and a reference.
+type fakeQueryRow struct {
+	fields    map[string]string
+	record    func(records *types.QueryResultRecordsType, metadata *types.Metadata)
+	reference *types.Reference
+}
+
+// fakeQueryReferences is the references format of query results. The
+// references are named after the query type, e.g. VMReference.
+type fakeQueryReferences struct {
+	HREF      string                `xml:"href,attr"`
+	Type      string                `xml:"type,attr"`
+	Name      string                `xml:"name,attr"`
+	Page      int                   `xml:"page,attr"`
+	PageSize  int                   `xml:"pageSize,attr"`
+	Total     int                   `xml:"total,attr"`
+	Link      types.LinkList        `xml:"Link,omitempty"`
+	Reference []*fakeQueryReference `xml:",any"`
+}
+
+type fakeQueryReference struct {
+	XMLName xml.Name
+	HREF    string `xml:"href,attr"`
+	ID      string `xml:"id,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Name    string `xml:"name,attr,omitempty"`
+}
+
+var statusNames = map[int]string{
+	statusUnresolved: "UNRESOLVED",
+	statusSuspended:  "SUSPENDED",
+	statusPoweredOn:  "POWERED_ON",
+	statusPoweredOff: "POWERED_OFF",
+	10:               "MIXED",
+}
+
+// serveQuery answers the query service, for the vApp, vm and vAppTemplate
+// types. It
+// supports the records and references formats, paging, sorting, the
+// comparisons of the filter parameter and metadata fields.
+func (f *FakeVCD) serveQuery(w http.ResponseWriter, req *http.Request) {
+	params := req.URL.Query()
+
+	var rows []*fakeQueryRow
+	var refName string
+	switch params.Get("type") {
+	case types.QtVapp:
+		rows, refName = f.vappRows(), "VAppReference"
+	case types.QtVm:
+		rows, refName = f.vmRows(), "VMReference"
+	case types.QtVappTemplate:
+		rows, refName = f.templateRows(), "VAppTemplateReference"
+	default:
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", fmt.Sprintf("Unsupported query type %q.", params.Get("type")))
+		return
+	}
+
+	if filter := params.Get("filter"); filter != "" {
+		match, err := parseFakeFilter(filter)
+		if err != nil {
+			f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+			return
+		}
+		var matching []*fakeQueryRow
+		for _, row := range rows {
+			if match(row.fields) {
+				matching = append(matching, row)
+			}
+		}
+		rows = matching
+	}
+
+	if field := params.Get("sortAsc"); field != "" {
+		sort.SliceStable(rows, func(i, j int) bool { return compareFields(rows[i].fields[field], rows[j].fields[field]) < 0 })
+	}
+	if field := params.Get("sortDesc"); field != "" {
+		sort.SliceStable(rows, func(i, j int) bool { return compareFields(rows[i].fields[field], rows[j].fields[field]) > 0 })
+	}
+
+	page, pageSize := 1, 25
+	if n, err := strconv.Atoi(params.Get("page")); err == nil && n > 0 {
+		page = n
+	}
+	if n, err := strconv.Atoi(params.Get("pageSize")); err == nil && n > 0 {
+		pageSize = n
+	}
+	if pageSize > 128 {
+		pageSize = 128
+	}
+	total := len(rows)
+	start, end := (page-1)*pageSize, page*pageSize
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+	rows = rows[start:end]
+
+	var links types.LinkList
+	if end < total {
+		next := url.Values{}
+		for k, v := range params {
+			next[k] = v
+		}
+		next.Set("page", strconv.Itoa(page+1))
+		next.Set("pageSize", strconv.Itoa(pageSize))
+		links = append(links, &types.Link{Rel: types.RelNextPage, HREF: f.href("query") + "?" + next.Encode()})
+	}
+
+	if params.Get("format") == types.QueryFormatReferences {
+		refs := &fakeQueryReferences{
+			HREF:     f.href("query"),
+			Type:     "application/vnd.vmware.vcloud.query.references+xml",
+			Name:     params.Get("type"),
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+			Link:     links,
+		}
+		for _, row := range rows {
+			ref := row.reference
+			refs.Reference = append(refs.Reference, &fakeQueryReference{
+				XMLName: xml.Name{Local: refName},
+				HREF:    ref.HREF,
+				ID:      ref.ID,
+				Type:    ref.Type,
+				Name:    ref.Name,
+			})
+		}
+		f.writeXML(w, http.StatusOK, "QueryResultReferences", refs)
+		return
+	}
+
+	records := &types.QueryResultRecordsType{
+		HREF:     f.href("query"),
+		Type:     "application/vnd.vmware.vcloud.query.records+xml",
+		Name:     params.Get("type"),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    float64(total),
+		Link:     links,
+	}
+	fields := strings.Split(params.Get("fields"), ",")
+	for _, row := range rows {
+		row.record(records, f.recordMetadata(row.reference.HREF, fields))
+	}
+	f.writeXML(w, http.StatusOK, "QueryResultRecords", records)
+}
+
+func (f *FakeVCD) vappRows() []*fakeQueryRow {
+	var rows []*fakeQueryRow
+	for _, vapp := range f.vapps {
+		record := &types.QueryResultVAppRecordType{
+			HREF:        f.href("vApp/vapp-%s", vapp.id),
+			Name:        vapp.name,
+			Status:      statusNames[vapp.status],
+			Deployed:    vapp.deployed,
+			VdcHREF:     f.href("vdc/%s", f.vdcID),
+			VdcName:     f.Vdc,
+			NumberOfVMs: len(vapp.vms),
+			Busy:        f.tasksFor(f.href("vApp/vapp-%s", vapp.id)) != nil,
+		}
+		row := &fakeQueryRow{
+			fields: map[string]string{
+				"name":        record.Name,
+				"status":      record.Status,
+				"isDeployed":  strconv.FormatBool(record.Deployed),
+				"vdc":         record.VdcHREF,
+				"vdcName":     record.VdcName,
+				"numberOfVMs": strconv.Itoa(record.NumberOfVMs),
+			},
+			record: func(records *types.QueryResultRecordsType, metadata *types.Metadata) {
+				r := *record
+				r.Metadata = metadata
+				records.VAppRecord = append(records.VAppRecord, &r)
+			},
+			reference: &types.Reference{HREF: record.HREF, ID: "urn:vcloud:vapp:" + vapp.id, Type: "application/vnd.vmware.vcloud.vApp+xml", Name: vapp.name},
+		}
+		f.metadataFields(record.HREF, row.fields)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (f *FakeVCD) vmRows() []*fakeQueryRow {
+	var rows []*fakeQueryRow
+	for _, vapp := range f.vapps {
+		for _, vm := range vapp.vms {
+			record := &types.QueryResultVMRecordType{
+				HREF:           f.href("vApp/vm-%s", vm.id),
+				Name:           vm.name,
+				Status:         statusNames[vm.status],
+				Deployed:       vm.deployed,
+				VdcHREF:        f.href("vdc/%s", f.vdcID),
+				VAppParentHREF: f.href("vApp/vapp-%s", vapp.id),
+				VAppParentName: vapp.name,
+				Busy:           f.tasksFor(f.href("vApp/vm-%s", vm.id)) != nil,
+			}
+			row := &fakeQueryRow{
+				fields: map[string]string{
+					"name":           record.Name,
+					"status":         record.Status,
+					"isDeployed":     strconv.FormatBool(record.Deployed),
+					"vdc":            record.VdcHREF,
+					"container":      record.VAppParentHREF,
+					"containerName":  record.VAppParentName,
+					"isVAppTemplate": "false",
+				},
+				record: func(records *types.QueryResultRecordsType, metadata *types.Metadata) {
+					r := *record
+					r.Metadata = metadata
+					records.VMRecord = append(records.VMRecord, &r)
+				},
+				reference: &types.Reference{HREF: record.HREF, ID: "urn:vcloud:vm:" + vm.id, Type: "application/vnd.vmware.vcloud.vm+xml", Name: vm.name},
+			}
+			f.metadataFields(record.HREF, row.fields)
+			rows = append(rows, row)
+		}
+	}
+	return append(rows, f.templateVMRows()...)
+}
+
+// templateRows returns the vApp templates of the catalogs, followed by their
+// VMs as the vm query type has them.
+func (f *FakeVCD) templateRows() []*fakeQueryRow {
+	var rows []*fakeQueryRow
+	for _, cat := range f.catalogs {
+		for _, item := range cat.items {
+			tmpl := item.template
+			record := &types.QueryResultVAppTemplateRecordType{
+				HREF:        f.href("vAppTemplate/vappTemplate-%s", tmpl.id),
+				ID:          "urn:vcloud:vapptemplate:" + tmpl.id,
+				Name:        tmpl.name,
+				CatalogName: cat.name,
+				CatalogItem: f.href("catalogItem/%s", item.id),
+				Vdc:         f.href("vdc/%s", f.vdcID),
+				VdcName:     f.Vdc,
+				Status:      "RESOLVED",
+				IsEnabled:   true,
+				NumberOfVMs: len(tmpl.vms),
+			}
+			row := &fakeQueryRow{
+				fields: map[string]string{
+					"name":        record.Name,
+					"catalogName": record.CatalogName,
+					"vdc":         record.Vdc,
+					"vdcName":     record.VdcName,
+					"status":      record.Status,
+				},
+				record: func(records *types.QueryResultRecordsType, metadata *types.Metadata) {
+					r := *record
+					r.Metadata = metadata
+					records.VAppTemplateRecord = append(records.VAppTemplateRecord, &r)
+				},
+				reference: &types.Reference{HREF: record.HREF, ID: record.ID, Type: "application/vnd.vmware.vcloud.vAppTemplate+xml", Name: tmpl.name},
+			}
+			f.metadataFields(record.HREF, row.fields)
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// templateVMRows returns the VMs of the vApp templates, as the vm query type
+// has them.
+func (f *FakeVCD) templateVMRows() []*fakeQueryRow {
+	var rows []*fakeQueryRow
+	for _, cat := range f.catalogs {
+		for _, item := range cat.items {
+			for _, vm := range item.template.vms {
+				record := &types.QueryResultVMRecordType{
+					HREF:           f.href("vAppTemplate/vm-%s", vm.id),
+					Name:           vm.name,
+					Status:         "POWERED_OFF",
+					VAppTemplate:   true,
+					VdcHREF:        f.href("vdc/%s", f.vdcID),
+					VAppParentHREF: f.href("vAppTemplate/vappTemplate-%s", item.template.id),
+					VAppParentName: item.template.name,
+				}
+				row := &fakeQueryRow{
+					fields: map[string]string{
+						"name":           record.Name,
+						"status":         record.Status,
+						"vdc":            record.VdcHREF,
+						"container":      record.VAppParentHREF,
+						"containerName":  record.VAppParentName,
+						"isVAppTemplate": "true",
+					},
+					record: func(records *types.QueryResultRecordsType, metadata *types.Metadata) {
+						r := *record
+						r.Metadata = metadata
+						records.VMRecord = append(records.VMRecord, &r)
+					},
+					reference: &types.Reference{HREF: record.HREF, ID: "urn:vcloud:vm:" + vm.id, Type: "application/vnd.vmware.vcloud.vm+xml", Name: vm.name},
+				}
+				f.metadataFields(record.HREF, row.fields)
+				rows = append(rows, row)
+			}
+		}
+	}
+	return rows
+}
+
+var fakeCondition = regexp.MustCompile(`^([^=!()]+)(==|!=|=gt=|=ge=|=lt=|=le=)([^()]*)$`)
+
+// parseFakeFilter parses the filter parameter of a query: comparisons joined
+// with ; (and) and , (or), grouped with parentheses. Values are percent
+// encoded and may hold * wildcards.
+func parseFakeFilter(filter string) (func(map[string]string) bool, error) {
+	if terms := splitFilter(filter, ','); len(terms) > 1 {
+		return combineFilters(terms, false)
+	}
+	if terms := splitFilter(filter, ';'); len(terms) > 1 {
+		return combineFilters(terms, true)
+	}
+	if strings.HasPrefix(filter, "(") && strings.HasSuffix(filter, ")") {
+		return parseFakeFilter(filter[1 : len(filter)-1])
+	}
+
+	m := fakeCondition.FindStringSubmatch(filter)
+	if m == nil {
+		return nil, fmt.Errorf("Invalid filter expression %q.", filter)
+	}
+	field, operator := m[1], m[2]
+	value, err := url.PathUnescape(m[3])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid filter value %q.", m[3])
+	}
+	// Metadata values are prefixed with their type, e.g. NUMBER:12
+	if strings.HasPrefix(field, "metadata") {
+		i := strings.Index(value, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("Invalid metadata filter value %q.", value)
+		}
+		value = value[i+1:]
+	}
+	return func(fields map[string]string) bool {
+		actual, ok := fields[field]
+		if !ok {
+			return false
+		}
+		switch operator {
+		case "==":
+			return matchWildcard(value, actual)
+		case "!=":
+			return !matchWildcard(value, actual)
+		case "=gt=":
+			return compareFields(actual, value) > 0
+		case "=ge=":
+			return compareFields(actual, value) >= 0
+		case "=lt=":
+			return compareFields(actual, value) < 0
+		default:
+			return compareFields(actual, value) <= 0
+		}
+	}, nil
+}
+
+func combineFilters(terms []string, and bool) (func(map[string]string) bool, error) {
+	var matches []func(map[string]string) bool
+	for _, term := range terms {
+		match, err := parseFakeFilter(term)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return func(fields map[string]string) bool {
+		for _, match := range matches {
+			if match(fields) != and {
+				return !and
+			}
+		}
+		return and
+	}, nil
+}
+
+// splitFilter splits filter at the separators outside of parentheses.
+func splitFilter(filter string, separator byte) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i := 0; i < len(filter); i++ {
+		switch filter[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case separator:
+			if depth == 0 {
+				terms = append(terms, filter[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, filter[start:])
+}
+
+// matchWildcard matches s against pattern, where * matches any run of
+// characters.
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
+
+// compareFields compares two field values, as numbers if they both are.
+func compareFields(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,1186 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	types "github.com/ukcloud/govcloudair/types/v56"
+)
+
+const vcloudNamespace = "http://www.vmware.com/vcloud/v1.5"
+
+// Status values of vApps and VMs, see types.VAppStatuses.
+const (
+	statusUnresolved = 0
+	statusSuspended  = 3
+	statusPoweredOn  = 4
+	statusPoweredOff = 8
+)
+
+// FakeVCD is a stateful, in-memory vCloud Director. Unlike HTTPServer, which
+// answers with canned responses, it keeps track of the objects created
+// through the API: composing a vApp makes it show up in the vDC, powering it
+// on changes its status, and every operation returns a task that moves from
+// queued to running to success as time goes by.
+//
+// It serves a single organization with a single vDC. Catalogs, templates,
+// vApps and edge gateways can be seeded with the Add methods.
+type FakeVCD struct {
+	URL      string // Base URL, the API is served under URL/api
+	User     string // User accepted at login
+	Password string // Password accepted at login
+	Org      string // Name of the organization
+	Vdc      string // Name of the organization vDC
+
+	// SAMLKey verifies the SAML assertions presented at login, SAML logins
+	// are refused if nil. See SAMLIdP.
+	SAMLKey *rsa.PublicKey
+
+	// Versions are the API versions advertised by /api/versions. Requests
+	// asking for another version in their Accept header are rejected.
+	Versions []string
+
+	// TaskDuration is how long tasks take to complete. Tasks are queued
+	// during the first half and running during the second one. With the
+	// zero value tasks complete as soon as they are polled.
+	TaskDuration time.Duration
+
+	// Latency delays every response, to observe concurrent requests.
+	Latency time.Duration
+
+	server *httptest.Server
+
+	inFlight    int32
+	maxInFlight int32
+
+	mu        sync.Mutex
+	ids       int
+	token     string
+	bearer    bool
+	logins    int
+	apiTokens []string
+	orgID     string
+	vdcID     string
+	catalogs  []*fakeCatalog
+	templates map[string]*fakeTemplate
+	vapps     []*fakeVApp
+	edges     []*fakeEdgeGateway
+	tasks     map[string]*fakeTask
+	failNext  *types.Error
+	metadata  map[string][]*types.MetadataEntry
+}
+
+type fakeCatalog struct {
+	id, name string
+	items    []*fakeCatalogItem
+}
+
+type fakeCatalogItem struct {
+	id, name string
+	template *fakeTemplate
+}
+
+type fakeTemplate struct {
+	id, name string
+	vms      []*fakeTemplateVM
+}
+
+type fakeTemplateVM struct {
+	id, name string
+}
+
+type fakeVApp struct {
+	id, name string
+	status   int
+	deployed bool
+	vms      []*fakeVM
+}
+
+type fakeVM struct {
+	id, name string
+	status   int
+	deployed bool
+}
+
+type fakeEdgeGateway struct {
+	id, name string
+	services *types.GatewayFeatures
+}
+
+type fakeTask struct {
+	task     *types.Task
+	started  time.Time
+	complete func()
+	fail     *types.Error
+}
+
+// NewFakeVCD starts a FakeVCD listening on a random local port. Call Close
+// when done.
+func NewFakeVCD() *FakeVCD {
+	f := &FakeVCD{
+		User:      "user",
+		Password:  "password",
+		Org:       "org",
+		Vdc:       "vdc",
+		Versions:  []string{"5.5", "9.0", "27.0"},
+		templates: make(map[string]*fakeTemplate),
+		tasks:     make(map[string]*fakeTask),
+		metadata:  make(map[string][]*types.MetadataEntry),
+	}
+	f.orgID = f.newID()
+	f.vdcID = f.newID()
+	f.server = httptest.NewServer(f)
+	f.URL = f.server.URL
+	return f
+}
+
+// Close shuts the server down.
+func (f *FakeVCD) Close() {
+	f.server.Close()
+}
+
+// Endpoint returns the API endpoint to pass to NewVCDClient.
+func (f *FakeVCD) Endpoint() url.URL {
+	u, _ := url.Parse(f.URL + "/api")
+	return *u
+}
+
+// AddCatalogItem adds a vApp template with the given VMs to a catalog,
+// creating the catalog if needed.
+func (f *FakeVCD) AddCatalogItem(catalog, item string, vms ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var cat *fakeCatalog
+	for _, c := range f.catalogs {
+		if c.name == catalog {
+			cat = c
+		}
+	}
+	if cat == nil {
+		cat = &fakeCatalog{id: f.newID(), name: catalog}
+		f.catalogs = append(f.catalogs, cat)
+	}
+
+	tmpl := &fakeTemplate{id: f.newID(), name: item}
+	for _, vm := range vms {
+		tmpl.vms = append(tmpl.vms, &fakeTemplateVM{id: f.newID(), name: vm})
+	}
+	f.templates[tmpl.id] = tmpl
+	cat.items = append(cat.items, &fakeCatalogItem{id: f.newID(), name: item, template: tmpl})
+}
+
+// AddVApp adds a powered off vApp with the given VMs to the vDC.
+func (f *FakeVCD) AddVApp(name string, vms ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vapp := &fakeVApp{id: f.newID(), name: name, status: statusPoweredOff}
+	for _, vm := range vms {
+		vapp.vms = append(vapp.vms, &fakeVM{id: f.newID(), name: vm, status: statusPoweredOff})
+	}
+	f.vapps = append(f.vapps, vapp)
+}
+
+// AddEdgeGateway adds an edge gateway with no services configured to the vDC.
+func (f *FakeVCD) AddEdgeGateway(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.edges = append(f.edges, &fakeEdgeGateway{
+		id:   f.newID(),
+		name: name,
+		services: &types.GatewayFeatures{
+			GatewayDhcpService: &types.GatewayDhcpService{},
+			FirewallService:    &types.FirewallService{},
+			NatService:         &types.NatService{},
+		},
+	})
+}
+
+// ExpireSession invalidates the current session token, as the session timeout
+// of a real vCloud Director would.
+func (f *FakeVCD) ExpireSession() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = ""
+}
+
+// AddAPIToken registers an API refresh token accepted by the OAuth token
+// endpoint of the organization.
+func (f *FakeVCD) AddAPIToken(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.apiTokens = append(f.apiTokens, token)
+}
+
+// FailNextTask makes the next task started fail with the given error
+// instead of applying its changes.
+func (f *FakeVCD) FailNextTask(minor, message string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = &types.Error{MajorErrorCode: http.StatusInternalServerError, MinorErrorCode: minor, Message: message}
+}
+
+// Logins returns the number of successful logins so far.
+func (f *FakeVCD) Logins() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins
+}
+
+// newID returns a new UUID-like identifier.
+func (f *FakeVCD) newID() string {
+	f.ids++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", f.ids)
+}
+
+func (f *FakeVCD) href(format string, a ...interface{}) string {
+	return f.URL + "/api/" + fmt.Sprintf(format, a...)
+}
+
+// MaxInFlight returns the highest number of requests served at the same time
+// so far.
+func (f *FakeVCD) MaxInFlight() int {
+	return int(atomic.LoadInt32(&f.maxInFlight))
+}
+
+// ServeHTTP implements http.Handler.
+func (f *FakeVCD) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	n := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for max := atomic.LoadInt32(&f.maxInFlight); n > max; max = atomic.LoadInt32(&f.maxInFlight) {
+		if atomic.CompareAndSwapInt32(&f.maxInFlight, max, n) {
+			break
+		}
+	}
+	time.Sleep(f.Latency)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.advanceTasks()
+
+	if version, ok := f.acceptsVersion(req); !ok {
+		f.writeError(w, http.StatusNotAcceptable, "NOT_ACCEPTABLE", fmt.Sprintf("API version %s is not supported.", version))
+		return
+	}
+
+	switch {
+	case req.URL.Path == "/cloudapi/1.0.0/sessions" && req.Method == "POST":
+		f.serveBearerLogin(w, req)
+		return
+	case req.URL.Path == "/oauth/tenant/"+f.Org+"/token" && req.Method == "POST":
+		f.serveTokenLogin(w, req)
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/api"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case path == "versions" && req.Method == "GET":
+		f.serveVersions(w)
+		return
+	case path == "sessions" && req.Method == "POST":
+		f.serveLogin(w, req)
+		return
+	}
+
+	if !f.authorized(req) {
+		f.writeError(w, http.StatusUnauthorized, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "This operation is denied.")
+		return
+	}
+
+	// The metadata of any entity is under <entity>/metadata
+	for i, part := range parts {
+		if part == "metadata" && i > 0 {
+			f.serveMetadata(w, req, f.href("%s", strings.Join(parts[:i], "/")), parts[i+1:])
+			return
+		}
+	}
+
+	switch {
+	case path == "session" && req.Method == "GET":
+		f.writeXML(w, http.StatusOK, "Session", f.session())
+	case path == "session" && req.Method == "DELETE":
+		f.token = ""
+		w.WriteHeader(http.StatusNoContent)
+	case path == "query" && req.Method == "GET":
+		f.serveQuery(w, req)
+	case len(parts) == 2 && parts[0] == "org" && req.Method == "GET":
+		f.serveOrg(w, parts[1])
+	case len(parts) == 2 && parts[0] == "vdc" && req.Method == "GET":
+		f.serveVdc(w, parts[1])
+	case len(parts) == 4 && parts[0] == "vdc" && parts[2] == "action" && req.Method == "POST":
+		f.serveVdcAction(w, req, parts[1], parts[3])
+	case len(parts) == 2 && parts[0] == "catalog" && req.Method == "GET":
+		f.serveCatalog(w, parts[1])
+	case len(parts) == 2 && parts[0] == "catalogItem" && req.Method == "GET":
+		f.serveCatalogItem(w, parts[1])
+	case len(parts) == 2 && parts[0] == "vAppTemplate" && req.Method == "GET":
+		f.serveTemplate(w, parts[1])
+	case len(parts) == 2 && parts[0] == "vApp" && req.Method == "GET":
+		f.serveVAppOrVM(w, parts[1])
+	case len(parts) == 2 && parts[0] == "vApp" && req.Method == "DELETE":
+		f.deleteVApp(w, parts[1])
+	case len(parts) == 5 && parts[0] == "vApp" && parts[2] == "power" && parts[3] == "action" && req.Method == "POST":
+		f.powerAction(w, parts[1], parts[4])
+	case len(parts) == 4 && parts[0] == "vApp" && parts[2] == "action" && req.Method == "POST":
+		f.powerAction(w, parts[1], parts[3])
+	case len(parts) == 2 && parts[0] == "task" && req.Method == "GET":
+		f.serveTask(w, parts[1])
+	case len(parts) == 4 && parts[0] == "task" && parts[2] == "action" && parts[3] == "cancel" && req.Method == "POST":
+		f.cancelTask(w, parts[1])
+	case len(parts) == 2 && parts[0] == "tasksList" && req.Method == "GET":
+		f.serveTasksList(w, parts[1])
+	case len(parts) == 4 && parts[0] == "admin" && parts[1] == "vdc" && parts[3] == "edgeGateways" && req.Method == "GET":
+		f.serveEdgeGatewayRecords(w)
+	case len(parts) == 3 && parts[0] == "admin" && parts[1] == "edgeGateway" && req.Method == "GET":
+		f.serveEdgeGateway(w, parts[2])
+	case len(parts) == 5 && parts[0] == "admin" && parts[1] == "edgeGateway" && parts[4] == "configureServices" && req.Method == "POST":
+		f.configureServices(w, req, parts[2])
+	default:
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", fmt.Sprintf("Resource %s %s not found.", req.Method, req.URL.Path))
+	}
+}
+
+func (f *FakeVCD) writeXML(w http.ResponseWriter, status int, name string, v interface{}) {
+	// The root element is named explicitly and put in the vCloud namespace,
+	// the types don't always carry an XMLName.
+	start := xml.StartElement{
+		Name: xml.Name{Local: name},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: vcloudNamespace}},
+	}
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.EncodeElement(v, start); err != nil {
+		f.writeError(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprint(w, xml.Header+buf.String())
+}
+
+func (f *FakeVCD) writeError(w http.ResponseWriter, status int, minor, message string) {
+	f.writeXML(w, status, "Error", &types.Error{MajorErrorCode: status, MinorErrorCode: minor, Message: message})
+}
+
+type fakeSupportedVersions struct {
+	VersionInfo []fakeVersionInfo `xml:"VersionInfo"`
+}
+
+type fakeVersionInfo struct {
+	Version  string `xml:"Version"`
+	LoginURL string `xml:"LoginUrl"`
+}
+
+func (f *FakeVCD) serveVersions(w http.ResponseWriter) {
+	versions := new(fakeSupportedVersions)
+	for _, v := range f.Versions {
+		versions.VersionInfo = append(versions.VersionInfo, fakeVersionInfo{Version: v, LoginURL: f.href("sessions")})
+	}
+	f.writeXML(w, http.StatusOK, "SupportedVersions", versions)
+}
+
+// acceptsVersion checks the version parameters of the Accept headers of req
+// against Versions. It returns the offending version if there's one.
+func (f *FakeVCD) acceptsVersion(req *http.Request) (string, bool) {
+	for _, accept := range req.Header.Values("Accept") {
+		for _, param := range strings.Split(accept, ";")[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 || kv[0] != "version" {
+				continue
+			}
+			if !contains(f.Versions, kv[1]) {
+				return kv[1], false
+			}
+		}
+	}
+	return "", true
+}
+
+type fakeSession struct {
+	HREF string         `xml:"href,attr"`
+	Type string         `xml:"type,attr"`
+	User string         `xml:"user,attr"`
+	Org  string         `xml:"org,attr"`
+	Link types.LinkList `xml:"Link"`
+}
+
+func (f *FakeVCD) session() *fakeSession {
+	return &fakeSession{
+		HREF: f.href("session"),
+		Type: "application/vnd.vmware.vcloud.session+xml",
+		User: f.User,
+		Org:  f.Org,
+		Link: types.LinkList{
+			{Rel: "down", Type: "application/vnd.vmware.vcloud.org+xml", Name: f.Org, HREF: f.href("org/%s", f.orgID)},
+			{Rel: "down", Type: "application/vnd.vmware.vcloud.query.queryList+xml", HREF: f.href("query")},
+			{Rel: "remove", HREF: f.href("session")},
+		},
+	}
+}
+
+// authorized checks the legacy or bearer token of req against the session.
+func (f *FakeVCD) authorized(req *http.Request) bool {
+	if f.token == "" {
+		return false
+	}
+	if f.bearer {
+		return req.Header.Get("Authorization") == "Bearer "+f.token
+	}
+	return req.Header.Get("x-vcloud-authorization") == f.token
+}
+
+// login starts a new session, bearer sessions get a JWT-like token.
+func (f *FakeVCD) login(bearer bool) {
+	id := f.newID()
+	f.token = "fake-" + id
+	if bearer {
+		f.token = "eyJhbGciOiJub25lIn0." + id + ".fake"
+	}
+	f.bearer = bearer
+	f.logins++
+}
+
+func (f *FakeVCD) checkCredentials(w http.ResponseWriter, req *http.Request) bool {
+	user, password, ok := req.BasicAuth()
+	if !ok || user != f.User+"@"+f.Org || password != f.Password {
+		f.writeError(w, http.StatusUnauthorized, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "Invalid credentials.")
+		return false
+	}
+	return true
+}
+
+func (f *FakeVCD) serveLogin(w http.ResponseWriter, req *http.Request) {
+	if strings.HasPrefix(req.Header.Get("Authorization"), "SIGN ") {
+		if err := f.checkSAMLToken(req.Header.Get("Authorization")); err != nil {
+			f.writeError(w, http.StatusUnauthorized, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", err.Error())
+			return
+		}
+	} else if !f.checkCredentials(w, req) {
+		return
+	}
+	f.login(false)
+	w.Header().Set("x-vcloud-authorization", f.token)
+	f.writeXML(w, http.StatusOK, "Session", f.session())
+}
+
+// checkSAMLToken verifies the assertion of a SIGN authorization header.
+func (f *FakeVCD) checkSAMLToken(header string) error {
+	if f.SAMLKey == nil {
+		return fmt.Errorf("SAML login is not configured.")
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(strings.TrimPrefix(header, "SIGN "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	if params["org"] != f.Org {
+		return fmt.Errorf("Invalid organization %s.", params["org"])
+	}
+
+	data, err := base64.StdEncoding.DecodeString(params["token"])
+	if err != nil {
+		return fmt.Errorf("Invalid token encoding.")
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Invalid token compression.")
+	}
+	assertion, err := ioutil.ReadAll(gz)
+	if err != nil {
+		return fmt.Errorf("Invalid token compression.")
+	}
+
+	a, err := VerifySAMLAssertion(assertion, f.SAMLKey)
+	if err != nil {
+		return err
+	}
+	if a.Conditions.Audience != f.Org {
+		return fmt.Errorf("Assertion is not meant for organization %s.", f.Org)
+	}
+	return nil
+}
+
+// serveBearerLogin implements the cloudapi login of newer releases.
+func (f *FakeVCD) serveBearerLogin(w http.ResponseWriter, req *http.Request) {
+	if !f.checkCredentials(w, req) {
+		return
+	}
+	f.login(true)
+	w.Header().Set("X-VMWARE-VCLOUD-ACCESS-TOKEN", f.token)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id":"urn:vcloud:session:%s","user":{"name":%q},"org":{"name":%q}}`, f.newID(), f.User, f.Org)
+}
+
+// serveTokenLogin implements the OAuth refresh token grant used by API
+// tokens.
+func (f *FakeVCD) serveTokenLogin(w http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil || req.PostForm.Get("grant_type") != "refresh_token" ||
+		!contains(f.apiTokens, req.PostForm.Get("refresh_token")) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":"invalid_grant"}`)
+		return
+	}
+	f.login(true)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":2592000,"refresh_token":null}`, f.token)
+}
+
+// IssueToken starts a new session and returns its token, to be handed to a
+// client as a pre-issued token. With bearer set the token is a JWT.
+func (f *FakeVCD) IssueToken(bearer bool) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.login(bearer)
+	return f.token
+}
+
+func (f *FakeVCD) orgRef() *types.Reference {
+	return &types.Reference{HREF: f.href("org/%s", f.orgID), Name: f.Org, Type: "application/vnd.vmware.vcloud.org+xml"}
+}
+
+func (f *FakeVCD) serveOrg(w http.ResponseWriter, id string) {
+	if id != f.orgID {
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such organization.")
+		return
+	}
+	org := &types.Org{
+		HREF:      f.href("org/%s", f.orgID),
+		Type:      "application/vnd.vmware.vcloud.org+xml",
+		ID:        "urn:vcloud:org:" + f.orgID,
+		Name:      f.Org,
+		FullName:  f.Org,
+		IsEnabled: true,
+		Link: types.LinkList{
+			{Rel: "down", Type: "application/vnd.vmware.vcloud.vdc+xml", Name: f.Vdc, HREF: f.href("vdc/%s", f.vdcID)},
+			{Rel: "down", Type: "application/vnd.vmware.vcloud.tasksList+xml", HREF: f.href("tasksList/%s", f.orgID)},
+		},
+	}
+	for _, cat := range f.catalogs {
+		org.Link = append(org.Link, &types.Link{Rel: "down", Type: "application/vnd.vmware.vcloud.catalog+xml", Name: cat.name, HREF: f.href("catalog/%s", cat.id)})
+	}
+	f.writeXML(w, http.StatusOK, "Org", org)
+}
+
+func (f *FakeVCD) serveVdc(w http.ResponseWriter, id string) {
+	if id != f.vdcID {
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such vDC.")
+		return
+	}
+	entities := &types.ResourceEntities{}
+	for _, vapp := range f.vapps {
+		entities.ResourceEntity = append(entities.ResourceEntity, &types.ResourceReference{
+			HREF: f.href("vApp/vapp-%s", vapp.id),
+			Type: "application/vnd.vmware.vcloud.vApp+xml",
+			Name: vapp.name,
+		})
+	}
+	vdc := &types.Vdc{
+		HREF:            f.href("vdc/%s", f.vdcID),
+		Type:            "application/vnd.vmware.vcloud.vdc+xml",
+		ID:              "urn:vcloud:vdc:" + f.vdcID,
+		Name:            f.Vdc,
+		Status:          "1",
+		AllocationModel: "AllocationVApp",
+		IsEnabled:       true,
+		Link: types.LinkList{
+			{Rel: "up", Type: "application/vnd.vmware.vcloud.org+xml", HREF: f.href("org/%s", f.orgID)},
+			{Rel: "add", Type: "application/vnd.vmware.vcloud.composeVAppParams+xml", HREF: f.href("vdc/%s/action/composeVApp", f.vdcID)},
+			{Rel: "add", Type: "application/vnd.vmware.vcloud.instantiateVAppTemplateParams+xml", HREF: f.href("vdc/%s/action/instantiateVAppTemplate", f.vdcID)},
+			{Rel: "edgeGateways", Type: "application/vnd.vmware.vcloud.query.records+xml", HREF: f.href("admin/vdc/%s/edgeGateways", f.vdcID)},
+		},
+		ResourceEntities: []*types.ResourceEntities{entities},
+	}
+	f.writeXML(w, http.StatusOK, "Vdc", vdc)
+}
+
+func (f *FakeVCD) serveVdcAction(w http.ResponseWriter, req *http.Request, id, action string) {
+	if id != f.vdcID {
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such vDC.")
+		return
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+
+	var name, operation string
+	var sources []*types.Reference
+	switch action {
+	case "composeVApp":
+		params := new(types.ComposeVAppParams)
+		if err := xml.Unmarshal(body, params); err != nil {
+			f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+			return
+		}
+		name, operation = params.Name, "vdcComposeVapp"
+		if params.SourcedItem != nil && params.SourcedItem.Source != nil {
+			sources = append(sources, params.SourcedItem.Source)
+		}
+	case "instantiateVAppTemplate":
+		params := new(types.InstantiateVAppTemplateParams)
+		if err := xml.Unmarshal(body, params); err != nil {
+			f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+			return
+		}
+		name, operation = params.Name, "vdcInstantiateVapp"
+		if params.Source == nil || f.templateByHREF(params.Source.HREF) == nil {
+			f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "The source vApp template doesn't exist.")
+			return
+		}
+		for _, vm := range f.templateByHREF(params.Source.HREF).vms {
+			sources = append(sources, &types.Reference{Name: vm.name})
+		}
+	default:
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "Unknown vDC action "+action+".")
+		return
+	}
+
+	if name == "" {
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "The vApp name is mandatory.")
+		return
+	}
+	for _, vapp := range f.vapps {
+		if vapp.name == name {
+			f.writeError(w, http.StatusBadRequest, "DUPLICATE_NAME", fmt.Sprintf("The vApp name %q is already in use.", name))
+			return
+		}
+	}
+
+	vapp := &fakeVApp{id: f.newID(), name: name, status: statusUnresolved}
+	for _, src := range sources {
+		vapp.vms = append(vapp.vms, &fakeVM{id: f.newID(), name: src.Name, status: statusUnresolved})
+	}
+	f.vapps = append(f.vapps, vapp)
+
+	f.newTask(operation, f.vappRef(vapp), func() {
+		vapp.status = statusPoweredOff
+		for _, vm := range vapp.vms {
+			vm.status = statusPoweredOff
+		}
+	})
+
+	f.writeXML(w, http.StatusCreated, "VApp", f.vapp(vapp))
+}
+
+func (f *FakeVCD) templateByHREF(href string) *fakeTemplate {
+	for id, tmpl := range f.templates {
+		if href == f.href("vAppTemplate/vappTemplate-%s", id) {
+			return tmpl
+		}
+	}
+	return nil
+}
+
+func (f *FakeVCD) serveCatalog(w http.ResponseWriter, id string) {
+	for _, cat := range f.catalogs {
+		if cat.id != id {
+			continue
+		}
+		items := &types.CatalogItems{}
+		for _, item := range cat.items {
+			items.CatalogItem = append(items.CatalogItem, &types.Reference{
+				HREF: f.href("catalogItem/%s", item.id),
+				Type: "application/vnd.vmware.vcloud.catalogItem+xml",
+				Name: item.name,
+			})
+		}
+		f.writeXML(w, http.StatusOK, "Catalog", &types.Catalog{
+			HREF:         f.href("catalog/%s", cat.id),
+			Type:         "application/vnd.vmware.vcloud.catalog+xml",
+			ID:           "urn:vcloud:catalog:" + cat.id,
+			Name:         cat.name,
+			CatalogItems: []*types.CatalogItems{items},
+			Link:         types.LinkList{{Rel: "up", Type: "application/vnd.vmware.vcloud.org+xml", HREF: f.href("org/%s", f.orgID)}},
+		})
+		return
+	}
+	f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such catalog.")
+}
+
+func (f *FakeVCD) serveCatalogItem(w http.ResponseWriter, id string) {
+	for _, cat := range f.catalogs {
+		for _, item := range cat.items {
+			if item.id != id {
+				continue
+			}
+			f.writeXML(w, http.StatusOK, "CatalogItem", &types.CatalogItem{
+				HREF: f.href("catalogItem/%s", item.id),
+				Type: "application/vnd.vmware.vcloud.catalogItem+xml",
+				ID:   "urn:vcloud:catalogitem:" + item.id,
+				Name: item.name,
+				Entity: &types.Entity{
+					HREF: f.href("vAppTemplate/vappTemplate-%s", item.template.id),
+					Type: "application/vnd.vmware.vcloud.vAppTemplate+xml",
+					Name: item.template.name,
+				},
+				Link: types.LinkList{{Rel: "up", Type: "application/vnd.vmware.vcloud.catalog+xml", HREF: f.href("catalog/%s", cat.id)}},
+			})
+			return
+		}
+	}
+	f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such catalog item.")
+}
+
+func (f *FakeVCD) serveTemplate(w http.ResponseWriter, id string) {
+	tmpl := f.templates[strings.TrimPrefix(id, "vappTemplate-")]
+	if tmpl == nil {
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such vApp template.")
+		return
+	}
+	children := &types.VAppTemplateChildren{}
+	for _, vm := range tmpl.vms {
+		href := f.href("vAppTemplate/vm-%s", vm.id)
+		children.VM = append(children.VM, &types.VAppTemplate{
+			HREF: href,
+			Type: "application/vnd.vmware.vcloud.vm+xml",
+			ID:   "urn:vcloud:vm:" + vm.id,
+			Name: vm.name,
+			NetworkConnectionSection: &types.NetworkConnectionSection{
+				HREF: href + "/networkConnectionSection/",
+				Type: "application/vnd.vmware.vcloud.networkConnectionSection+xml",
+			},
+		})
+	}
+	f.writeXML(w, http.StatusOK, "VAppTemplate", &types.VAppTemplate{
+		HREF:     f.href("vAppTemplate/vappTemplate-%s", tmpl.id),
+		Type:     "application/vnd.vmware.vcloud.vAppTemplate+xml",
+		ID:       "urn:vcloud:vapptemplate:" + tmpl.id,
+		Name:     tmpl.name,
+		Status:   statusPoweredOff,
+		Children: children,
+	})
+}
+
+func (f *FakeVCD) vappRef(vapp *fakeVApp) *types.Reference {
+	return &types.Reference{HREF: f.href("vApp/vapp-%s", vapp.id), Name: vapp.name, Type: "application/vnd.vmware.vcloud.vApp+xml"}
+}
+
+func (f *FakeVCD) vmRef(vm *fakeVM) *types.Reference {
+	return &types.Reference{HREF: f.href("vApp/vm-%s", vm.id), Name: vm.name, Type: "application/vnd.vmware.vcloud.vm+xml"}
+}
+
+// tasksFor returns the unfinished tasks owned by href.
+func (f *FakeVCD) tasksFor(href string) *types.TasksInProgress {
+	var tasks []*types.Task
+	for _, t := range f.tasks {
+		if t.complete != nil && t.task.Owner != nil && t.task.Owner.HREF == href {
+			task := *t.task
+			tasks = append(tasks, &task)
+		}
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return &types.TasksInProgress{Task: tasks}
+}
+
+func (f *FakeVCD) vm(vm *fakeVM, parent *fakeVApp) *types.VM {
+	href := f.href("vApp/vm-%s", vm.id)
+	return &types.VM{
+		HREF:     href,
+		Type:     "application/vnd.vmware.vcloud.vm+xml",
+		ID:       "urn:vcloud:vm:" + vm.id,
+		Name:     vm.name,
+		Status:   vm.status,
+		Deployed: vm.deployed,
+		Link: types.LinkList{
+			{Rel: "up", Type: "application/vnd.vmware.vcloud.vApp+xml", HREF: f.href("vApp/vapp-%s", parent.id)},
+			{Rel: "power:powerOn", HREF: href + "/power/action/powerOn"},
+			{Rel: "power:powerOff", HREF: href + "/power/action/powerOff"},
+		},
+		Tasks: f.tasksFor(href),
+		NetworkConnectionSection: &types.NetworkConnectionSection{
+			HREF: href + "/networkConnectionSection/",
+			Type: "application/vnd.vmware.vcloud.networkConnectionSection+xml",
+		},
+	}
+}
+
+func (f *FakeVCD) vapp(vapp *fakeVApp) *types.VApp {
+	href := f.href("vApp/vapp-%s", vapp.id)
+	out := &types.VApp{
+		HREF:     href,
+		Type:     "application/vnd.vmware.vcloud.vApp+xml",
+		ID:       "urn:vcloud:vapp:" + vapp.id,
+		Name:     vapp.name,
+		Status:   vapp.status,
+		Deployed: vapp.deployed,
+		Link: types.LinkList{
+			{Rel: "up", Type: "application/vnd.vmware.vcloud.vdc+xml", HREF: f.href("vdc/%s", f.vdcID)},
+			{Rel: "power:powerOn", HREF: href + "/power/action/powerOn"},
+			{Rel: "power:powerOff", HREF: href + "/power/action/powerOff"},
+			{Rel: "deploy", Type: "application/vnd.vmware.vcloud.deployVAppParams+xml", HREF: href + "/action/deploy"},
+			{Rel: "undeploy", Type: "application/vnd.vmware.vcloud.undeployVAppParams+xml", HREF: href + "/action/undeploy"},
+			{Rel: "remove", HREF: href},
+		},
+		Tasks: f.tasksFor(href),
+	}
+	if len(vapp.vms) > 0 {
+		out.Children = &types.VAppChildren{}
+		for _, vm := range vapp.vms {
+			out.Children.VM = append(out.Children.VM, f.vm(vm, vapp))
+		}
+	}
+	return out
+}
+
+// findVAppOrVM resolves a vapp-<id> or vm-<id> path element.
+func (f *FakeVCD) findVAppOrVM(id string) (*fakeVApp, *fakeVM) {
+	for _, vapp := range f.vapps {
+		if id == "vapp-"+vapp.id {
+			return vapp, nil
+		}
+		for _, vm := range vapp.vms {
+			if id == "vm-"+vm.id {
+				return vapp, vm
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *FakeVCD) serveVAppOrVM(w http.ResponseWriter, id string) {
+	vapp, vm := f.findVAppOrVM(id)
+	switch {
+	case vm != nil:
+		f.writeXML(w, http.StatusOK, "Vm", f.vm(vm, vapp))
+	case vapp != nil:
+		f.writeXML(w, http.StatusOK, "VApp", f.vapp(vapp))
+	default:
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to entity "+id+".")
+	}
+}
+
+// busy writes a BUSY_ENTITY error and returns true if href has a task in
+// flight.
+func (f *FakeVCD) busy(w http.ResponseWriter, ref *types.Reference) bool {
+	if f.tasksFor(ref.HREF) == nil {
+		return false
+	}
+	f.writeError(w, http.StatusBadRequest, "BUSY_ENTITY",
+		fmt.Sprintf("The requested operation could not be executed since %s %q is busy.", ref.Type, ref.Name))
+	return true
+}
+
+func (f *FakeVCD) deleteVApp(w http.ResponseWriter, id string) {
+	vapp, vm := f.findVAppOrVM(id)
+	if vapp == nil || vm != nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to entity "+id+".")
+		return
+	}
+	ref := f.vappRef(vapp)
+	if f.busy(w, ref) {
+		return
+	}
+	if vapp.deployed {
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "The vApp must be undeployed before it can be deleted.")
+		return
+	}
+	task := f.newTask("vdcDeleteVapp", ref, func() {
+		for i, v := range f.vapps {
+			if v == vapp {
+				f.vapps = append(f.vapps[:i], f.vapps[i+1:]...)
+				break
+			}
+		}
+	})
+	f.writeXML(w, http.StatusAccepted, "Task", task)
+}
+
+// powerAction handles the power and deployment actions of vApps and VMs.
+func (f *FakeVCD) powerAction(w http.ResponseWriter, id, action string) {
+	vapp, vm := f.findVAppOrVM(id)
+	if vapp == nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to entity "+id+".")
+		return
+	}
+
+	type target struct {
+		status   *int
+		deployed *bool
+	}
+	var targets []target
+	ref := f.vappRef(vapp)
+	if vm != nil {
+		ref = f.vmRef(vm)
+		targets = append(targets, target{&vm.status, &vm.deployed})
+	} else {
+		targets = append(targets, target{&vapp.status, &vapp.deployed})
+		for _, vm := range vapp.vms {
+			targets = append(targets, target{&vm.status, &vm.deployed})
+		}
+	}
+
+	if f.busy(w, ref) {
+		return
+	}
+
+	status, deployed := -1, -1
+	switch action {
+	case "powerOn", "reboot", "reset":
+		status, deployed = statusPoweredOn, 1
+	case "powerOff", "shutdown":
+		status = statusPoweredOff
+	case "suspend":
+		status = statusSuspended
+	case "deploy":
+		deployed = 1
+	case "undeploy":
+		status, deployed = statusPoweredOff, 0
+	default:
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "Unknown action "+action+".")
+		return
+	}
+
+	task := f.newTask(action, ref, func() {
+		for _, t := range targets {
+			if status >= 0 {
+				*t.status = status
+			}
+			if deployed >= 0 {
+				*t.deployed = deployed == 1
+			}
+		}
+		// A vApp with all its VMs in the same state reports that state.
+		if vm != nil && len(vapp.vms) > 0 {
+			vapp.status = vapp.vms[0].status
+			for _, v := range vapp.vms {
+				if v.status != vapp.status {
+					vapp.status = 10 // MIXED
+				}
+			}
+		}
+	})
+	f.writeXML(w, http.StatusAccepted, "Task", task)
+}
+
+func (f *FakeVCD) serveEdgeGatewayRecords(w http.ResponseWriter) {
+	records := &types.QueryResultEdgeGatewayRecordsType{
+		HREF:     f.href("admin/vdc/%s/edgeGateways", f.vdcID),
+		Type:     "application/vnd.vmware.vcloud.query.records+xml",
+		Name:     "edgeGateway",
+		Page:     1,
+		PageSize: 25,
+		Total:    float64(len(f.edges)),
+	}
+	for _, edge := range f.edges {
+		records.EdgeGatewayRecord = append(records.EdgeGatewayRecord, &types.QueryResultEdgeGatewayRecordType{
+			HREF:          f.href("admin/edgeGateway/%s", edge.id),
+			Name:          edge.name,
+			Vdc:           f.href("vdc/%s", f.vdcID),
+			IsBusy:        f.tasksFor(f.href("admin/edgeGateway/%s", edge.id)) != nil,
+			GatewayStatus: "READY",
+			HaStatus:      "DISABLED",
+		})
+	}
+	f.writeXML(w, http.StatusOK, "QueryResultRecords", records)
+}
+
+func (f *FakeVCD) findEdgeGateway(id string) *fakeEdgeGateway {
+	for _, edge := range f.edges {
+		if edge.id == id {
+			return edge
+		}
+	}
+	return nil
+}
+
+func (f *FakeVCD) serveEdgeGateway(w http.ResponseWriter, id string) {
+	edge := f.findEdgeGateway(id)
+	if edge == nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to entity "+id+".")
+		return
+	}
+	href := f.href("admin/edgeGateway/%s", edge.id)
+	f.writeXML(w, http.StatusOK, "EdgeGateway", &types.EdgeGateway{
+		HREF:   href,
+		Type:   "application/vnd.vmware.admin.edgeGateway+xml",
+		ID:     "urn:vcloud:gateway:" + edge.id,
+		Name:   edge.name,
+		Status: 1,
+		Link: types.LinkList{
+			{Rel: "up", Type: "application/vnd.vmware.vcloud.vdc+xml", HREF: f.href("vdc/%s", f.vdcID)},
+			{Rel: "edgeGateway:configureServices", Type: "application/vnd.vmware.admin.edgeGatewayServiceConfiguration+xml", HREF: href + "/action/configureServices"},
+		},
+		Tasks: f.tasksFor(href),
+		Configuration: &types.GatewayConfiguration{
+			GatewayBackingConfig: "compact",
+			GatewayInterfaces: &types.GatewayInterfaces{
+				GatewayInterface: []*types.GatewayInterface{{
+					Name:          "uplink",
+					DisplayName:   "uplink",
+					Network:       &types.Reference{HREF: f.href("admin/network/%s", edge.id), Name: "uplink", Type: "application/vnd.vmware.admin.network+xml"},
+					InterfaceType: "uplink",
+				}},
+			},
+			EdgeGatewayServiceConfiguration: edge.services,
+		},
+	})
+}
+
+func (f *FakeVCD) configureServices(w http.ResponseWriter, req *http.Request, id string) {
+	edge := f.findEdgeGateway(id)
+	if edge == nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to entity "+id+".")
+		return
+	}
+	ref := &types.Reference{HREF: f.href("admin/edgeGateway/%s", edge.id), Name: edge.name, Type: "edgeGateway"}
+	if f.busy(w, ref) {
+		return
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	config := new(types.EdgeGatewayServiceConfiguration)
+	if err := xml.Unmarshal(body, config); err != nil {
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+
+	task := f.newTask("networkConfigureEdgeGatewayServices", ref, func() {
+		if config.GatewayDhcpService != nil {
+			edge.services.GatewayDhcpService = config.GatewayDhcpService
+		}
+		if config.FirewallService != nil {
+			edge.services.FirewallService = config.FirewallService
+		}
+		if config.NatService != nil {
+			edge.services.NatService = config.NatService
+		}
+		if config.GatewayIpsecVpnService != nil {
+			edge.services.GatewayIpsecVpnService = config.GatewayIpsecVpnService
+		}
+	})
+	f.writeXML(w, http.StatusAccepted, "Task", task)
+}
+
+// newTask queues a task on owner. complete is called when the task succeeds.
+func (f *FakeVCD) newTask(operation string, owner *types.Reference, complete func()) *types.Task {
+	id := f.newID()
+	now := time.Now()
+	t := &types.Task{
+		HREF:             f.href("task/%s", id),
+		Type:             "application/vnd.vmware.vcloud.task+xml",
+		ID:               "urn:vcloud:task:" + id,
+		Name:             "task",
+		Status:           "queued",
+		Operation:        fmt.Sprintf("%s %s(%s)", operation, owner.Name, owner.HREF),
+		OperationName:    operation,
+		ServiceNamespace: "com.vmware.vcloud",
+		StartTime:        now.UTC().Format(time.RFC3339),
+		ExpiryTime:       now.Add(90 * 24 * time.Hour).UTC().Format(time.RFC3339),
+		Owner:            owner,
+		Organization:     f.orgRef(),
+		User:             &types.Reference{HREF: f.href("admin/user/%s", f.orgID), Name: f.User, Type: "application/vnd.vmware.admin.user+xml"},
+		Link: types.LinkList{
+			{Rel: "task:cancel", HREF: f.href("task/%s/action/cancel", id)},
+		},
+	}
+	f.tasks[id] = &fakeTask{task: t, started: now, complete: complete, fail: f.failNext}
+	f.failNext = nil
+	copied := *t
+	return &copied
+}
+
+// advanceTasks moves the tasks along according to TaskDuration, applying the
+// changes of the ones that complete.
+func (f *FakeVCD) advanceTasks() {
+	now := time.Now()
+	for _, t := range f.tasks {
+		if t.complete == nil {
+			continue
+		}
+		elapsed := now.Sub(t.started)
+		switch {
+		case elapsed >= f.TaskDuration:
+			t.task.EndTime = now.UTC().Format(time.RFC3339)
+			t.task.Link = nil
+			complete := t.complete
+			t.complete = nil
+			if t.fail != nil {
+				t.task.Status = "error"
+				t.task.Description = t.fail.Message
+				t.task.Error = t.fail
+				continue
+			}
+			t.task.Status = "success"
+			t.task.Progress = 100
+			complete()
+		case elapsed >= f.TaskDuration/2:
+			t.task.Status = "running"
+			t.task.Progress = int(100 * elapsed / f.TaskDuration)
+		}
+	}
+}
+
+func (f *FakeVCD) serveTask(w http.ResponseWriter, id string) {
+	t := f.tasks[id]
+	if t == nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to task "+id+".")
+		return
+	}
+	f.writeXML(w, http.StatusOK, "Task", t.task)
+}
+
+// cancelTask aborts a task in flight, its changes are never applied.
+func (f *FakeVCD) cancelTask(w http.ResponseWriter, id string) {
+	t := f.tasks[id]
+	if t == nil {
+		f.writeError(w, http.StatusForbidden, "ACCESS_TO_RESOURCE_IS_FORBIDDEN", "No access to task "+id+".")
+		return
+	}
+	if t.complete == nil {
+		f.writeError(w, http.StatusBadRequest, "BAD_REQUEST", "The task has already completed and can't be cancelled.")
+		return
+	}
+	t.complete = nil
+	t.task.Status = "aborted"
+	t.task.CancelRequested = true
+	t.task.EndTime = time.Now().UTC().Format(time.RFC3339)
+	t.task.Link = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveTasksList lists the tasks of the organization, oldest first.
+func (f *FakeVCD) serveTasksList(w http.ResponseWriter, id string) {
+	if id != f.orgID {
+		f.writeError(w, http.StatusNotFound, "RESOURCE_NOT_FOUND", "No such organization.")
+		return
+	}
+	list := &types.TasksList{
+		HREF: f.href("tasksList/%s", f.orgID),
+		Type: "application/vnd.vmware.vcloud.tasksList+xml",
+		Name: "Tasks Lists",
+	}
+	for _, t := range f.tasks {
+		list.Task = append(list.Task, t.task)
+	}
+	sort.Slice(list.Task, func(i, j int) bool { return list.Task[i].HREF < list.Task[j].HREF })
+	f.writeXML(w, http.StatusOK, "TasksList", list)
+}
@@ -0,0 +1,147 @@
+//
+// The software in this package is published under the terms of the Mozilla
+// Public License, version 2.0 a copy of which has been included with this
+// distribution in the LICENSE file.
+//
+
+package testutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// SAMLIdP is a stand-in identity provider issuing SAML assertions signed
+// with a throwaway RSA key. The signature covers the assertion as serialized
+// without its Signature element, which is enough to tell genuine assertions
+// from forged or tampered ones in tests, but isn't XML-DSig.
+type SAMLIdP struct {
+	Issuer   string        // Issuer of the assertions
+	Validity time.Duration // How long assertions are valid, 5 minutes if zero
+
+	key *rsa.PrivateKey
+	ids int
+}
+
+// NewSAMLIdP returns an identity provider with a freshly generated key.
+func NewSAMLIdP(issuer string) (*SAMLIdP, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("error generating IdP key: %w", err)
+	}
+	return &SAMLIdP{Issuer: issuer, key: key}, nil
+}
+
+// PublicKey returns the key verifying the assertions of the IdP.
+func (idp *SAMLIdP) PublicKey() *rsa.PublicKey {
+	return &idp.key.PublicKey
+}
+
+// SAMLAssertion is the subset of a SAML 2.0 assertion issued by SAMLIdP.
+type SAMLAssertion struct {
+	XMLName      xml.Name `xml:"urn:oasis:names:tc:SAML:2.0:assertion Assertion"`
+	ID           string   `xml:"ID,attr"`
+	IssueInstant string   `xml:"IssueInstant,attr"`
+	Version      string   `xml:"Version,attr"`
+	Issuer       string   `xml:"Issuer"`
+	Subject      struct {
+		NameID string `xml:"NameID"`
+	} `xml:"Subject"`
+	Conditions struct {
+		NotBefore    string `xml:"NotBefore,attr"`
+		NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+		Audience     string `xml:"AudienceRestriction>Audience"`
+	} `xml:"Conditions"`
+	Signature *samlSignature `xml:"http://www.w3.org/2000/09/xmldsig# Signature,omitempty"`
+}
+
+type samlSignature struct {
+	SignatureValue string `xml:"http://www.w3.org/2000/09/xmldsig# SignatureValue"`
+}
+
+// Assertion issues a signed assertion for user, meant for audience, usually
+// the organization name.
+func (idp *SAMLIdP) Assertion(user, audience string) ([]byte, error) {
+
+	validity := idp.Validity
+	if validity == 0 {
+		validity = 5 * time.Minute
+	}
+
+	idp.ids++
+	now := time.Now().UTC()
+	a := &SAMLAssertion{
+		ID:           fmt.Sprintf("_assertion-%d", idp.ids),
+		IssueInstant: now.Format(time.RFC3339),
+		Version:      "2.0",
+		Issuer:       idp.Issuer,
+	}
+	a.Subject.NameID = user
+	a.Conditions.NotBefore = now.Add(-time.Minute).Format(time.RFC3339)
+	a.Conditions.NotOnOrAfter = now.Add(validity).Format(time.RFC3339)
+	a.Conditions.Audience = audience
+
+	unsigned, err := xml.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(unsigned)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("error signing assertion: %w", err)
+	}
+
+	a.Signature = &samlSignature{SignatureValue: base64.StdEncoding.EncodeToString(signature)}
+	return xml.Marshal(a)
+}
+
+// VerifySAMLAssertion checks the signature and the validity period of an
+// assertion issued by a SAMLIdP with the given key, and returns it.
+func VerifySAMLAssertion(data []byte, key *rsa.PublicKey) (*SAMLAssertion, error) {
+
+	a := new(SAMLAssertion)
+	if err := xml.Unmarshal(data, a); err != nil {
+		return nil, fmt.Errorf("error decoding assertion: %w", err)
+	}
+	if a.Signature == nil {
+		return nil, fmt.Errorf("assertion is not signed")
+	}
+	signature, err := base64.StdEncoding.DecodeString(a.Signature.SignatureValue)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding assertion signature: %w", err)
+	}
+
+	// Serialize the assertion again without its signature, a tampered
+	// assertion doesn't serialize to the signed bytes.
+	unsigned := *a
+	unsigned.Signature = nil
+	signed, err := xml.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(signed)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("invalid assertion signature: %w", err)
+	}
+
+	now := time.Now().UTC()
+	notBefore, err := time.Parse(time.RFC3339, a.Conditions.NotBefore)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding assertion conditions: %w", err)
+	}
+	notOnOrAfter, err := time.Parse(time.RFC3339, a.Conditions.NotOnOrAfter)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding assertion conditions: %w", err)
+	}
+	if now.Before(notBefore) || !now.Before(notOnOrAfter) {
+		return nil, fmt.Errorf("assertion expired")
+	}
+
+	return a, nil
+}
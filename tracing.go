@@ -0,0 +1,162 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Tracer starts the spans recorded around public operations, the HTTP
+// requests they send and the tasks they wait for. It mirrors the
+// OpenTelemetry tracer API so that an adapter is a few lines long: the span
+// to use as parent is carried by ctx, and Start returns a context carrying
+// the new span. Implementations must be safe for concurrent use.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation, see Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// startSpan starts a span named name as a child of the span carried by ctx.
+// It is a no-op if the client has no Tracer.
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c == nil || c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.Tracer.Start(ctx, name)
+}
+
+// startRequestSpan starts the span of a single HTTP request. The request is
+// sent with the returned context, so that middleware can propagate the trace
+// context to the server.
+func (c *Client) startRequestSpan(ctx context.Context, req *http.Request) (context.Context, Span) {
+	ctx, span := c.startSpan(ctx, "HTTP "+req.Method)
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.url", req.URL.String())
+	span.SetAttribute("vcd.entity_type", entityType(req.URL))
+	return ctx, span
+}
+
+// endSpan records err, if any, and ends span.
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(key string, value interface{}) {}
+func (noopSpan) RecordError(err error)                      {}
+func (noopSpan) End()                                       {}
+
+// MemoryTracer is a Tracer keeping the finished spans in memory, so that tests
+// can assert the span tree of an operation.
+type MemoryTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by MemoryTracer.
+type RecordedSpan struct {
+	Name       string
+	Parent     *RecordedSpan // nil for root spans
+	Attributes map[string]interface{}
+	Err        error // Last error recorded, if any
+	StartTime  time.Time
+	EndTime    time.Time
+
+	tracer *MemoryTracer
+	mu     sync.Mutex
+}
+
+type memorySpanKey struct{}
+
+// NewMemoryTracer returns an empty MemoryTracer.
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+// Start implements Tracer.
+func (t *MemoryTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(memorySpanKey{}).(*RecordedSpan)
+	span := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: make(map[string]interface{}),
+		StartTime:  time.Now(),
+		tracer:     t,
+	}
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+// Spans returns the finished spans in the order they ended.
+func (t *MemoryTracer) Spans() []*RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*RecordedSpan(nil), t.spans...)
+}
+
+// Find returns the finished spans named name.
+func (t *MemoryTracer) Find(name string) []*RecordedSpan {
+	var found []*RecordedSpan
+	for _, span := range t.Spans() {
+		if span.Name == name {
+			found = append(found, span)
+		}
+	}
+	return found
+}
+
+// Children returns the finished spans whose parent is span.
+func (t *MemoryTracer) Children(span *RecordedSpan) []*RecordedSpan {
+	var children []*RecordedSpan
+	for _, s := range t.Spans() {
+		if s.Parent == span {
+			children = append(children, s)
+		}
+	}
+	return children
+}
+
+// Reset forgets the spans recorded so far.
+func (t *MemoryTracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+// SetAttribute implements Span.
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError implements Span.
+func (s *RecordedSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Err = err
+}
+
+// End implements Span.
+func (s *RecordedSpan) End() {
+	s.mu.Lock()
+	s.EndTime = time.Now()
+	s.mu.Unlock()
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.spans = append(s.tracer.spans, s)
+}
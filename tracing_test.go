@@ -0,0 +1,76 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_Tracing(c *C) {
+
+	tracer := NewMemoryTracer()
+	s.client.Client.Tracer = tracer
+	defer func() { s.client.Client.Tracer = nil }()
+
+	vapp := NewVApp(&s.client.Client)
+	vapp.VApp.HREF = "http://localhost:4444/api/vApp/vapp-00000000-0000-0000-0000-000000000000"
+
+	// Spans nest under the span carried by the caller's context.
+	ctx, root := tracer.Start(context.Background(), "provision")
+
+	testServer.Response(200, nil, taskExample)
+	task, err := vapp.PowerOnWithContext(ctx)
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+
+	testServer.Response(200, nil, taskExample)
+	err = task.WaitTaskCompletionWithContext(ctx)
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, IsNil)
+	root.End()
+
+	rootSpan := tracer.Find("provision")[0]
+	children := tracer.Children(rootSpan)
+	c.Assert(children, HasLen, 2)
+	c.Assert(children[0].Name, Equals, "VApp.PowerOn")
+	c.Assert(children[1].Name, Equals, "Task.WaitTaskCompletion")
+
+	requests := tracer.Children(children[0])
+	c.Assert(requests, HasLen, 1)
+	c.Assert(requests[0].Name, Equals, "HTTP POST")
+	c.Assert(requests[0].Attributes["http.status_code"], Equals, 200)
+	c.Assert(requests[0].Attributes["vcd.entity_type"], Equals, "vApp")
+
+	wait := children[1]
+	c.Assert(wait.Attributes["task.href"], Equals, "http://localhost:4444/api/task/1b8f926c-eff5-4bea-9b13-4e49bdd50c05")
+	c.Assert(wait.Attributes["task.operation"], Equals, "vdcComposeVapp")
+	c.Assert(wait.Attributes["task.status"], Equals, "success")
+	refresh := tracer.Children(wait)
+	c.Assert(refresh, HasLen, 1)
+	c.Assert(refresh[0].Name, Equals, "Task.Refresh")
+	c.Assert(tracer.Children(refresh[0])[0].Name, Equals, "HTTP GET")
+}
+
+func (s *S) Test_TracingError(c *C) {
+
+	tracer := NewMemoryTracer()
+	s.client.Client.Tracer = tracer
+	defer func() { s.client.Client.Tracer = nil }()
+
+	testServer.Response(500, nil, vcdError)
+	err := s.vdc.Refresh()
+	_ = testServer.WaitRequest()
+	testServer.Flush()
+	c.Assert(err, NotNil)
+
+	spans := tracer.Find("Vdc.Refresh")
+	c.Assert(spans, HasLen, 1)
+	c.Assert(spans[0].Err, ErrorMatches, ".*Error Message.*")
+	c.Assert(tracer.Children(spans[0])[0].Attributes["http.status_code"], Equals, 500)
+}
@@ -43,6 +43,8 @@ const (
 	MimeInstantiateVAppTemplate = "application/vnd.vmware.vcloud.instantiateVAppTemplateParams+xml"
 	// MimeVApp mime for a vApp
 	MimeVApp = "application/vnd.vmware.vcloud.vApp+xml"
+	// MimeVM mime for a VM
+	MimeVM = "application/vnd.vmware.vcloud.vm+xml"
 	// MimeQueryRecords mime for the query records
 	MimeQueryRecords = "application/vnd.vmware.vchs.query.records+xml"
 	// MimeAPIExtensibility mime for api extensibility
@@ -55,10 +57,20 @@ const (
 	MimeSession = "application/vnd.vmware.vcloud.session+xml"
 	// MimeTask mime for task
 	MimeTask = "application/vnd.vmware.vcloud.task+xml"
+	// MimeTasksList mime for a list of tasks
+	MimeTasksList = "application/vnd.vmware.vcloud.tasksList+xml"
 	// MimeError mime for error
 	MimeError = "application/vnd.vmware.vcloud.error+xml"
 	// MimeNetwork mime for a network
 	MimeNetwork = "application/vnd.vmware.vcloud.network+xml"
+	// MimeOrgVdcNetwork mime for an org vdc network
+	MimeOrgVdcNetwork = "application/vnd.vmware.vcloud.orgVdcNetwork+xml"
+	// MimeEdgeGateway mime for an edge gateway
+	MimeEdgeGateway = "application/vnd.vmware.admin.edgeGateway+xml"
+	// MimeMetadata mime for the metadata of an entity
+	MimeMetadata = "application/vnd.vmware.vcloud.metadata+xml"
+	// MimeMetadataValue mime for a single metadata value
+	MimeMetadataValue = "application/vnd.vmware.vcloud.metadata.value+xml"
 )
 
 const (
@@ -73,3 +85,99 @@ const (
 	// HTTPDelete the http DELETE method
 	HTTPDelete = "DELETE"
 )
+
+const (
+	// QtVapp the query type for vApps
+	QtVapp = "vApp"
+	// QtAdminVapp the query type for vApps, system administrator view
+	QtAdminVapp = "adminVApp"
+	// QtVm the query type for VMs
+	QtVm = "vm"
+	// QtAdminVm the query type for VMs, system administrator view
+	QtAdminVm = "adminVM"
+	// QtVappTemplate the query type for vApp templates
+	QtVappTemplate = "vAppTemplate"
+	// QtAdminVappTemplate the query type for vApp templates, system administrator view
+	QtAdminVappTemplate = "adminVAppTemplate"
+	// QtCatalog the query type for catalogs
+	QtCatalog = "catalog"
+	// QtAdminCatalog the query type for catalogs, system administrator view
+	QtAdminCatalog = "adminCatalog"
+	// QtCatalogItem the query type for catalog items
+	QtCatalogItem = "catalogItem"
+	// QtAdminCatalogItem the query type for catalog items, system administrator view
+	QtAdminCatalogItem = "adminCatalogItem"
+	// QtMedia the query type for media
+	QtMedia = "media"
+	// QtAdminMedia the query type for media, system administrator view
+	QtAdminMedia = "adminMedia"
+	// QtOrgVdcNetwork the query type for org VDC networks
+	QtOrgVdcNetwork = "orgVdcNetwork"
+	// QtOrgVdc the query type for org VDCs
+	QtOrgVdc = "orgVdc"
+	// QtAdminOrgVdc the query type for org VDCs, system administrator view
+	QtAdminOrgVdc = "adminOrgVdc"
+	// QtTask the query type for tasks
+	QtTask = "task"
+	// QtAdminTask the query type for tasks, system administrator view
+	QtAdminTask = "adminTask"
+	// QtEvent the query type for events
+	QtEvent = "event"
+	// QtAdminEvent the query type for events, system administrator view
+	QtAdminEvent = "adminEvent"
+	// QtDisk the query type for independent disks
+	QtDisk = "disk"
+	// QtAdminDisk the query type for independent disks, system administrator view
+	QtAdminDisk = "adminDisk"
+	// QtUser the query type for users
+	QtUser = "user"
+	// QtAdminUser the query type for users, system administrator view
+	QtAdminUser = "adminUser"
+	// QtGroup the query type for groups
+	QtGroup = "group"
+	// QtAdminGroup the query type for groups, system administrator view
+	QtAdminGroup = "adminGroup"
+	// QtEdgeGateway the query type for edge gateways
+	QtEdgeGateway = "edgeGateway"
+	// QtOrgVdcStorageProfile the query type for org VDC storage profiles
+	QtOrgVdcStorageProfile = "orgVdcStorageProfile"
+	// QtAdminOrgVdcStorageProfile the query type for org VDC storage profiles, system administrator view
+	QtAdminOrgVdcStorageProfile = "adminOrgVdcStorageProfile"
+	// QtAllocatedExternalAddress the query type for external IP addresses allocated to edge gateways
+	QtAllocatedExternalAddress = "allocatedExternalAddress"
+	// QtAdminAllocatedExternalAddress the query type for allocated external IP addresses, system administrator view
+	QtAdminAllocatedExternalAddress = "adminAllocatedExternalAddress"
+)
+
+const (
+	// QueryFormatRecords the query format returning records with the attributes of each entity
+	QueryFormatRecords = "records"
+	// QueryFormatIDRecords the query format returning records with identifiers instead of HREFs
+	QueryFormatIDRecords = "idrecords"
+	// QueryFormatReferences the query format returning references to the entities
+	QueryFormatReferences = "references"
+)
+
+const (
+	// MetadataStringValue the xsi:type of string metadata values
+	MetadataStringValue = "MetadataStringValue"
+	// MetadataNumberValue the xsi:type of number metadata values
+	MetadataNumberValue = "MetadataNumberValue"
+	// MetadataBooleanValue the xsi:type of boolean metadata values
+	MetadataBooleanValue = "MetadataBooleanValue"
+	// MetadataDateTimeValue the xsi:type of date and time metadata values
+	MetadataDateTimeValue = "MetadataDateTimeValue"
+)
+
+const (
+	// MetadataDomainGeneral the domain of the metadata set by users
+	MetadataDomainGeneral = "GENERAL"
+	// MetadataDomainSystem the domain of the metadata set by system administrators
+	MetadataDomainSystem = "SYSTEM"
+	// MetadataReadWrite the visibility of metadata users can read and write
+	MetadataReadWrite = "READWRITE"
+	// MetadataReadOnly the visibility of metadata users can read only
+	MetadataReadOnly = "READONLY"
+	// MetadataHidden the visibility of metadata hidden from users
+	MetadataHidden = "PRIVATE"
+)
@@ -394,12 +394,35 @@ type Task struct {
 	Description      string           `xml:"Description,omitempty"`
 	Details          string           `xml:"Details,omitempty"`
 	Error            *Error           `xml:"Error,omitempty"`
-	Link             *Link            `xml:"Link,omitempty"`
+	Link             LinkList         `xml:"Link,omitempty"`
 	Organization     *Reference       `xml:"Organization,omitempty"`
 	Owner            *Reference       `xml:"Owner,omitempty"`
 	Progress         int              `xml:"Progress,omitempty"`
 	Tasks            *TasksInProgress `xml:"Tasks,omitempty"`
 	User             *Reference       `xml:"User,omitempty"`
+	Result           *TaskResult      `xml:"Result,omitempty"`
+}
+
+// TaskResult represents the result of a completed task, for operations that
+// return one.
+// Type: ResultType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: Result of a task.
+// Since: 5.1
+type TaskResult struct {
+	ResultContent string `xml:"ResultContent,omitempty"` // The result of the task, its format depends on the operation
+}
+
+// TasksList represents a list of tasks.
+// Type: TasksListType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: Represents a list of tasks.
+// Since: 0.9
+type TasksList struct {
+	HREF string  `xml:"href,attr,omitempty"`
+	Type string  `xml:"type,attr,omitempty"`
+	Name string  `xml:"name,attr,omitempty"`
+	Task []*Task `xml:"Task,omitempty"`
 }
 
 // CapacityWithUsage represents a capacity and usage of a given resource.
@@ -822,10 +845,11 @@ type Value struct {
 }
 
 type MetadataValue struct {
-	XMLName    xml.Name    `xml:"MetadataValue"`
-	Xsi        string      `xml:"xmlns:xsi,attr"`
-	Xmlns      string      `xml:"xmlns,attr"`
-	TypedValue *TypedValue `xml:"TypedValue"`
+	XMLName    xml.Name           `xml:"MetadataValue"`
+	Xsi        string             `xml:"xmlns:xsi,attr"`
+	Xmlns      string             `xml:"xmlns,attr"`
+	Domain     *MetadataDomainTag `xml:"Domain,omitempty"`
+	TypedValue *TypedValue        `xml:"TypedValue"`
 }
 
 type TypedValue struct {
@@ -833,6 +857,63 @@ type TypedValue struct {
 	Value   string `xml:"Value"`
 }
 
+// UnmarshalXML decodes a TypedValue. The xsi:type tag of XsiType is only
+// good for marshalling, the decoder resolves the xsi prefix to its namespace.
+func (t *TypedValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "type" && (attr.Name.Space == NsXMLSchema || attr.Name.Space == "xsi") {
+			t.XsiType = attr.Value
+		}
+	}
+	var value struct {
+		Value string `xml:"Value"`
+	}
+	if err := d.DecodeElement(&value, &start); err != nil {
+		return err
+	}
+	t.Value = value.Value
+	return nil
+}
+
+// Metadata is the metadata of an entity.
+// Type: MetadataType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: User-defined metadata associated with with an object.
+// Since: 1.5
+type Metadata struct {
+	XMLName       xml.Name         `xml:"Metadata"`
+	Xmlns         string           `xml:"xmlns,attr,omitempty"`
+	Xsi           string           `xml:"xmlns:xsi,attr,omitempty"`
+	HREF          string           `xml:"href,attr,omitempty"`
+	Type          string           `xml:"type,attr,omitempty"`
+	Link          LinkList         `xml:"Link,omitempty"`
+	MetadataEntry []*MetadataEntry `xml:"MetadataEntry"`
+}
+
+// MetadataEntry is a single key of the metadata of an entity.
+// Type: MetadataEntryType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: An entry in the metadata of an object.
+// Since: 1.5
+type MetadataEntry struct {
+	HREF       string             `xml:"href,attr,omitempty"`
+	Type       string             `xml:"type,attr,omitempty"`
+	Link       LinkList           `xml:"Link,omitempty"`
+	Domain     *MetadataDomainTag `xml:"Domain,omitempty"` // GENERAL if nil
+	Key        string             `xml:"Key"`
+	TypedValue *TypedValue        `xml:"TypedValue"`
+}
+
+// MetadataDomainTag is the domain of a metadata entry and its visibility.
+// Type: MetadataDomainTagType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: A value of SYSTEM places this MetadataEntry in the SYSTEM domain. Omit or leave empty to place this MetadataEntry in the GENERAL domain.
+// Since: 5.1
+type MetadataDomainTag struct {
+	Visibility string `xml:"visibility,attr"` // READWRITE, READONLY or PRIVATE
+	Domain     string `xml:",chardata"`       // GENERAL or SYSTEM
+}
+
 // VAppChildren is a container for virtual machines included in this vApp.
 // Type: VAppChildrenType
 // Namespace: http://www.vmware.com/vcloud/v1.5
@@ -1625,8 +1706,8 @@ type QueryResultEdgeGatewayRecordsType struct {
 	PageSize int     `xml:"pageSize,attr,omitempty"` // Page size, as a number of records or references.
 	Total    float64 `xml:"total,attr,omitempty"`    // Total number of records or references in the container.
 	// Elements
-	Link              LinkList                          `xml:"Link,omitempty"`    // A reference to an entity or operation associated with this object.
-	EdgeGatewayRecord *QueryResultEdgeGatewayRecordType `xml:"EdgeGatewayRecord"` // A record representing a query result.
+	Link              LinkList                            `xml:"Link,omitempty"`    // A reference to an entity or operation associated with this object.
+	EdgeGatewayRecord []*QueryResultEdgeGatewayRecordType `xml:"EdgeGatewayRecord"` // A record representing a query result.
 }
 
 type QueryResultRecordsType struct {
@@ -1638,11 +1719,57 @@ type QueryResultRecordsType struct {
 	PageSize int     `xml:"pageSize,attr,omitempty"` // Page size, as a number of records or references.
 	Total    float64 `xml:"total,attr,omitempty"`    // Total number of records or references in the container.
 	// Elements
-	Link                       []*Link                                      `xml:"Link,omitempty"`             // A reference to an entity or operation associated with this object.
-	EdgeGatewayRecord          *QueryResultEdgeGatewayRecordType            `xml:"EdgeGatewayRecord"`          // A record representing a query result.
-	VMRecord                   []*QueryResultVMRecordType                   `xml:"VMRecord"`                   // A record representing a VM result.
-	VAppRecord                 []*QueryResultVAppRecordType                 `xml:"VAppRecord"`                 // A record representing a VApp result.
-	OrgVdcStorageProfileRecord []*QueryResultOrgVdcStorageProfileRecordType `xml:"OrgVdcStorageProfileRecord"` // A record representing storage profiles
+	Link                                LinkList                                         `xml:"Link,omitempty"`                      // A reference to an entity or operation associated with this object.
+	EdgeGatewayRecord                   []*QueryResultEdgeGatewayRecordType              `xml:"EdgeGatewayRecord"`                   // A record representing a query result.
+	VMRecord                            []*QueryResultVMRecordType                       `xml:"VMRecord"`                            // A record representing a VM result.
+	AdminVMRecord                       []*QueryResultVMRecordType                       `xml:"AdminVMRecord"`                       // A record representing a VM result, system administrator view.
+	VAppRecord                          []*QueryResultVAppRecordType                     `xml:"VAppRecord"`                          // A record representing a VApp result.
+	AdminVAppRecord                     []*QueryResultVAppRecordType                     `xml:"AdminVAppRecord"`                     // A record representing a VApp result, system administrator view.
+	OrgVdcStorageProfileRecord          []*QueryResultOrgVdcStorageProfileRecordType     `xml:"OrgVdcStorageProfileRecord"`          // A record representing storage profiles
+	AdminOrgVdcStorageProfileRecord     []*QueryResultOrgVdcStorageProfileRecordType     `xml:"AdminOrgVdcStorageProfileRecord"`     // A record representing storage profiles, system administrator view.
+	CatalogRecord                       []*QueryResultCatalogRecordType                  `xml:"CatalogRecord"`                       // A record representing a catalog.
+	AdminCatalogRecord                  []*QueryResultCatalogRecordType                  `xml:"AdminCatalogRecord"`                  // A record representing a catalog, system administrator view.
+	CatalogItemRecord                   []*QueryResultCatalogItemRecordType              `xml:"CatalogItemRecord"`                   // A record representing a catalog item.
+	AdminCatalogItemRecord              []*QueryResultCatalogItemRecordType              `xml:"AdminCatalogItemRecord"`              // A record representing a catalog item, system administrator view.
+	VAppTemplateRecord                  []*QueryResultVAppTemplateRecordType             `xml:"VAppTemplateRecord"`                  // A record representing a vApp template.
+	AdminVAppTemplateRecord             []*QueryResultVAppTemplateRecordType             `xml:"AdminVAppTemplateRecord"`             // A record representing a vApp template, system administrator view.
+	MediaRecord                         []*QueryResultMediaRecordType                    `xml:"MediaRecord"`                         // A record representing a media.
+	AdminMediaRecord                    []*QueryResultMediaRecordType                    `xml:"AdminMediaRecord"`                    // A record representing a media, system administrator view.
+	OrgVdcNetworkRecord                 []*QueryResultOrgVdcNetworkRecordType            `xml:"OrgVdcNetworkRecord"`                 // A record representing an org VDC network.
+	OrgVdcRecord                        []*QueryResultOrgVdcRecordType                   `xml:"OrgVdcRecord"`                        // A record representing an org VDC.
+	AdminOrgVdcRecord                   []*QueryResultOrgVdcRecordType                   `xml:"AdminVdcRecord"`                      // A record representing an org VDC, system administrator view.
+	TaskRecord                          []*QueryResultTaskRecordType                     `xml:"TaskRecord"`                          // A record representing a task.
+	AdminTaskRecord                     []*QueryResultTaskRecordType                     `xml:"AdminTaskRecord"`                     // A record representing a task, system administrator view.
+	EventRecord                         []*QueryResultEventRecordType                    `xml:"EventRecord"`                         // A record representing an event.
+	AdminEventRecord                    []*QueryResultEventRecordType                    `xml:"AdminEventRecord"`                    // A record representing an event, system administrator view.
+	DiskRecord                          []*QueryResultDiskRecordType                     `xml:"DiskRecord"`                          // A record representing an independent disk.
+	AdminDiskRecord                     []*QueryResultDiskRecordType                     `xml:"AdminDiskRecord"`                     // A record representing an independent disk, system administrator view.
+	UserRecord                          []*QueryResultUserRecordType                     `xml:"UserRecord"`                          // A record representing a user.
+	AdminUserRecord                     []*QueryResultUserRecordType                     `xml:"AdminUserRecord"`                     // A record representing a user, system administrator view.
+	GroupRecord                         []*QueryResultGroupRecordType                    `xml:"GroupRecord"`                         // A record representing a group.
+	AdminGroupRecord                    []*QueryResultGroupRecordType                    `xml:"AdminGroupRecord"`                    // A record representing a group, system administrator view.
+	AllocatedExternalAddressRecord      []*QueryResultAllocatedExternalAddressRecordType `xml:"AllocatedExternalAddressRecord"`      // A record representing an allocated external IP address.
+	AdminAllocatedExternalAddressRecord []*QueryResultAllocatedExternalAddressRecordType `xml:"AdminAllocatedExternalAddressRecord"` // A record representing an allocated external IP address, system administrator view.
+}
+
+// QueryResultReferencesType is a container for query results in references
+// format. The references are named after the query type, e.g. VMReference,
+// and are all collected in Reference.
+// Type: QueryResultReferencesType
+// Namespace: http://www.vmware.com/vcloud/v1.5
+// Description: Container for query results in references format.
+// Since: 1.5
+type QueryResultReferencesType struct {
+	// Attributes
+	HREF     string  `xml:"href,attr,omitempty"`     // The URI of the entity.
+	Type     string  `xml:"type,attr,omitempty"`     // The MIME type of the entity.
+	Name     string  `xml:"name,attr,omitempty"`     // The name of the entity.
+	Page     int     `xml:"page,attr,omitempty"`     // Page of the result set that this container holds. The first page is page number 1.
+	PageSize int     `xml:"pageSize,attr,omitempty"` // Page size, as a number of records or references.
+	Total    float64 `xml:"total,attr,omitempty"`    // Total number of records or references in the container.
+	// Elements
+	Link      LinkList     `xml:"Link,omitempty"` // A reference to an entity or operation associated with this object.
+	Reference []*Reference `xml:",any"`           // A reference to an entity returned by the query.
 }
 
 // QueryResultEdgeGatewayRecordType represents an edge gateway record as query result.
@@ -1687,6 +1814,8 @@ type QueryResultVMRecordType struct {
 	TaskStatusName          string `xml:"taskStatusName,attr,omitempty"`
 	TaskDetails             string `xml:"taskDetails,attr,omitempty"`
 	TaskStatus              string `xml:"TaskStatus,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
 }
 
 // QueryResultVAppRecordType represents a VM record as query result.
@@ -1721,6 +1850,8 @@ type QueryResultVAppRecordType struct {
 	TaskStatusName          string `xml:"taskStatusName,attr,omitempty"`
 	TaskStatus              string `xml:"TaskStatus,attr,omitempty"`
 	TaskDetails             string `xml:"taskDetails,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
 }
 
 // QueryResultOrgVdcStorageProfileRecordType represents a storage
@@ -1738,3 +1869,278 @@ type QueryResultOrgVdcStorageProfileRecordType struct {
 	StorageUsedMB           int    `xml:"storageUsedMB,attr,omitempty"`
 	StorageLimitMB          int    `xml:"storageLimitMB,attr,omitempty"`
 }
+
+// QueryResultCatalogRecordType represents a catalog as query result.
+type QueryResultCatalogRecordType struct {
+	// Attributes
+	HREF                  string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                    string `xml:"id,attr,omitempty"`
+	Type                  string `xml:"type,attr,omitempty"`
+	Name                  string `xml:"name,attr,omitempty"` // Catalog name.
+	Description           string `xml:"description,attr,omitempty"`
+	IsPublished           bool   `xml:"isPublished,attr,omitempty"`
+	IsShared              bool   `xml:"isShared,attr,omitempty"`
+	CreationDate          string `xml:"creationDate,attr,omitempty"`
+	OrgName               string `xml:"orgName,attr,omitempty"`
+	OwnerName             string `xml:"ownerName,attr,omitempty"`
+	NumberOfVAppTemplates int    `xml:"numberOfVAppTemplates,attr,omitempty"`
+	NumberOfMedia         int    `xml:"numberOfMedia,attr,omitempty"`
+	Owner                 string `xml:"owner,attr,omitempty"`
+	Org                   string `xml:"org,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultCatalogItemRecordType represents a catalog item as query result.
+type QueryResultCatalogItemRecordType struct {
+	// Attributes
+	HREF         string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID           string `xml:"id,attr,omitempty"`
+	Type         string `xml:"type,attr,omitempty"`
+	Entity       string `xml:"entity,attr,omitempty"`      // HREF of the vApp template or media of the item
+	EntityName   string `xml:"entityName,attr,omitempty"`  // Name of the vApp template or media of the item
+	EntityType   string `xml:"entityType,attr,omitempty"`  // vappTemplate or media
+	Catalog      string `xml:"catalog,attr,omitempty"`     // HREF of the catalog
+	CatalogName  string `xml:"catalogName,attr,omitempty"` // Name of the catalog
+	OwnerName    string `xml:"ownerName,attr,omitempty"`
+	Owner        string `xml:"owner,attr,omitempty"`
+	IsPublished  bool   `xml:"isPublished,attr,omitempty"`
+	Vdc          string `xml:"vdc,attr,omitempty"`
+	VdcName      string `xml:"vdcName,attr,omitempty"`
+	IsVdcEnabled bool   `xml:"isVdcEnabled,attr,omitempty"`
+	CreationDate string `xml:"creationDate,attr,omitempty"`
+	IsExpired    bool   `xml:"isExpired,attr,omitempty"`
+	Status       string `xml:"status,attr,omitempty"`
+	Name         string `xml:"name,attr,omitempty"` // Catalog item name.
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultVAppTemplateRecordType represents a vApp template as query result.
+type QueryResultVAppTemplateRecordType struct {
+	// Attributes
+	HREF               string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                 string `xml:"id,attr,omitempty"`
+	Type               string `xml:"type,attr,omitempty"`
+	OwnerName          string `xml:"ownerName,attr,omitempty"`
+	CatalogName        string `xml:"catalogName,attr,omitempty"`
+	IsPublished        bool   `xml:"isPublished,attr,omitempty"`
+	Name               string `xml:"name,attr,omitempty"` // vApp template name.
+	Description        string `xml:"description,attr,omitempty"`
+	Vdc                string `xml:"vdc,attr,omitempty"`
+	VdcName            string `xml:"vdcName,attr,omitempty"`
+	Org                string `xml:"org,attr,omitempty"`
+	CreationDate       string `xml:"creationDate,attr,omitempty"`
+	IsBusy             bool   `xml:"isBusy,attr,omitempty"`
+	IsGoldMaster       bool   `xml:"isGoldMaster,attr,omitempty"`
+	IsEnabled          bool   `xml:"isEnabled,attr,omitempty"`
+	Status             string `xml:"status,attr,omitempty"`
+	IsDeployed         bool   `xml:"isDeployed,attr,omitempty"`
+	IsExpired          bool   `xml:"isExpired,attr,omitempty"`
+	StorageProfileName string `xml:"storageProfileName,attr,omitempty"`
+	NumberOfVMs        int    `xml:"numberOfVMs,attr,omitempty"`
+	CatalogItem        string `xml:"catalogItem,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultMediaRecordType represents a media as query result.
+type QueryResultMediaRecordType struct {
+	// Attributes
+	HREF               string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                 string `xml:"id,attr,omitempty"`
+	Type               string `xml:"type,attr,omitempty"`
+	OwnerName          string `xml:"ownerName,attr,omitempty"`
+	CatalogName        string `xml:"catalogName,attr,omitempty"`
+	IsPublished        bool   `xml:"isPublished,attr,omitempty"`
+	Name               string `xml:"name,attr,omitempty"` // Media name.
+	Vdc                string `xml:"vdc,attr,omitempty"`
+	VdcName            string `xml:"vdcName,attr,omitempty"`
+	Org                string `xml:"org,attr,omitempty"`
+	CreationDate       string `xml:"creationDate,attr,omitempty"`
+	IsBusy             bool   `xml:"isBusy,attr,omitempty"`
+	StorageB           int64  `xml:"storageB,attr,omitempty"`
+	Owner              string `xml:"owner,attr,omitempty"`
+	Catalog            string `xml:"catalog,attr,omitempty"`
+	CatalogItem        string `xml:"catalogItem,attr,omitempty"`
+	Status             string `xml:"status,attr,omitempty"`
+	StorageProfileName string `xml:"storageProfileName,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultOrgVdcNetworkRecordType represents an org VDC network as query
+// result.
+type QueryResultOrgVdcNetworkRecordType struct {
+	// Attributes
+	HREF               string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                 string `xml:"id,attr,omitempty"`
+	Type               string `xml:"type,attr,omitempty"`
+	Name               string `xml:"name,attr,omitempty"` // Network name.
+	DefaultGateway     string `xml:"defaultGateway,attr,omitempty"`
+	Netmask            string `xml:"netmask,attr,omitempty"`
+	Dns1               string `xml:"dns1,attr,omitempty"`
+	Dns2               string `xml:"dns2,attr,omitempty"`
+	DnsSuffix          string `xml:"dnsSuffix,attr,omitempty"`
+	LinkType           int    `xml:"linkType,attr,omitempty"` // 0 = direct, 1 = routed, 2 = isolated
+	ConnectedTo        string `xml:"connectedTo,attr,omitempty"`
+	Vdc                string `xml:"vdc,attr,omitempty"`
+	IsBusy             bool   `xml:"isBusy,attr,omitempty"`
+	IsShared           bool   `xml:"isShared,attr,omitempty"`
+	VdcName            string `xml:"vdcName,attr,omitempty"`
+	IsIpScopeInherited bool   `xml:"isIpScopeInherited,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultOrgVdcRecordType represents an org VDC as query result.
+type QueryResultOrgVdcRecordType struct {
+	// Attributes
+	HREF                           string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                             string `xml:"id,attr,omitempty"`
+	Type                           string `xml:"type,attr,omitempty"`
+	Name                           string `xml:"name,attr,omitempty"` // VDC name.
+	Description                    string `xml:"description,attr,omitempty"`
+	ComputeProviderScope           string `xml:"computeProviderScope,attr,omitempty"`
+	NetworkProviderScope           string `xml:"networkProviderScope,attr,omitempty"`
+	IsEnabled                      bool   `xml:"isEnabled,attr,omitempty"`
+	IsBusy                         bool   `xml:"isBusy,attr,omitempty"`
+	Status                         string `xml:"status,attr,omitempty"`
+	OrgName                        string `xml:"orgName,attr,omitempty"`
+	Org                            string `xml:"org,attr,omitempty"`
+	AllocationModel                string `xml:"allocationModel,attr,omitempty"`
+	CpuAllocationMhz               int    `xml:"cpuAllocationMhz,attr,omitempty"`
+	CpuLimitMhz                    int    `xml:"cpuLimitMhz,attr,omitempty"`
+	CpuUsedMhz                     int    `xml:"cpuUsedMhz,attr,omitempty"`
+	MemoryAllocationMB             int    `xml:"memoryAllocationMB,attr,omitempty"`
+	MemoryLimitMB                  int    `xml:"memoryLimitMB,attr,omitempty"`
+	MemoryUsedMB                   int    `xml:"memoryUsedMB,attr,omitempty"`
+	StorageLimitMB                 int    `xml:"storageLimitMB,attr,omitempty"`
+	StorageUsedMB                  int    `xml:"storageUsedMB,attr,omitempty"`
+	NumberOfVApps                  int    `xml:"numberOfVApps,attr,omitempty"`
+	NumberOfVAppTemplates          int    `xml:"numberOfVAppTemplates,attr,omitempty"`
+	NumberOfMedia                  int    `xml:"numberOfMedia,attr,omitempty"`
+	NumberOfDisks                  int    `xml:"numberOfDisks,attr,omitempty"`
+	NumberOfStorageProfiles        int    `xml:"numberOfStorageProfiles,attr,omitempty"`
+	ProviderVdc                    string `xml:"providerVdc,attr,omitempty"`
+	ProviderVdcName                string `xml:"providerVdcName,attr,omitempty"`
+	IsSystemVdc                    bool   `xml:"isSystemVdc,attr,omitempty"`
+	NumberOfDatastores             int    `xml:"numberOfDatastores,attr,omitempty"`
+	NumberOfDeployedVApps          int    `xml:"numberOfDeployedVApps,attr,omitempty"`
+	NumberOfDeployedUnmanagedVApps int    `xml:"numberOfDeployedUnmanagedVApps,attr,omitempty"`
+}
+
+// QueryResultTaskRecordType represents a task as query result.
+type QueryResultTaskRecordType struct {
+	// Attributes
+	HREF             string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID               string `xml:"id,attr,omitempty"`
+	Type             string `xml:"type,attr,omitempty"`
+	Name             string `xml:"name,attr,omitempty"` // Task name, e.g. jobDeploy.
+	Org              string `xml:"org,attr,omitempty"`
+	OrgName          string `xml:"orgName,attr,omitempty"`
+	Object           string `xml:"object,attr,omitempty"` // HREF of the entity the task acts on
+	ObjectName       string `xml:"objectName,attr,omitempty"`
+	ObjectType       string `xml:"objectType,attr,omitempty"`
+	Status           string `xml:"status,attr,omitempty"`
+	StartDate        string `xml:"startDate,attr,omitempty"`
+	EndDate          string `xml:"endDate,attr,omitempty"`
+	OwnerName        string `xml:"ownerName,attr,omitempty"`
+	ServiceNamespace string `xml:"serviceNamespace,attr,omitempty"`
+	Details          string `xml:"details,attr,omitempty"`
+}
+
+// QueryResultEventRecordType represents an event as query result.
+type QueryResultEventRecordType struct {
+	// Attributes
+	HREF             string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID               string `xml:"id,attr,omitempty"`
+	Type             string `xml:"type,attr,omitempty"`
+	EventType        string `xml:"eventType,attr,omitempty"` // e.g. com/vmware/vcloud/event/vapp/deploy
+	Entity           string `xml:"entity,attr,omitempty"`    // HREF of the entity the event is about
+	EntityName       string `xml:"entityName,attr,omitempty"`
+	EntityType       string `xml:"entityType,attr,omitempty"`
+	Org              string `xml:"org,attr,omitempty"`
+	OrgName          string `xml:"orgName,attr,omitempty"`
+	ServiceNamespace string `xml:"serviceNamespace,attr,omitempty"`
+	TimeStamp        string `xml:"timeStamp,attr,omitempty"`
+	UserName         string `xml:"userName,attr,omitempty"`
+	EventStatus      int    `xml:"eventStatus,attr,omitempty"`
+	Description      string `xml:"description,attr,omitempty"`
+}
+
+// QueryResultDiskRecordType represents an independent disk as query result.
+type QueryResultDiskRecordType struct {
+	// Attributes
+	HREF               string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID                 string `xml:"id,attr,omitempty"`
+	Type               string `xml:"type,attr,omitempty"`
+	Name               string `xml:"name,attr,omitempty"` // Disk name.
+	Description        string `xml:"description,attr,omitempty"`
+	SizeB              int64  `xml:"sizeB,attr,omitempty"`
+	Vdc                string `xml:"vdc,attr,omitempty"`
+	VdcName            string `xml:"vdcName,attr,omitempty"`
+	StorageProfile     string `xml:"storageProfile,attr,omitempty"`
+	StorageProfileName string `xml:"storageProfileName,attr,omitempty"`
+	Datastore          string `xml:"datastore,attr,omitempty"`
+	DatastoreName      string `xml:"datastoreName,attr,omitempty"`
+	OwnerName          string `xml:"ownerName,attr,omitempty"`
+	BusType            string `xml:"busType,attr,omitempty"`
+	BusSubType         string `xml:"busSubType,attr,omitempty"`
+	Status             string `xml:"status,attr,omitempty"`
+	IsAttached         bool   `xml:"isAttached,attr,omitempty"`
+	IsBusy             bool   `xml:"isBusy,attr,omitempty"`
+	// Elements
+	Metadata *Metadata `xml:"Metadata,omitempty"` // Metadata requested with metadata:<key> fields
+}
+
+// QueryResultUserRecordType represents a user as query result.
+type QueryResultUserRecordType struct {
+	// Attributes
+	HREF            string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID              string `xml:"id,attr,omitempty"`
+	Type            string `xml:"type,attr,omitempty"`
+	Name            string `xml:"name,attr,omitempty"` // User name.
+	FullName        string `xml:"fullName,attr,omitempty"`
+	IsEnabled       bool   `xml:"isEnabled,attr,omitempty"`
+	IsLdapUser      bool   `xml:"isLdapUser,attr,omitempty"`
+	LdapIdentifier  string `xml:"ldapIdentifier,attr,omitempty"`
+	Email           string `xml:"email,attr,omitempty"`
+	Org             string `xml:"org,attr,omitempty"`
+	OrgName         string `xml:"orgName,attr,omitempty"`
+	RoleName        string `xml:"roleName,attr,omitempty"`
+	IsLocked        bool   `xml:"isLocked,attr,omitempty"`
+	DeployedVMQuota int    `xml:"deployedVMQuota,attr,omitempty"`
+	StoredVMQuota   int    `xml:"storedVMQuota,attr,omitempty"`
+	NumDeployedVMs  int    `xml:"numDeployedVMs,attr,omitempty"`
+	NumStoredVMs    int    `xml:"numStoredVMs,attr,omitempty"`
+	ProviderType    string `xml:"providerType,attr,omitempty"`
+}
+
+// QueryResultGroupRecordType represents a group as query result.
+type QueryResultGroupRecordType struct {
+	// Attributes
+	HREF         string `xml:"href,attr,omitempty"` // The URI of the entity.
+	ID           string `xml:"id,attr,omitempty"`
+	Type         string `xml:"type,attr,omitempty"`
+	Name         string `xml:"name,attr,omitempty"` // Group name.
+	Identifier   string `xml:"identifier,attr,omitempty"`
+	IsReadOnly   bool   `xml:"isReadOnly,attr,omitempty"`
+	Org          string `xml:"org,attr,omitempty"`
+	OrgName      string `xml:"orgName,attr,omitempty"`
+	RoleName     string `xml:"roleName,attr,omitempty"`
+	ProviderType string `xml:"providerType,attr,omitempty"`
+}
+
+// QueryResultAllocatedExternalAddressRecordType represents an external IP
+// address allocated to an edge gateway, as query result.
+type QueryResultAllocatedExternalAddressRecordType struct {
+	// Attributes
+	HREF          string `xml:"href,attr,omitempty"` // The URI of the network the address belongs to.
+	IPAddress     string `xml:"ipAddress,attr,omitempty"`
+	LinkedNetwork string `xml:"linkedNetwork,attr,omitempty"` // HREF of the edge gateway the address is allocated to
+	Network       string `xml:"network,attr,omitempty"`
+	NetworkName   string `xml:"networkName,attr,omitempty"`
+	VdcName       string `xml:"vdcName,attr,omitempty"`
+	Org           string `xml:"org,attr,omitempty"`
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+// Package urn parses the identifiers of vCloud Director entities and converts
+// them between their three forms: URNs such as
+// urn:vcloud:vapp:00000000-0000-0000-0000-000000000000, bare UUIDs, and
+// HREFs such as https://host/api/vApp/vapp-00000000-0000-0000-0000-000000000000.
+package urn
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalid is wrapped by the errors returned for malformed identifiers.
+var ErrInvalid = errors.New("invalid vCloud Director identifier")
+
+// Entity types, as found in URNs.
+const (
+	VApp              = "vapp"
+	VM                = "vm"
+	VAppTemplate      = "vapptemplate"
+	Org               = "org"
+	Vdc               = "vdc"
+	Catalog           = "catalog"
+	CatalogItem       = "catalogitem"
+	Network           = "network"
+	EdgeGateway       = "gateway"
+	Task              = "task"
+	Disk              = "disk"
+	Media             = "media"
+	User              = "user"
+	Group             = "group"
+	VdcStorageProfile = "vdcstorageProfile"
+)
+
+// entityPath tells where the entities of a type live in the API: the path
+// of their HREF under the API endpoint, and the prefix of the UUID in the
+// last path element, if any.
+type entityPath struct {
+	path   string
+	prefix string
+}
+
+// entityPaths lists the HREF forms of each entity type, the one used to build
+// HREFs first. The VMs of vApp templates live under vAppTemplate but share
+// the vm type of the VMs of vApps.
+var entityPaths = map[string][]entityPath{
+	VApp:              {{"vApp", "vapp-"}},
+	VM:                {{"vApp", "vm-"}, {"vAppTemplate", "vm-"}},
+	VAppTemplate:      {{"vAppTemplate", "vappTemplate-"}},
+	Org:               {{"org", ""}},
+	Vdc:               {{"vdc", ""}},
+	Catalog:           {{"catalog", ""}},
+	CatalogItem:       {{"catalogItem", ""}},
+	Network:           {{"network", ""}},
+	EdgeGateway:       {{"admin/edgeGateway", ""}},
+	Task:              {{"task", ""}},
+	Disk:              {{"disk", ""}},
+	Media:             {{"media", ""}},
+	User:              {{"admin/user", ""}},
+	Group:             {{"admin/group", ""}},
+	VdcStorageProfile: {{"vdcStorageProfile", ""}},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// URN identifies a vCloud Director entity.
+type URN struct {
+	Type string // Entity type, one of the constants of this package
+	UUID string // Identifier of the entity
+}
+
+// New returns the URN of the entity of the given type and UUID.
+func New(entityType, uuid string) URN {
+	return URN{Type: entityType, UUID: strings.ToLower(uuid)}
+}
+
+// IsUUID reports whether s is a bare UUID.
+func IsUUID(s string) bool {
+	return uuidPattern.MatchString(s)
+}
+
+// Parse parses a URN of the form urn:vcloud:<type>:<uuid>.
+func Parse(s string) (URN, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 4 || !strings.EqualFold(parts[0], "urn") || parts[1] != "vcloud" || parts[2] == "" {
+		return URN{}, fmt.Errorf("%w: %q is not a urn:vcloud:<type>:<uuid> URN", ErrInvalid, s)
+	}
+	if !IsUUID(parts[3]) {
+		return URN{}, fmt.Errorf("%w: %q is not a UUID", ErrInvalid, parts[3])
+	}
+	return New(parts[2], parts[3]), nil
+}
+
+// FromHREF returns the URN of the entity at href. The HREF can point below
+// the entity, e.g. to its metadata or to one of its actions.
+func FromHREF(href string) (URN, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return URN{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	elements := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(elements); i++ {
+		for entityType, paths := range entityPaths {
+			for _, p := range paths {
+				if elements[i] != p.path[strings.LastIndex(p.path, "/")+1:] {
+					continue
+				}
+				id := elements[i+1]
+				if strings.HasPrefix(id, p.prefix) && IsUUID(id[len(p.prefix):]) {
+					return New(entityType, id[len(p.prefix):]), nil
+				}
+			}
+		}
+	}
+	return URN{}, fmt.Errorf("%w: no entity found in %q", ErrInvalid, href)
+}
+
+// Resolve returns the URN of id, which can be a URN, an HREF or a bare UUID.
+// The type of the entity is entityType for a bare UUID; for the other forms
+// it must match entityType unless entityType is empty.
+func Resolve(entityType, id string) (URN, error) {
+	var u URN
+	var err error
+	switch {
+	case IsUUID(id):
+		if entityType == "" {
+			return URN{}, fmt.Errorf("%w: the type of entity %s is unknown", ErrInvalid, id)
+		}
+		return New(entityType, id), nil
+	case strings.HasPrefix(strings.ToLower(id), "urn:"):
+		u, err = Parse(id)
+	default:
+		u, err = FromHREF(id)
+	}
+	if err != nil {
+		return URN{}, err
+	}
+	if entityType != "" && u.Type != entityType {
+		return URN{}, fmt.Errorf("%w: %s identifies a %s, not a %s", ErrInvalid, id, u.Type, entityType)
+	}
+	return u, nil
+}
+
+// String returns the URN as urn:vcloud:<type>:<uuid>.
+func (u URN) String() string {
+	return "urn:vcloud:" + u.Type + ":" + u.UUID
+}
+
+// HREF returns the HREF of the entity, given the API endpoint, e.g.
+// https://host/api. The HREF of a VM is the one of the VMs of vApps.
+func (u URN) HREF(endpoint url.URL) (url.URL, error) {
+	paths, ok := entityPaths[u.Type]
+	if !ok {
+		return url.URL{}, fmt.Errorf("%w: no HREF is known for entities of type %q", ErrInvalid, u.Type)
+	}
+	p := paths[0]
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + p.path + "/" + p.prefix + u.UUID
+	endpoint.RawPath = ""
+	endpoint.RawQuery = ""
+	return endpoint, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package urn
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+const uuid = "a1b2c3d4-0000-4000-8000-00000000abcd"
+
+func TestParse(t *testing.T) {
+	u, err := Parse("urn:vcloud:vapp:A1B2C3D4-0000-4000-8000-00000000ABCD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != (URN{VApp, uuid}) {
+		t.Fatalf("unexpected URN %+v", u)
+	}
+	if u.String() != "urn:vcloud:vapp:"+uuid {
+		t.Fatalf("unexpected string %s", u)
+	}
+
+	for _, s := range []string{
+		"",
+		uuid,
+		"urn:vcloud:vapp",
+		"urn:vcloud::" + uuid,
+		"urn:other:vapp:" + uuid,
+		"urn:vcloud:vapp:not-a-uuid",
+		"urn:vcloud:vapp:" + uuid + ":extra",
+	} {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %q to be invalid, got %v", s, err)
+		}
+	}
+}
+
+func TestFromHREF(t *testing.T) {
+	tests := []struct {
+		href string
+		want URN
+	}{
+		{"https://vcd.example.com/api/vApp/vapp-" + uuid, URN{VApp, uuid}},
+		{"https://vcd.example.com/api/vApp/vm-" + uuid + "/power/action/powerOn", URN{VM, uuid}},
+		{"https://vcd.example.com/api/vAppTemplate/vappTemplate-" + uuid + "/metadata", URN{VAppTemplate, uuid}},
+		{"https://vcd.example.com/api/vAppTemplate/vm-" + uuid, URN{VM, uuid}},
+		{"https://vcd.example.com/api/admin/edgeGateway/" + uuid, URN{EdgeGateway, uuid}},
+		{"https://vcd.example.com/api/vdc/" + uuid, URN{Vdc, uuid}},
+		{"https://vcd.example.com/tenant/api/catalogItem/" + uuid, URN{CatalogItem, uuid}},
+	}
+	for _, test := range tests {
+		u, err := FromHREF(test.href)
+		if err != nil {
+			t.Errorf("%s: %s", test.href, err)
+			continue
+		}
+		if u != test.want {
+			t.Errorf("%s: expected %+v, got %+v", test.href, test.want, u)
+		}
+	}
+
+	if _, err := FromHREF("https://vcd.example.com/api/query"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected no entity, got %v", err)
+	}
+}
+
+func TestHREF(t *testing.T) {
+	endpoint, _ := url.Parse("https://vcd.example.com/api?ignored=1")
+
+	for _, u := range []URN{{VApp, uuid}, {VM, uuid}, {VAppTemplate, uuid}, {EdgeGateway, uuid}, {User, uuid}, {Network, uuid}} {
+		href, err := u.HREF(*endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		back, err := FromHREF(href.String())
+		if err != nil || back != u {
+			t.Errorf("%s: round trip through %s gave %+v, %v", u, href.String(), back, err)
+		}
+	}
+
+	href, _ := URN{VM, uuid}.HREF(*endpoint)
+	if href.String() != "https://vcd.example.com/api/vApp/vm-"+uuid {
+		t.Fatalf("unexpected HREF %s", href.String())
+	}
+
+	if _, err := (URN{"unknown", uuid}).HREF(*endpoint); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected an unknown type, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	for _, id := range []string{
+		uuid,
+		"urn:vcloud:vm:" + uuid,
+		"https://vcd.example.com/api/vApp/vm-" + uuid,
+	} {
+		u, err := Resolve(VM, id)
+		if err != nil || u != (URN{VM, uuid}) {
+			t.Errorf("%s: got %+v, %v", id, u, err)
+		}
+	}
+
+	if _, err := Resolve(VApp, "urn:vcloud:vm:"+uuid); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected a type mismatch, got %v", err)
+	}
+	if _, err := Resolve("", uuid); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected an unknown type, got %v", err)
+	}
+	if u, err := Resolve("", "urn:vcloud:disk:"+uuid); err != nil || u.Type != Disk {
+		t.Fatalf("expected a disk, got %+v, %v", u, err)
+	}
+}
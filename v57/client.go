@@ -1,17 +1,19 @@
 package v57
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	govcloudair "github.com/ukcloud/govcloudair"
 	types "github.com/ukcloud/govcloudair/types/v56"
 )
 
@@ -48,12 +50,18 @@ func NewClient() (*Client, error) {
 		// Implicitly trust this URL parse.
 		u, _ = url.ParseRequestURI("https://vca.vmware.com/api")
 	}
-	return &Client{
+	c := &Client{
 		VAEndpoint:    *u,
 		Region:        os.Getenv("VCLOUDAIR_REGION"),
 		VCDAuthHeader: "X-Vcloud-Authorization",
+		APIVersion:    Version,
+		MaxAPIVersion: Version,
 		http:          http.Client{Transport: &http.Transport{TLSHandshakeTimeout: 120 * time.Second}},
-	}, nil
+	}
+	if os.Getenv("VCLOUDAIR_DEBUG") != "" {
+		c.Use(govcloudair.LoggingMiddleware(log.Default(), true))
+	}
+	return c, nil
 }
 
 // Client provides a client to vCloud Air, values can be populated automatically using the Authenticate method.
@@ -63,14 +71,37 @@ type Client struct {
 	Region        string  // Region where the compute resource lives.
 	VCDToken      string  // Access Token (authorization header)
 	VCDAuthHeader string  // Authorization header
+	APIVersion    string  // vCloud Director API version in use, negotiated at login
+	MinAPIVersion string  // Lowest vCloud Director API version acceptable at login
+	MaxAPIVersion string  // Highest vCloud Director API version acceptable at login, Version by default
 	Links         types.LinkList
-	vcdHREF       *url.URL    // HREF of the backend VDC you're using
-	http          http.Client // HttpClient is the client to use. Default will be used if not provided.
+	Middleware    []govcloudair.Middleware // Middleware chain wrapping the HTTP transport, see Use.
+	vcdHREF       *url.URL                 // HREF of the backend VDC you're using
+	http          http.Client              // HttpClient is the client to use. Default will be used if not provided.
+}
+
+// Use appends middleware to the client chain.
+func (c *Client) Use(middleware ...govcloudair.Middleware) {
+	c.Middleware = append(c.Middleware, middleware...)
+}
+
+// httpClient returns a copy of the HTTP client sending requests through the
+// middleware chain.
+func (c *Client) httpClient() *http.Client {
+	hc := c.http
+	hc.Transport = govcloudair.Chain(c.http.Transport, c.Middleware...)
+	return &hc
 }
 
 // Authenticate is a helper function that performs a complete login in vCloud
 // Air and in the backend vCloud Director instance.
 func (c *Client) Authenticate(username, password string) error {
+	return c.AuthenticateWithContext(context.Background(), username, password)
+}
+
+// AuthenticateWithContext performs the same login as Authenticate, aborting
+// any in-flight request when ctx is cancelled.
+func (c *Client) AuthenticateWithContext(ctx context.Context, username, password string) error {
 	if username == "" {
 		username = os.Getenv("VCLOUDAIR_USERNAME")
 	}
@@ -78,11 +109,11 @@ func (c *Client) Authenticate(username, password string) error {
 		password = os.Getenv("VCLOUDAIR_PASSWORD")
 	}
 
-	r, _ := http.NewRequest("POST", c.VAEndpoint.String()+LoginPath, nil)
+	r, _ := http.NewRequestWithContext(ctx, "POST", c.VAEndpoint.String()+LoginPath, nil)
 	r.Header.Set("Accept", JSONMimeV57)
 	r.SetBasicAuth(username, password)
 
-	resp, err := c.http.Do(r)
+	resp, err := c.DoHTTP(r)
 	if err != nil {
 		return err
 	}
@@ -101,7 +132,7 @@ func (c *Client) Authenticate(username, password string) error {
 	c.VAToken = result.AuthToken
 	result.Config = c
 
-	instances, err := result.instances()
+	instances, err := result.instances(ctx)
 	if err != nil {
 		return err
 	}
@@ -120,7 +151,7 @@ func (c *Client) Authenticate(username, password string) error {
 	}
 	attrs.client = c
 
-	return attrs.Authenticate(username, password)
+	return attrs.Authenticate(ctx, username, password)
 }
 
 // BaseURL the base uril for the vcloud director instance
@@ -131,21 +162,9 @@ func (c *Client) BaseURL() url.URL {
 	return *c.vcdHREF
 }
 
-// DoHTTP performs a http request
+// DoHTTP performs a http request through the middleware chain
 func (c *Client) DoHTTP(req *http.Request) (*http.Response, error) {
-	if os.Getenv("VCLOUDAIR_DEBUG") != "" {
-		dr, _ := httputil.DumpRequestOut(req, true)
-		fmt.Println(string(dr))
-	}
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if os.Getenv("VCLOUDAIR_DEBUG") != "" {
-		dr, _ := httputil.DumpResponse(resp, true)
-		fmt.Println(string(dr))
-	}
-	return resp, nil
+	return c.httpClient().Do(req)
 }
 
 // Disconnect performs a disconnection from the vCloud Air API endpoint.
@@ -156,6 +175,12 @@ func (c *Client) Disconnect() error {
 // NewRequest creates a new HTTP request and applies necessary auth headers if
 // set.
 func (c *Client) NewRequest(params map[string]string, method string, u *url.URL, body io.Reader) *http.Request {
+	return c.NewRequestWithContext(context.Background(), params, method, u, body)
+}
+
+// NewRequestWithContext creates a new HTTP request bound to ctx and applies
+// necessary auth headers if set.
+func (c *Client) NewRequestWithContext(ctx context.Context, params map[string]string, method string, u *url.URL, body io.Reader) *http.Request {
 
 	p := url.Values{}
 
@@ -170,13 +195,13 @@ func (c *Client) NewRequest(params map[string]string, method string, u *url.URL,
 	// Build the request, no point in checking for errors here as we're just
 	// passing a string version of an url.URL struct and http.NewRequest returns
 	// error only if can't process an url.ParseRequestURI().
-	req, _ := http.NewRequest(method, u.String(), body)
+	req, _ := http.NewRequestWithContext(ctx, method, u.String(), body)
 
 	if c.VCDToken != "" {
 		// Add the authorization header
 		req.Header.Add(c.VCDAuthHeader, c.VCDToken)
 		// Add the Accept header for VCD
-		req.Header.Add("Accept", "application/*+xml;version="+Version)
+		req.Header.Add("Accept", "application/*+xml;version="+c.APIVersion)
 	}
 	return req
 
@@ -184,12 +209,18 @@ func (c *Client) NewRequest(params map[string]string, method string, u *url.URL,
 
 // NewAuthenticatedSession create a new vCloud Air authenticated client
 func NewAuthenticatedSession(user, password string) (*Client, error) {
+	return NewAuthenticatedSessionWithContext(context.Background(), user, password)
+}
+
+// NewAuthenticatedSessionWithContext create a new vCloud Air authenticated
+// client, giving up when ctx is cancelled
+func NewAuthenticatedSessionWithContext(ctx context.Context, user, password string) (*Client, error) {
 	client, err := NewClient()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := client.Authenticate(user, password); err != nil {
+	if err := client.AuthenticateWithContext(ctx, user, password); err != nil {
 		return nil, err
 	}
 
@@ -205,22 +236,22 @@ type oAuthClient struct {
 	}
 }
 
-func (a *oAuthClient) instances() ([]accountInstance, error) {
-	if err := a.JSONRequest("GET", InstancesPath, &a.Info); err != nil {
+func (a *oAuthClient) instances(ctx context.Context) ([]accountInstance, error) {
+	if err := a.JSONRequest(ctx, "GET", InstancesPath, &a.Info); err != nil {
 		return nil, err
 	}
 	return a.Info.Instances, nil
 }
 
-func (a *oAuthClient) JSONRequest(method, path string, result interface{}) error {
-	r, _ := http.NewRequest(method, a.Config.VAEndpoint.String()+path, nil)
+func (a *oAuthClient) JSONRequest(ctx context.Context, method, path string, result interface{}) error {
+	r, _ := http.NewRequestWithContext(ctx, method, a.Config.VAEndpoint.String()+path, nil)
 	r.Header.Set(HeaderAccept, JSONMimeV57)
 
 	if a.AuthToken != "" {
 		r.Header.Set("Authorization", "Bearer "+a.AuthToken)
 	}
 
-	resp, err := a.Config.http.Do(r)
+	resp, err := a.Config.DoHTTP(r)
 	if err != nil {
 		return err
 	}
@@ -271,12 +302,50 @@ type accountInstanceAttrs struct {
 	client        *Client
 }
 
-func (a *accountInstanceAttrs) Authenticate(user, password string) error {
-	r, _ := http.NewRequest("POST", a.SessionURI, nil)
-	r.Header.Set(HeaderAccept, AnyXMLMime511)
+// negotiateVersion picks the vCloud Director API version from the versions
+// endpoint of the instance. The client version is left alone when the
+// instance doesn't advertise one.
+func (a *accountInstanceAttrs) negotiateVersion(ctx context.Context) error {
+	if a.APIVersionURI == "" {
+		return nil
+	}
+
+	r, _ := http.NewRequestWithContext(ctx, "GET", a.APIVersionURI, nil)
+
+	resp, err := a.client.DoHTTP(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Could not retrieve the vCloud API versions, because (status %d) %s\n", resp.StatusCode, resp.Status)
+	}
+
+	var versions govcloudair.SupportedVersions
+	dec := xml.NewDecoder(resp.Body)
+	if err := dec.Decode(&versions); err != nil {
+		return err
+	}
+
+	version, err := versions.Negotiate(a.client.MinAPIVersion, a.client.MaxAPIVersion)
+	if err != nil {
+		return err
+	}
+	a.client.APIVersion = version.Version
+	return nil
+}
+
+func (a *accountInstanceAttrs) Authenticate(ctx context.Context, user, password string) error {
+	if err := a.negotiateVersion(ctx); err != nil {
+		return err
+	}
+
+	r, _ := http.NewRequestWithContext(ctx, "POST", a.SessionURI, nil)
+	r.Header.Set(HeaderAccept, "application/*+xml;version="+a.client.APIVersion)
 	r.SetBasicAuth(user+"@"+a.OrgName, password)
 
-	resp, err := a.client.http.Do(r)
+	resp, err := a.client.DoHTTP(r)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,43 @@
+package v57
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<SupportedVersions xmlns="http://www.vmware.com/vcloud/versions">
+	<VersionInfo><Version>5.5</Version></VersionInfo>
+	<VersionInfo><Version>5.11</Version></VersionInfo>
+	<VersionInfo><Version>27.0</Version></VersionInfo>
+</SupportedVersions>`)
+	}))
+	defer server.Close()
+
+	client, err := NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance := &accountInstanceAttrs{APIVersionURI: server.URL, client: client}
+
+	// The package version is the default upper bound
+	if err := instance.negotiateVersion(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if client.APIVersion != Version511 {
+		t.Fatalf("expected API version %s to be negotiated, got %s", Version511, client.APIVersion)
+	}
+
+	// Higher versions on request
+	client.MaxAPIVersion = "27.0"
+	if err := instance.negotiateVersion(context.Background()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if client.APIVersion != "27.0" {
+		t.Fatalf("expected API version 27.0 to be negotiated, got %s", client.APIVersion)
+	}
+}
@@ -6,11 +6,11 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"log"
 	"net/url"
-	"os"
 	"strconv"
 
 	types "github.com/stasian/govcloudair/types/v56"
@@ -34,33 +34,38 @@ func (v *VCDClient) NewVApp(c *Client) VApp {
 }
 
 func (v *VApp) Refresh() error {
+	return v.RefreshWithContext(context.Background())
+}
+
+func (v *VApp) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Refresh")
+	defer func() { endSpan(span, err) }()
 
 	if v.VApp.HREF == "" {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(v.VApp.HREF)
-
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.VApp{}
 
-	resp, err := checkResp(v.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retrieving task: %s", err)
+	if err = v.c.getEntity(ctx, v.VApp.HREF, "vApp", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	v.VApp = &types.VApp{}
-
-	if err = decodeBody(resp, v.VApp); err != nil {
-		return fmt.Errorf("error decoding task response: %s", err)
-	}
+	v.VApp = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (v *VApp) AddVM(orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate VAppTemplate, name string) (Task, error) {
+	return v.AddVMWithContext(context.Background(), orgvdcnetworks, vapptemplate, name)
+}
+
+func (v *VApp) AddVMWithContext(ctx context.Context, orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate VAppTemplate, name string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.AddVM")
+	defer func() { endSpan(span, err) }()
 
 	vcomp := &types.ReComposeVAppParams{
 		Ovf:         "http://schemas.dmtf.org/ovf/envelope/1",
@@ -102,45 +107,49 @@ func (v *VApp) AddVM(orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate VAppTem
 			},
 		)
 	}
-	log.Printf("%s", vcomp.SourcedItem.InstantiationParams.NetworkConnectionSection.NetworkConnection)
+	log.Printf("%+v", vcomp.SourcedItem.InstantiationParams.NetworkConnectionSection.NetworkConnection)
 
 	output, _ := xml.MarshalIndent(vcomp, "  ", "    ")
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/action/recomposeVApp"
 
-	log.Printf("[TRACE] Recompose XML: %s", string(output))
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.recomposeVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error instantiating a new VM: %s", err)
+		return Task{}, fmt.Errorf("error instantiating a new VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding task response: %w", err)
 	}
 
 	return *task, nil
 }
 
 func (v *VApp) RemoveVM(vm VM) error {
+	return v.RemoveVMWithContext(context.Background(), vm)
+}
 
-	v.Refresh()
+func (v *VApp) RemoveVMWithContext(ctx context.Context, vm VM) (err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.RemoveVM")
+	defer func() { endSpan(span, err) }()
+
+	v.RefreshWithContext(ctx)
 	task := NewTask(v.c)
 	if v.VApp.Tasks != nil {
 		for _, t := range v.VApp.Tasks.Task {
 			task.Task = t
-			err := task.WaitTaskCompletion()
+			err := task.WaitTaskCompletionWithContext(ctx)
 			if err != nil {
-				return fmt.Errorf("Error performing task: %#v", err)
+				return fmt.Errorf("Error performing task: %w", err)
 			}
 		}
 	}
@@ -161,30 +170,36 @@ func (v *VApp) RemoveVM(vm VM) error {
 
 	b := bytes.NewBufferString(xml.Header + string(output))
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.recomposeVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("error instantiating a new vApp: %s", err)
+		return fmt.Errorf("error instantiating a new vApp: %w", err)
 	}
 
 	task = NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return fmt.Errorf("error decoding task response: %s", err)
+		return fmt.Errorf("error decoding task response: %w", err)
 	}
 
-	err = task.WaitTaskCompletion()
+	err = task.WaitTaskCompletionWithContext(ctx)
 	if err != nil {
-		return fmt.Errorf("Error performing task: %#v", err)
+		return fmt.Errorf("Error performing task: %w", err)
 	}
 
 	return nil
 }
 
 func (v *VApp) ComposeVApp(orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate VAppTemplate, storageprofileref types.Reference, name string, description string) (Task, error) {
+	return v.ComposeVAppWithContext(context.Background(), orgvdcnetworks, vapptemplate, storageprofileref, name, description)
+}
+
+func (v *VApp) ComposeVAppWithContext(ctx context.Context, orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate VAppTemplate, storageprofileref types.Reference, name string, description string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ComposeVApp")
+	defer func() { endSpan(span, err) }()
 
 	if vapptemplate.VAppTemplate.Children == nil || orgvdcnetworks == nil {
 		return Task{}, fmt.Errorf("can't compose a new vApp, objects passed are not valid")
@@ -256,27 +271,25 @@ func (v *VApp) ComposeVApp(orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate V
 
 	output, err := xml.MarshalIndent(vcomp, "  ", "    ")
 	if err != nil {
-		return Task{}, fmt.Errorf("error marshaling vapp compose: %s", err)
+		return Task{}, fmt.Errorf("error marshaling vapp compose: %w", err)
 	}
 
-	log.Printf("\n\nXML DEBUG: %s\n\n", string(output))
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s := v.c.VCDVDCHREF
 	s.Path += "/action/composeVApp"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.composeVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error instantiating a new vApp: %s", err)
+		return Task{}, fmt.Errorf("error instantiating a new vApp: %w", err)
 	}
 
 	if err = decodeBody(resp, v.VApp); err != nil {
-		return Task{}, fmt.Errorf("error decoding vApp response: %s", err)
+		return Task{}, fmt.Errorf("error decoding vApp response: %w", err)
 	}
 
 	task := NewTask(v.c)
@@ -287,21 +300,27 @@ func (v *VApp) ComposeVApp(orgvdcnetworks []*types.OrgVDCNetwork, vapptemplate V
 }
 
 func (v *VApp) PowerOn() (Task, error) {
+	return v.PowerOnWithContext(context.Background())
+}
+
+func (v *VApp) PowerOnWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.PowerOn")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/powerOn"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error powering on vApp: %s", err)
+		return Task{}, fmt.Errorf("error powering on vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -310,21 +329,27 @@ func (v *VApp) PowerOn() (Task, error) {
 }
 
 func (v *VApp) PowerOff() (Task, error) {
+	return v.PowerOffWithContext(context.Background())
+}
+
+func (v *VApp) PowerOffWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.PowerOff")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/powerOff"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error powering off vApp: %s", err)
+		return Task{}, fmt.Errorf("error powering off vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -333,21 +358,27 @@ func (v *VApp) PowerOff() (Task, error) {
 }
 
 func (v *VApp) Reboot() (Task, error) {
+	return v.RebootWithContext(context.Background())
+}
+
+func (v *VApp) RebootWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Reboot")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/reboot"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error rebooting vApp: %s", err)
+		return Task{}, fmt.Errorf("error rebooting vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -356,21 +387,27 @@ func (v *VApp) Reboot() (Task, error) {
 }
 
 func (v *VApp) Reset() (Task, error) {
+	return v.ResetWithContext(context.Background())
+}
+
+func (v *VApp) ResetWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Reset")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/reset"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error resetting vApp: %s", err)
+		return Task{}, fmt.Errorf("error resetting vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -379,21 +416,27 @@ func (v *VApp) Reset() (Task, error) {
 }
 
 func (v *VApp) Suspend() (Task, error) {
+	return v.SuspendWithContext(context.Background())
+}
+
+func (v *VApp) SuspendWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Suspend")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/suspend"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error suspending vApp: %s", err)
+		return Task{}, fmt.Errorf("error suspending vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -402,21 +445,27 @@ func (v *VApp) Suspend() (Task, error) {
 }
 
 func (v *VApp) Shutdown() (Task, error) {
+	return v.ShutdownWithContext(context.Background())
+}
+
+func (v *VApp) ShutdownWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Shutdown")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/power/action/shutdown"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error shutting down vApp: %s", err)
+		return Task{}, fmt.Errorf("error shutting down vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -425,6 +474,12 @@ func (v *VApp) Shutdown() (Task, error) {
 }
 
 func (v *VApp) Undeploy() (Task, error) {
+	return v.UndeployWithContext(context.Background())
+}
+
+func (v *VApp) UndeployWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Undeploy")
+	defer func() { endSpan(span, err) }()
 
 	vu := &types.UndeployVAppParams{
 		Xmlns:               "http://www.vmware.com/vcloud/v1.5",
@@ -433,13 +488,7 @@ func (v *VApp) Undeploy() (Task, error) {
 
 	output, err := xml.MarshalIndent(vu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling undeploy parameters: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -447,19 +496,19 @@ func (v *VApp) Undeploy() (Task, error) {
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/action/undeploy"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.undeployVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error undeploy vApp: %s", err)
+		return Task{}, fmt.Errorf("error undeploy vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -468,6 +517,12 @@ func (v *VApp) Undeploy() (Task, error) {
 }
 
 func (v *VApp) Deploy() (Task, error) {
+	return v.DeployWithContext(context.Background())
+}
+
+func (v *VApp) DeployWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Deploy")
+	defer func() { endSpan(span, err) }()
 
 	vu := &types.DeployVAppParams{
 		Xmlns:   "http://www.vmware.com/vcloud/v1.5",
@@ -476,13 +531,7 @@ func (v *VApp) Deploy() (Task, error) {
 
 	output, err := xml.MarshalIndent(vu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling deploy parameters: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -490,19 +539,19 @@ func (v *VApp) Deploy() (Task, error) {
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/action/deploy"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.deployVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error undeploy vApp: %s", err)
+		return Task{}, fmt.Errorf("error undeploy vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -511,20 +560,26 @@ func (v *VApp) Deploy() (Task, error) {
 }
 
 func (v *VApp) Delete() (Task, error) {
+	return v.DeleteWithContext(context.Background())
+}
+
+func (v *VApp) DeleteWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Delete")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 
-	req := v.c.NewRequest(map[string]string{}, "DELETE", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "DELETE", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error deleting vApp: %s", err)
+		return Task{}, fmt.Errorf("error deleting vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -533,13 +588,25 @@ func (v *VApp) Delete() (Task, error) {
 }
 
 func (v *VApp) RunCustomizationScript(computername, script string) (Task, error) {
-	return v.Customize(computername, script, false)
+	return v.RunCustomizationScriptWithContext(context.Background(), computername, script)
+}
+
+func (v *VApp) RunCustomizationScriptWithContext(ctx context.Context, computername, script string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.RunCustomizationScript")
+	defer func() { endSpan(span, err) }()
+	return v.CustomizeWithContext(ctx, computername, script, false)
 }
 
 func (v *VApp) Customize(computername, script string, changeSid bool) (Task, error) {
-	err := v.Refresh()
+	return v.CustomizeWithContext(context.Background(), computername, script, changeSid)
+}
+
+func (v *VApp) CustomizeWithContext(ctx context.Context, computername, script string, changeSid bool) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.Customize")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	// Check if VApp Children is populated
@@ -563,15 +630,7 @@ func (v *VApp) Customize(computername, script string, changeSid bool) (Task, err
 
 	output, err := xml.MarshalIndent(vu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	log.Printf("[DEBUG] VCD Client configuration: %s", output)
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling customization section: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -579,19 +638,19 @@ func (v *VApp) Customize(computername, script string, changeSid bool) (Task, err
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/guestCustomizationSection/"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.guestCustomizationSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -599,14 +658,26 @@ func (v *VApp) Customize(computername, script string, changeSid bool) (Task, err
 }
 
 func (v *VApp) GetStatus() (string, error) {
-	err := v.Refresh()
+	return v.GetStatusWithContext(context.Background())
+}
+
+func (v *VApp) GetStatusWithContext(ctx context.Context) (_ string, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.GetStatus")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return "", fmt.Errorf("error refreshing vapp: %v", err)
+		return "", fmt.Errorf("error refreshing vapp: %w", err)
 	}
 	return types.VAppStatuses[v.VApp.Status], nil
 }
 
 func (v *VApp) GetNetworkConnectionSection() (*types.NetworkConnectionSection, error) {
+	return v.GetNetworkConnectionSectionWithContext(context.Background())
+}
+
+func (v *VApp) GetNetworkConnectionSectionWithContext(ctx context.Context) (_ *types.NetworkConnectionSection, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.GetNetworkConnectionSection")
+	defer func() { endSpan(span, err) }()
 
 	networkConnectionSection := &types.NetworkConnectionSection{}
 
@@ -616,17 +687,17 @@ func (v *VApp) GetNetworkConnectionSection() (*types.NetworkConnectionSection, e
 
 	u, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF + "/networkConnectionSection/")
 
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkConnectionSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return networkConnectionSection, fmt.Errorf("error retrieving task: %s", err)
+		return networkConnectionSection, fmt.Errorf("error retrieving task: %w", err)
 	}
 
 	if err = decodeBody(resp, networkConnectionSection); err != nil {
-		return networkConnectionSection, fmt.Errorf("error decoding task response: %s", err)
+		return networkConnectionSection, fmt.Errorf("error decoding task response: %w", err)
 	}
 
 	// The request was successful
@@ -634,10 +705,16 @@ func (v *VApp) GetNetworkConnectionSection() (*types.NetworkConnectionSection, e
 }
 
 func (v *VApp) ChangeCPUcount(size int) (Task, error) {
+	return v.ChangeCPUcountWithContext(context.Background(), size)
+}
+
+func (v *VApp) ChangeCPUcountWithContext(ctx context.Context, size int) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeCPUcount")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	// Check if VApp Children is populated
@@ -670,13 +747,7 @@ func (v *VApp) ChangeCPUcount(size int) (Task, error) {
 
 	output, err := xml.MarshalIndent(newcpu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling cpu settings: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -684,19 +755,19 @@ func (v *VApp) ChangeCPUcount(size int) (Task, error) {
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/virtualHardwareSection/cpu"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.rasdItem+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -705,9 +776,15 @@ func (v *VApp) ChangeCPUcount(size int) (Task, error) {
 }
 
 func (v *VApp) ChangeNestedHypervisor(value bool) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeNestedHypervisorWithContext(context.Background(), value)
+}
+
+func (v *VApp) ChangeNestedHypervisorWithContext(ctx context.Context, value bool) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeNestedHypervisor")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	// Check if VApp Children is populated
@@ -715,8 +792,6 @@ func (v *VApp) ChangeNestedHypervisor(value bool) (Task, error) {
 		return Task{}, fmt.Errorf("vApp doesn't contain any children, aborting customization")
 	}
 
-	log.Printf("[DEBUG] Nested Hypervisor is: %t", v.VApp.Children.VM[0].NestedHypervisorEnabled)
-
 	b := bytes.NewBufferString(xml.Header)
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
@@ -727,21 +802,19 @@ func (v *VApp) ChangeNestedHypervisor(value bool) (Task, error) {
 		s.Path += "/action/disableNestedHypervisor"
 	}
 
-	log.Printf("[DEBUG] URL for NestedHypervisor setting: %s", s)
-
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.vm+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -749,16 +822,22 @@ func (v *VApp) ChangeNestedHypervisor(value bool) (Task, error) {
 }
 
 func (v *VApp) ChangeStorageProfile(name string) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeStorageProfileWithContext(context.Background(), name)
+}
+
+func (v *VApp) ChangeStorageProfileWithContext(ctx context.Context, name string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeStorageProfile")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
 		return Task{}, fmt.Errorf("vApp doesn't contain any children, aborting customization")
 	}
 
-	vdc, err := v.c.retrieveVDC()
+	vdc, err := v.c.retrieveVDC(ctx)
 	storageprofileref, err := vdc.FindStorageProfileReference(name)
 
 	newprofile := &types.VM{
@@ -769,28 +848,26 @@ func (v *VApp) ChangeStorageProfile(name string) (Task, error) {
 
 	output, err := xml.MarshalIndent(newprofile, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling storage profile: %w", err)
 	}
 
-	log.Printf("[DEBUG] VCD Client configuration: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.vm+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -799,9 +876,15 @@ func (v *VApp) ChangeStorageProfile(name string) (Task, error) {
 }
 
 func (v *VApp) ChangeVMName(name string) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeVMNameWithContext(context.Background(), name)
+}
+
+func (v *VApp) ChangeVMNameWithContext(ctx context.Context, name string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeVMName")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
@@ -815,28 +898,26 @@ func (v *VApp) ChangeVMName(name string) (Task, error) {
 
 	output, err := xml.MarshalIndent(newname, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling vapp name: %w", err)
 	}
 
-	log.Printf("[DEBUG] VCD Client configuration: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.vm+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -845,9 +926,15 @@ func (v *VApp) ChangeVMName(name string) (Task, error) {
 }
 
 func (v *VApp) DeleteMetadata(key string) (Task, error) {
-	err := v.Refresh()
+	return v.DeleteMetadataWithContext(context.Background(), key)
+}
+
+func (v *VApp) DeleteMetadataWithContext(ctx context.Context, key string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.DeleteMetadata")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
@@ -857,17 +944,17 @@ func (v *VApp) DeleteMetadata(key string) (Task, error) {
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/metadata/" + key
 
-	req := v.c.NewRequest(map[string]string{}, "DELETE", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "DELETE", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error deleting Metadata: %s", err)
+		return Task{}, fmt.Errorf("error deleting Metadata: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -875,9 +962,15 @@ func (v *VApp) DeleteMetadata(key string) (Task, error) {
 }
 
 func (v *VApp) AddMetadata(key, value string) (Task, error) {
-	err := v.Refresh()
+	return v.AddMetadataWithContext(context.Background(), key, value)
+}
+
+func (v *VApp) AddMetadataWithContext(ctx context.Context, key, value string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.AddMetadata")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
@@ -895,29 +988,27 @@ func (v *VApp) AddMetadata(key, value string) (Task, error) {
 
 	output, err := xml.MarshalIndent(newmetadata, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling metadata: %w", err)
 	}
 
-	log.Printf("[DEBUG] NetworkXML: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/metadata/" + key
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.metadata.value+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM Network: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM Network: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -926,9 +1017,15 @@ func (v *VApp) AddMetadata(key, value string) (Task, error) {
 }
 
 func (v *VApp) SetOvf(parameters map[string]string) (Task, error) {
-	err := v.Refresh()
+	return v.SetOvfWithContext(context.Background(), parameters)
+}
+
+func (v *VApp) SetOvfWithContext(ctx context.Context, parameters map[string]string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.SetOvf")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
@@ -956,29 +1053,27 @@ func (v *VApp) SetOvf(parameters map[string]string) (Task, error) {
 
 	output, err := xml.MarshalIndent(newmetadata, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling product section: %w", err)
 	}
 
-	log.Printf("[DEBUG] NetworkXML: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/productSections"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.productSections+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM Network: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM Network: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -987,16 +1082,22 @@ func (v *VApp) SetOvf(parameters map[string]string) (Task, error) {
 }
 
 func (v *VApp) ChangeNetworkConfig(networks []map[string]interface{}, ip string) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeNetworkConfigWithContext(context.Background(), networks, ip)
+}
+
+func (v *VApp) ChangeNetworkConfigWithContext(ctx context.Context, networks []map[string]interface{}, ip string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeNetworkConfig")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing VM before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing VM before running customization: %w", err)
 	}
 
 	if v.VApp.Children == nil {
 		return Task{}, fmt.Errorf("vApp doesn't contain any children, aborting customization")
 	}
 
-	networksection, err := v.GetNetworkConnectionSection()
+	networksection, err := v.GetNetworkConnectionSectionWithContext(ctx)
 
 	for index, network := range networks {
 		// Determine what type of address is requested for the vApp
@@ -1016,8 +1117,6 @@ func (v *VApp) ChangeNetworkConfig(networks []map[string]interface{}, ip string)
 			ipAddress = ip
 		}
 
-		log.Printf("[DEBUG] Function ChangeNetworkConfig() for %s invoked", network["orgnetwork"])
-
 		networksection.Xmlns = "http://www.vmware.com/vcloud/v1.5"
 		networksection.Ovf = "http://schemas.dmtf.org/ovf/envelope/1"
 		networksection.Info = "Specifies the available VM network connections"
@@ -1031,34 +1130,32 @@ func (v *VApp) ChangeNetworkConfig(networks []map[string]interface{}, ip string)
 			networksection.PrimaryNetworkConnectionIndex = index
 		}
 
-		log.Printf("Networksection: %s", networksection)
+		log.Printf("Networksection: %+v", networksection)
 	}
 
 	output, err := xml.MarshalIndent(networksection, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling network connection section: %w", err)
 	}
 
-	log.Printf("[DEBUG] NetworkXML: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/networkConnectionSection/"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkConnectionSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM Network: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM Network: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -1066,10 +1163,16 @@ func (v *VApp) ChangeNetworkConfig(networks []map[string]interface{}, ip string)
 }
 
 func (v *VApp) ChangeMemorySize(size int) (Task, error) {
+	return v.ChangeMemorySizeWithContext(context.Background(), size)
+}
+
+func (v *VApp) ChangeMemorySizeWithContext(ctx context.Context, size int) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.ChangeMemorySize")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
 	// Check if VApp Children is populated
@@ -1101,13 +1204,7 @@ func (v *VApp) ChangeMemorySize(size int) (Task, error) {
 
 	output, err := xml.MarshalIndent(newmem, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling memory settings: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -1115,19 +1212,19 @@ func (v *VApp) ChangeMemorySize(size int) (Task, error) {
 	s, _ := url.ParseRequestURI(v.VApp.Children.VM[0].HREF)
 	s.Path += "/virtualHardwareSection/memory"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.rasdItem+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -1136,6 +1233,12 @@ func (v *VApp) ChangeMemorySize(size int) (Task, error) {
 }
 
 func (v *VApp) GetNetworkConfig() (*types.NetworkConfigSection, error) {
+	return v.GetNetworkConfigWithContext(context.Background())
+}
+
+func (v *VApp) GetNetworkConfigWithContext(ctx context.Context) (_ *types.NetworkConfigSection, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.GetNetworkConfig")
+	defer func() { endSpan(span, err) }()
 
 	networkConfig := &types.NetworkConfigSection{}
 
@@ -1145,17 +1248,17 @@ func (v *VApp) GetNetworkConfig() (*types.NetworkConfigSection, error) {
 
 	u, _ := url.ParseRequestURI(v.VApp.HREF + "/networkConfigSection/")
 
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkConfigSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return networkConfig, fmt.Errorf("error retrieving task: %s", err)
+		return networkConfig, fmt.Errorf("error retrieving task: %w", err)
 	}
 
 	if err = decodeBody(resp, networkConfig); err != nil {
-		return networkConfig, fmt.Errorf("error decoding task response: %s", err)
+		return networkConfig, fmt.Errorf("error decoding task response: %w", err)
 	}
 
 	// The request was successful
@@ -1163,6 +1266,12 @@ func (v *VApp) GetNetworkConfig() (*types.NetworkConfigSection, error) {
 }
 
 func (v *VApp) AddRAWNetworkConfig(orgvdcnetworks []*types.OrgVDCNetwork) (Task, error) {
+	return v.AddRAWNetworkConfigWithContext(context.Background(), orgvdcnetworks)
+}
+
+func (v *VApp) AddRAWNetworkConfigWithContext(ctx context.Context, orgvdcnetworks []*types.OrgVDCNetwork) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VApp.AddRAWNetworkConfig")
+	defer func() { endSpan(span, err) }()
 
 	networkConfig := &types.NetworkConfigSection{
 		Info:  "Configuration parameters for logical networks",
@@ -1187,29 +1296,27 @@ func (v *VApp) AddRAWNetworkConfig(orgvdcnetworks []*types.OrgVDCNetwork) (Task,
 
 	output, err := xml.MarshalIndent(networkConfig, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling network config section: %w", err)
 	}
 
-	log.Printf("[DEBUG] RAWNETWORK Config NetworkXML: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VApp.HREF)
 	s.Path += "/networkConfigSection/"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkconfigsection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error adding vApp Network: %s", err)
+		return Task{}, fmt.Errorf("error adding vApp Network: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -5,8 +5,10 @@
 package govcloudair
 
 import (
-	"github.com/stasian/govcloudair/testutil"
+	"context"
+
 	"github.com/stasian/govcloudair/types/v56"
+	"github.com/ukcloud/govcloudair/testutil"
 
 	. "gopkg.in/check.v1"
 )
@@ -14,13 +16,13 @@ import (
 func (s *S) Test_ComposeVApp(c *C) {
 
 	testServer.ResponseMap(7, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{200, nil, vappExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{Status: 200, Headers: nil, Body: vappExample},
 	})
 
 	// Get the Org populated
@@ -75,6 +77,17 @@ func (s *S) Test_PowerOn(c *C) {
 
 }
 
+func (s *S) Test_PowerOnWithContext(c *C) {
+
+	// A cancelled context must abort the request before it reaches the server
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.vapp.PowerOnWithContext(ctx)
+	c.Assert(err, NotNil)
+
+}
+
 func (s *S) Test_PowerOff(c *C) {
 
 	testServer.Response(200, nil, taskExample)
@@ -99,14 +112,14 @@ func (s *S) Test_Reboot(c *C) {
 
 func (s *S) Test_SetOvf(c *C) {
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/productSections":   testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/productSections":   testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
@@ -155,14 +168,14 @@ func (s *S) Test_SetOvf(c *C) {
 
 func (s *S) Test_AddMetadata(c *C) {
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/metadata/key":      testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/metadata/key":      testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
@@ -209,15 +222,15 @@ func (s *S) Test_AddMetadata(c *C) {
 func (s *S) Test_ChangeStorageProfile(c *C) {
 
 	testServer.ResponseMap(9, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000":                   testutil.Response{200, nil, taskExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000":                       testutil.Response{200, nil, vdcExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000":                   testutil.Response{Status: 200, Headers: nil, Body: taskExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":                       testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
 	})
 
 	// Get the Org populated
@@ -264,14 +277,14 @@ func (s *S) Test_ChangeStorageProfile(c *C) {
 func (s *S) Test_ChangeVMName(c *C) {
 
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000":                   testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                       testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                  testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                   testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":               testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5": testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":    testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                 testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000":                   testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
@@ -384,14 +397,14 @@ func (s *S) Test_Delete(c *C) {
 func (s *S) Test_RunCustomizationScript(c *C) {
 
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                                testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                           testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                            testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                        testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":          testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":             testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/guestCustomizationSection/": testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                                testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                           testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                            testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                        testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":          testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":             testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/guestCustomizationSection/": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
@@ -438,14 +451,14 @@ func (s *S) Test_RunCustomizationScript(c *C) {
 func (s *S) Test_ChangeCPUcount(c *C) {
 
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                                testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                           testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                            testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                        testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":          testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":             testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                          testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/virtualHardwareSection/cpu": testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                                testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                           testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                            testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                        testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":          testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":             testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                          testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/virtualHardwareSection/cpu": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
@@ -492,14 +505,14 @@ func (s *S) Test_ChangeCPUcount(c *C) {
 func (s *S) Test_ChangeMemorySize(c *C) {
 
 	testServer.ResponseMap(8, testutil.ResponseMap{
-		"/api/org/11111111-1111-1111-1111-111111111111":                                   testutil.Response{200, nil, orgExample},
-		"/api/network/44444444-4444-4444-4444-4444444444444":                              testutil.Response{200, nil, orgvdcnetExample},
-		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                               testutil.Response{200, nil, catalogExample},
-		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                           testutil.Response{200, nil, catalogitemExample},
-		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":             testutil.Response{200, nil, vapptemplateExample},
-		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":                testutil.Response{200, nil, instantiatedvappExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                             testutil.Response{200, nil, vappExample},
-		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/virtualHardwareSection/memory": testutil.Response{200, nil, taskExample},
+		"/api/org/11111111-1111-1111-1111-111111111111":                                   testutil.Response{Status: 200, Headers: nil, Body: orgExample},
+		"/api/network/44444444-4444-4444-4444-4444444444444":                              testutil.Response{Status: 200, Headers: nil, Body: orgvdcnetExample},
+		"/api/catalog/e8a20fdf-8a78-440c-ac71-0420db59f854":                               testutil.Response{Status: 200, Headers: nil, Body: catalogExample},
+		"/api/catalogItem/1176e485-8858-4e15-94e5-ae4face605ae":                           testutil.Response{Status: 200, Headers: nil, Body: catalogitemExample},
+		"/api/vAppTemplate/vappTemplate-40cb9721-5f1a-44f9-b5c3-98c5f518c4f5":             testutil.Response{Status: 200, Headers: nil, Body: vapptemplateExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp":                testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000":                             testutil.Response{Status: 200, Headers: nil, Body: vappExample},
+		"/api/vApp/vm-00000000-0000-0000-0000-000000000000/virtualHardwareSection/memory": testutil.Response{Status: 200, Headers: nil, Body: taskExample},
 	})
 
 	// Get the Org populated
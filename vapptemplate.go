@@ -6,6 +6,7 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 
@@ -25,34 +26,37 @@ func NewVAppTemplate(c *Client) *VAppTemplate {
 }
 
 func (v *Vdc) InstantiateVAppTemplate(template *types.InstantiateVAppTemplateParams) error {
+	return v.InstantiateVAppTemplateWithContext(context.Background(), template)
+}
+
+func (v *Vdc) InstantiateVAppTemplateWithContext(ctx context.Context, template *types.InstantiateVAppTemplateParams) (err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.InstantiateVAppTemplate")
+	defer func() { endSpan(span, err) }()
 	output, err := xml.MarshalIndent(template, "", "  ")
 	if err != nil {
-		return fmt.Errorf("Error finding VAppTemplate: %#v", err)
+		return fmt.Errorf("Error finding VAppTemplate: %w", err)
 	}
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s := v.c.VCDVDCHREF
 	s.Path += "/action/instantiateVAppTemplate"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", s, b)
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.instantiateVAppTemplateParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("error instantiating a new template: %s", err)
+		return fmt.Errorf("error instantiating a new template: %w", err)
 	}
 
 	vapptemplate := NewVAppTemplate(v.c)
 	if err = decodeBody(resp, vapptemplate.VAppTemplate); err != nil {
-		return fmt.Errorf("error decoding orgvdcnetwork response: %s", err)
+		return fmt.Errorf("error decoding orgvdcnetwork response: %w", err)
 	}
-	task := NewTask(v.c)
-	for _, t := range vapptemplate.VAppTemplate.Tasks.Task {
-		task.Task = t
-		err = task.WaitTaskCompletion()
-		if err != nil {
-			return fmt.Errorf("Error performing task: %#v", err)
-		}
+	tasks := &TaskGroup{FailFast: true}
+	tasks.addTasks(v.c, vapptemplate.VAppTemplate.Tasks)
+	if _, err = tasks.WaitAll(ctx); err != nil {
+		return fmt.Errorf("Error performing task: %w", err)
 	}
 	return nil
 }
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/ukcloud/govcloudair/urn"
+)
+
+// apiEndpoint returns the API endpoint of the session, e.g. https://host/api,
+// the parent of the query service.
+func (c *VCDClient) apiEndpoint() (url.URL, error) {
+	if c.QueryHREF.Path == "" {
+		return url.URL{}, fmt.Errorf("cannot find the API endpoint, the client isn't authenticated")
+	}
+	endpoint := c.QueryHREF
+	endpoint.Path = path.Dir(endpoint.Path)
+	endpoint.RawPath = ""
+	endpoint.RawQuery = ""
+	return endpoint, nil
+}
+
+// entityHREF returns the HREF of the entity of the given type identified by
+// id, a URN, a bare UUID or an HREF.
+func (c *VCDClient) entityHREF(entityType, id string) (string, error) {
+	u, err := urn.Resolve(entityType, id)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := c.apiEndpoint()
+	if err != nil {
+		return "", err
+	}
+	href, err := u.HREF(endpoint)
+	if err != nil {
+		return "", err
+	}
+	return href.String(), nil
+}
+
+// GetVAppByID returns the vApp identified by id, a URN such as
+// urn:vcloud:vapp:<uuid> or a bare UUID, fetched directly from its HREF.
+func (c *VCDClient) GetVAppByID(id string) (VApp, error) {
+	return c.GetVAppByIDWithContext(context.Background(), id)
+}
+
+func (c *VCDClient) GetVAppByIDWithContext(ctx context.Context, id string) (_ VApp, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.GetVAppByID")
+	defer func() { endSpan(span, err) }()
+
+	href, err := c.entityHREF(urn.VApp, id)
+	if err != nil {
+		return VApp{}, err
+	}
+
+	vapp := NewVApp(&c.Client)
+	vapp.VApp.HREF = href
+	if err = vapp.RefreshWithContext(ctx); err != nil {
+		return VApp{}, fmt.Errorf("error retrieving vApp %s: %w", id, err)
+	}
+
+	return *vapp, nil
+}
+
+// GetVMByID returns the VM identified by id, a URN such as
+// urn:vcloud:vm:<uuid> or a bare UUID, fetched directly from its HREF.
+func (c *VCDClient) GetVMByID(id string) (VM, error) {
+	return c.GetVMByIDWithContext(context.Background(), id)
+}
+
+func (c *VCDClient) GetVMByIDWithContext(ctx context.Context, id string) (_ VM, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.GetVMByID")
+	defer func() { endSpan(span, err) }()
+
+	href, err := c.entityHREF(urn.VM, id)
+	if err != nil {
+		return VM{}, err
+	}
+
+	vm := NewVM(&c.Client)
+	vm.VM.HREF = href
+	if err = vm.RefreshWithContext(ctx); err != nil {
+		return VM{}, fmt.Errorf("error retrieving VM %s: %w", id, err)
+	}
+
+	return *vm, nil
+}
+
+// GetEdgeGatewayByID returns the edge gateway identified by id, a URN such as
+// urn:vcloud:gateway:<uuid> or a bare UUID, fetched directly from its HREF.
+func (c *VCDClient) GetEdgeGatewayByID(id string) (EdgeGateway, error) {
+	return c.GetEdgeGatewayByIDWithContext(context.Background(), id)
+}
+
+func (c *VCDClient) GetEdgeGatewayByIDWithContext(ctx context.Context, id string) (_ EdgeGateway, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.GetEdgeGatewayByID")
+	defer func() { endSpan(span, err) }()
+
+	href, err := c.entityHREF(urn.EdgeGateway, id)
+	if err != nil {
+		return EdgeGateway{}, err
+	}
+
+	edge := NewEdgeGateway(&c.Client)
+	edge.EdgeGateway.HREF = href
+	if err = edge.RefreshWithContext(ctx); err != nil {
+		return EdgeGateway{}, fmt.Errorf("error retrieving Edge Gateway %s: %w", id, err)
+	}
+
+	return *edge, nil
+}
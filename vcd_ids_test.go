@@ -0,0 +1,52 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"errors"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	"github.com/ukcloud/govcloudair/urn"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_GetByID(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("web", "web01", "web02")
+	fake.AddEdgeGateway("edge")
+
+	client, _, vdc := fakeVCDLogin(c, fake)
+	web, err := vdc.FindVAppByName("web")
+	c.Assert(err, IsNil)
+	edge, err := vdc.FindEdgeGateway("edge")
+	c.Assert(err, IsNil)
+
+	id, err := urn.FromHREF(web.VApp.HREF)
+	c.Assert(err, IsNil)
+	vapp, err := client.GetVAppByID(id.String())
+	c.Assert(err, IsNil)
+	c.Assert(vapp.VApp.Name, Equals, "web")
+	vapp, err = vdc.FindVAppByID(id.String())
+	c.Assert(err, IsNil)
+	c.Assert(vapp.VApp.Name, Equals, "web")
+
+	// A bare UUID takes the type of the entity asked for
+	id, err = urn.FromHREF(web.VApp.Children.VM[1].HREF)
+	c.Assert(err, IsNil)
+	vm, err := client.GetVMByID(id.UUID)
+	c.Assert(err, IsNil)
+	c.Assert(vm.VM.Name, Equals, "web02")
+	_, err = client.GetVAppByID(id.String())
+	c.Assert(errors.Is(err, urn.ErrInvalid), Equals, true, Commentf("got %v", err))
+
+	id, err = urn.FromHREF(edge.EdgeGateway.HREF)
+	c.Assert(err, IsNil)
+	gateway, err := client.GetEdgeGatewayByID(id.String())
+	c.Assert(err, IsNil)
+	c.Assert(gateway.EdgeGateway.Name, Equals, "edge")
+
+}
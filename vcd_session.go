@@ -0,0 +1,134 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// VCDSession is the state of an authenticated VCDClient. It can be saved, as
+// JSON for instance, and handed to ResumeSession later on to reuse the
+// session instead of logging in again. It holds the session token, keep it
+// as safe as a password.
+type VCDSession struct {
+	Token       string `json:"token"`
+	AuthHeader  string `json:"authHeader"`
+	APIVersion  string `json:"apiVersion"`
+	OrgHREF     string `json:"orgHref"`
+	VdcHREF     string `json:"vdcHref"`
+	QueryHREF   string `json:"queryHref"`
+	SessionHREF string `json:"sessionHref"`
+	LoginHREF   string `json:"loginHref,omitempty"`
+}
+
+// ExportSession returns the state of the current session.
+func (c *VCDClient) ExportSession() VCDSession {
+	header, token := c.Client.authToken()
+	session := VCDSession{
+		Token:       token,
+		AuthHeader:  header,
+		APIVersion:  c.Client.APIVersion,
+		OrgHREF:     c.OrgHREF.String(),
+		VdcHREF:     c.Client.VCDVDCHREF.String(),
+		QueryHREF:   c.QueryHREF.String(),
+		SessionHREF: c.sessionHREF.String(),
+	}
+	if c.loginHREF.Host != "" {
+		session.LoginHREF = c.loginHREF.String()
+	}
+	return session
+}
+
+// ResumeSession restores a session exported with ExportSession, it is an
+// alternative to Authenticate. The token is checked with a GET on the session
+// so that an expired session is reported straight away, in which case the
+// client is left untouched.
+func (c *VCDClient) ResumeSession(session VCDSession) error {
+	return c.ResumeSessionWithContext(context.Background(), session)
+}
+
+// ResumeSessionWithContext is like ResumeSession but gives up when ctx is
+// cancelled.
+func (c *VCDClient) ResumeSessionWithContext(ctx context.Context, session VCDSession) (err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.ResumeSession")
+	defer func() { endSpan(span, err) }()
+
+	if session.Token == "" || session.AuthHeader == "" {
+		return fmt.Errorf("cannot resume session, no token in session")
+	}
+
+	orgHREF, err := sessionHREF("org", session.OrgHREF)
+	if err != nil {
+		return err
+	}
+	vdcHREF, err := sessionHREF("vdc", session.VdcHREF)
+	if err != nil {
+		return err
+	}
+	queryHREF, err := sessionHREF("query", session.QueryHREF)
+	if err != nil {
+		return err
+	}
+	sessionURL, err := sessionHREF("session", session.SessionHREF)
+	if err != nil {
+		return err
+	}
+
+	// Validate the token before touching the client, a GET on the session
+	// is the cheapest authenticated call there is.
+	version := c.Client.APIVersion
+	if session.APIVersion != "" {
+		version = session.APIVersion
+	}
+	req, _ := http.NewRequestWithContext(ctx, "GET", sessionURL.String(), nil)
+	req.Header.Set(session.AuthHeader, authHeaderValue(session.AuthHeader, session.Token))
+	req.Header.Set("Accept", "application/*+xml;version="+version)
+
+	resp, err := checkResp(c.Client.httpClient().Do(req))
+	if err != nil {
+		return fmt.Errorf("error validating session: %w", err)
+	}
+	resp.Body.Close()
+
+	if session.LoginHREF != "" {
+		loginHREF, err := sessionHREF("login", session.LoginHREF)
+		if err != nil {
+			return err
+		}
+		c.loginHREF = loginHREF
+	}
+
+	c.Client.setAuthToken(session.AuthHeader, session.Token)
+	c.Client.APIVersion = version
+	c.Client.VCDVDCHREF = vdcHREF
+	c.OrgHREF = orgHREF
+	c.QueryHREF = queryHREF
+	c.sessionHREF = sessionURL
+	return nil
+}
+
+func sessionHREF(name, href string) (url.URL, error) {
+	u, err := url.Parse(href)
+	if err != nil || href == "" {
+		return url.URL{}, fmt.Errorf("cannot resume session, invalid %s HREF %q", name, href)
+	}
+	return *u, nil
+}
+
+// RetrieveVDC returns the organization vDC of the session, it is mostly
+// useful after ResumeSession.
+func (c *VCDClient) RetrieveVDC() (Vdc, error) {
+	return c.RetrieveVDCWithContext(context.Background())
+}
+
+// RetrieveVDCWithContext is like RetrieveVDC but bounded by ctx.
+func (c *VCDClient) RetrieveVDCWithContext(ctx context.Context) (_ Vdc, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.RetrieveVDC")
+	defer func() { endSpan(span, err) }()
+	return c.Client.retrieveVDC(ctx)
+}
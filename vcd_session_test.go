@@ -0,0 +1,44 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"encoding/json"
+
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_ResumeSession(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+	fake.AddVApp("vapp", "vm1")
+
+	client, _, _ := fakeVCDLogin(c, fake)
+
+	data, err := json.Marshal(client.ExportSession())
+	c.Assert(err, IsNil)
+	var session VCDSession
+	c.Assert(json.Unmarshal(data, &session), IsNil)
+
+	resumed := NewVCDClient(fake.Endpoint(), false)
+	c.Assert(resumed.ResumeSession(session), IsNil)
+	c.Assert(resumed.Client.APIVersion, Equals, client.Client.APIVersion)
+	vdc, err := resumed.RetrieveVDC()
+	c.Assert(err, IsNil)
+	_, err = vdc.FindVAppByName("vapp")
+	c.Assert(err, IsNil)
+	_, err = resumed.RetrieveOrg(fake.Vdc)
+	c.Assert(err, IsNil)
+
+	// An expired session is refused and leaves the client alone
+	c.Assert(client.Disconnect(), IsNil)
+	stale := NewVCDClient(fake.Endpoint(), false)
+	err = stale.ResumeSession(session)
+	c.Assert(IsUnauthorized(err), Equals, true, Commentf("got %v", err))
+	c.Assert(stale.Client.VCDToken, Equals, "")
+
+}
@@ -6,14 +6,13 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
-	"log"
 	"net/url"
-	"os"
-	"strings"
 
 	types "github.com/stasian/govcloudair/types/v56"
+	"github.com/ukcloud/govcloudair/urn"
 )
 
 type Vdc struct {
@@ -29,19 +28,19 @@ func NewVdc(c *Client) *Vdc {
 	}
 }
 
-func (c *Client) retrieveVDC() (Vdc, error) {
+func (c *Client) retrieveVDC(ctx context.Context) (Vdc, error) {
 
-	req := c.NewRequest(map[string]string{}, "GET", c.VCDVDCHREF, nil)
+	req := c.NewRequestWithContext(ctx, map[string]string{}, "GET", c.VCDVDCHREF, nil)
 
-	resp, err := checkResp(c.Http.Do(req))
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return Vdc{}, fmt.Errorf("error retreiving vdc: %s", err)
+		return Vdc{}, fmt.Errorf("error retreiving vdc: %w", err)
 	}
 
 	vdc := NewVdc(c)
 
 	if err = decodeBody(resp, vdc.Vdc); err != nil {
-		return Vdc{}, fmt.Errorf("error decoding vdc response: %s", err)
+		return Vdc{}, fmt.Errorf("error decoding vdc response: %w", err)
 	}
 
 	// The request was successful
@@ -49,55 +48,58 @@ func (c *Client) retrieveVDC() (Vdc, error) {
 }
 
 func (v *Vdc) Refresh() error {
+	return v.RefreshWithContext(context.Background())
+}
+
+func (v *Vdc) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.Refresh")
+	defer func() { endSpan(span, err) }()
 
 	if v.Vdc.HREF == "" {
 		return fmt.Errorf("cannot refresh, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(v.Vdc.HREF)
-
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.Vdc{}
 
-	resp, err := checkResp(v.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retreiving Edge Gateway: %s", err)
+	if err = v.c.getEntity(ctx, v.Vdc.HREF, "vdc", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	unmarshalledVdc := &types.Vdc{}
-
-	if err = decodeBody(resp, unmarshalledVdc); err != nil {
-		return fmt.Errorf("error decoding vdc response: %s", err)
-	}
-
-	v.Vdc = unmarshalledVdc
+	v.Vdc = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (v *Vdc) FindVDCNetwork(network string) (OrgVDCNetwork, error) {
+	return v.FindVDCNetworkWithContext(context.Background(), network)
+}
+
+func (v *Vdc) FindVDCNetworkWithContext(ctx context.Context, network string) (_ OrgVDCNetwork, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.FindVDCNetwork")
+	defer func() { endSpan(span, err) }()
 
 	for _, an := range v.Vdc.AvailableNetworks {
 		for _, n := range an.Network {
 			if n.Name == network {
 				u, err := url.ParseRequestURI(n.HREF)
 				if err != nil {
-					return OrgVDCNetwork{}, fmt.Errorf("error decoding vdc response: %s", err)
+					return OrgVDCNetwork{}, fmt.Errorf("error decoding vdc response: %w", err)
 				}
 
-				req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+				req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
-				resp, err := checkResp(v.c.Http.Do(req))
+				resp, err := v.c.doRequest(req)
 				if err != nil {
-					return OrgVDCNetwork{}, fmt.Errorf("error retreiving orgvdcnetwork: %s", err)
+					return OrgVDCNetwork{}, fmt.Errorf("error retreiving orgvdcnetwork: %w", err)
 				}
 
 				orgnet := NewOrgVDCNetwork(v.c)
 
 				if err = decodeBody(resp, orgnet.OrgVDCNetwork); err != nil {
-					return OrgVDCNetwork{}, fmt.Errorf("error decoding orgvdcnetwork response: %s", err)
+					return OrgVDCNetwork{}, fmt.Errorf("error decoding orgvdcnetwork response: %w", err)
 				}
 
 				// The request was successful
@@ -135,26 +137,32 @@ func (v *Vdc) GetDefaultStorageProfileReference(storageprofiles *types.QueryResu
 
 // Doesn't work with vCloud API 5.5, only vCloud Air
 func (v *Vdc) GetVDCOrg() (Org, error) {
+	return v.GetVDCOrgWithContext(context.Background())
+}
+
+func (v *Vdc) GetVDCOrgWithContext(ctx context.Context) (_ Org, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.GetVDCOrg")
+	defer func() { endSpan(span, err) }()
 
 	for _, av := range v.Vdc.Link {
 		if av.Rel == "up" && av.Type == "application/vnd.vmware.vcloud.org+xml" {
 			u, err := url.ParseRequestURI(av.HREF)
 
 			if err != nil {
-				return Org{}, fmt.Errorf("error decoding vdc response: %s", err)
+				return Org{}, fmt.Errorf("error decoding vdc response: %w", err)
 			}
 
-			req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+			req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
-			resp, err := checkResp(v.c.Http.Do(req))
+			resp, err := v.c.doRequest(req)
 			if err != nil {
-				return Org{}, fmt.Errorf("error retreiving org: %s", err)
+				return Org{}, fmt.Errorf("error retreiving org: %w", err)
 			}
 
 			org := NewOrg(v.c)
 
 			if err = decodeBody(resp, org.Org); err != nil {
-				return Org{}, fmt.Errorf("error decoding org response: %s", err)
+				return Org{}, fmt.Errorf("error decoding org response: %w", err)
 			}
 
 			// The request was successful
@@ -166,46 +174,62 @@ func (v *Vdc) GetVDCOrg() (Org, error) {
 }
 
 func (v *Vdc) FindEdgeGateway(edgegateway string) (EdgeGateway, error) {
+	return v.FindEdgeGatewayWithContext(context.Background(), edgegateway)
+}
+
+func (v *Vdc) FindEdgeGatewayWithContext(ctx context.Context, edgegateway string) (_ EdgeGateway, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.FindEdgeGateway")
+	defer func() { endSpan(span, err) }()
 
 	for _, av := range v.Vdc.Link {
 		if av.Rel == "edgeGateways" && av.Type == "application/vnd.vmware.vcloud.query.records+xml" {
 			u, err := url.ParseRequestURI(av.HREF)
 
 			if err != nil {
-				return EdgeGateway{}, fmt.Errorf("error decoding vdc response: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error decoding vdc response: %w", err)
 			}
 
 			// Querying the Result list
-			req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+			req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
-			resp, err := checkResp(v.c.Http.Do(req))
+			resp, err := v.c.doRequest(req)
 			if err != nil {
-				return EdgeGateway{}, fmt.Errorf("error retrieving edge gateway records: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error retrieving edge gateway records: %w", err)
 			}
 
 			query := new(types.QueryResultEdgeGatewayRecordsType)
 
 			if err = decodeBody(resp, query); err != nil {
-				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway query response: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway query response: %w", err)
+			}
+
+			var record *types.QueryResultEdgeGatewayRecordType
+			for _, r := range query.EdgeGatewayRecord {
+				if r.Name == edgegateway {
+					record = r
+				}
+			}
+			if record == nil {
+				return EdgeGateway{}, fmt.Errorf("can't find Edge Gateway %s", edgegateway)
 			}
 
-			u, err = url.ParseRequestURI(query.EdgeGatewayRecord.HREF)
+			u, err = url.ParseRequestURI(record.HREF)
 			if err != nil {
-				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway query response: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway query response: %w", err)
 			}
 
 			// Querying the Result list
-			req = v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+			req = v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
-			resp, err = checkResp(v.c.Http.Do(req))
+			resp, err = v.c.doRequest(req)
 			if err != nil {
-				return EdgeGateway{}, fmt.Errorf("error retrieving edge gateway: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error retrieving edge gateway: %w", err)
 			}
 
 			edge := NewEdgeGateway(v.c)
 
 			if err = decodeBody(resp, edge.EdgeGateway); err != nil {
-				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway response: %s", err)
+				return EdgeGateway{}, fmt.Errorf("error decoding edge gateway response: %w", err)
 			}
 
 			return *edge, nil
@@ -217,6 +241,12 @@ func (v *Vdc) FindEdgeGateway(edgegateway string) (EdgeGateway, error) {
 }
 
 func (v *Vdc) ComposeRawVApp(name string) error {
+	return v.ComposeRawVAppWithContext(context.Background(), name)
+}
+
+func (v *Vdc) ComposeRawVAppWithContext(ctx context.Context, name string) (err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.ComposeRawVApp")
+	defer func() { endSpan(span, err) }()
 	vcomp := &types.ComposeVAppParams{
 		Ovf:     "http://schemas.dmtf.org/ovf/envelope/1",
 		Xsi:     "http://www.w3.org/2001/XMLSchema-instance",
@@ -228,13 +258,7 @@ func (v *Vdc) ComposeRawVApp(name string) error {
 
 	output, err := xml.MarshalIndent(vcomp, "  ", "    ")
 	if err != nil {
-		return fmt.Errorf("error marshaling vapp compose: %s", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return fmt.Errorf("error marshaling vapp compose: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -242,34 +266,49 @@ func (v *Vdc) ComposeRawVApp(name string) error {
 	s := v.c.VCDVDCHREF
 	s.Path += "/action/composeVApp"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.composeVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("error instantiating a new vApp: %s", err)
+		return fmt.Errorf("error instantiating a new vApp: %w", err)
 	}
 
-	task := NewTask(v.c)
+	// The API answers with the new vApp, wait for the tasks it carries.
+	vapp := NewVApp(v.c)
 
-	if err = decodeBody(resp, task.Task); err != nil {
-		return fmt.Errorf("error decoding task response: %s", err)
+	if err = decodeBody(resp, vapp.VApp); err != nil {
+		return fmt.Errorf("error decoding vApp response: %w", err)
 	}
 
-	err = task.WaitTaskCompletion()
-	if err != nil {
-		return fmt.Errorf("Error performing task: %#v", err)
+	if vapp.VApp.Tasks == nil {
+		return nil
+	}
+
+	task := NewTask(v.c)
+	for _, t := range vapp.VApp.Tasks.Task {
+		task.Task = t
+		err = task.WaitTaskCompletionWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("Error performing task: %w", err)
+		}
 	}
 
 	return nil
 }
 
 func (v *Vdc) FindVAppByName(vapp string) (VApp, error) {
+	return v.FindVAppByNameWithContext(context.Background(), vapp)
+}
+
+func (v *Vdc) FindVAppByNameWithContext(ctx context.Context, vapp string) (_ VApp, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.FindVAppByName")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return VApp{}, fmt.Errorf("error refreshing vdc: %s", err)
+		return VApp{}, fmt.Errorf("error refreshing vdc: %w", err)
 	}
 
 	for _, resents := range v.Vdc.ResourceEntities {
@@ -277,24 +316,10 @@ func (v *Vdc) FindVAppByName(vapp string) (VApp, error) {
 
 			if resent.Name == vapp && resent.Type == "application/vnd.vmware.vcloud.vApp+xml" {
 
-				u, err := url.ParseRequestURI(resent.HREF)
-
-				if err != nil {
-					return VApp{}, fmt.Errorf("error decoding vdc response: %s", err)
-				}
-
-				// Querying the VApp
-				req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-				resp, err := checkResp(v.c.Http.Do(req))
-				if err != nil {
-					return VApp{}, fmt.Errorf("error retrieving vApp: %s", err)
-				}
-
 				newvapp := NewVApp(v.c)
 
-				if err = decodeBody(resp, newvapp.VApp); err != nil {
-					return VApp{}, fmt.Errorf("error decoding vApp response: %s", err.Error())
+				if err = v.c.getEntity(ctx, resent.HREF, "vApp", newvapp.VApp); err != nil {
+					return VApp{}, err
 				}
 
 				return *newvapp, nil
@@ -306,44 +331,31 @@ func (v *Vdc) FindVAppByName(vapp string) (VApp, error) {
 }
 
 func (v *Vdc) FindVMByName(vapp VApp, vm string) (VM, error) {
+	return v.FindVMByNameWithContext(context.Background(), vapp, vm)
+}
 
-	err := v.Refresh()
+func (v *Vdc) FindVMByNameWithContext(ctx context.Context, vapp VApp, vm string) (_ VM, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.FindVMByName")
+	defer func() { endSpan(span, err) }()
+
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return VM{}, fmt.Errorf("error refreshing vdc: %s", err)
+		return VM{}, fmt.Errorf("error refreshing vdc: %w", err)
 	}
 
-	err = vapp.Refresh()
+	err = vapp.RefreshWithContext(ctx)
 	if err != nil {
-		return VM{}, fmt.Errorf("error refreshing vapp: %s", err)
+		return VM{}, fmt.Errorf("error refreshing vapp: %w", err)
 	}
 
-	log.Printf("[TRACE] Looking for VM: %s", vm)
 	for _, child := range vapp.VApp.Children.VM {
 
-		log.Printf("[TRACE] Found: %s", child.Name)
 		if child.Name == vm {
 
-			u, err := url.ParseRequestURI(child.HREF)
-
-			if err != nil {
-				return VM{}, fmt.Errorf("error decoding vdc response: %s", err)
-			}
-
-			// Querying the VApp
-			req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-			resp, err := checkResp(v.c.Http.Do(req))
-			if err != nil {
-				return VM{}, fmt.Errorf("error retrieving vm: %s", err)
-			}
-
 			newvm := NewVM(v.c)
 
-			//body, err := ioutil.ReadAll(resp.Body)
-			//fmt.Println(string(body))
-
-			if err = decodeBody(resp, newvm.VM); err != nil {
-				return VM{}, fmt.Errorf("error decoding vm response: %s", err.Error())
+			if err = v.c.getEntity(ctx, child.HREF, "VM", newvm.VM); err != nil {
+				return VM{}, err
 			}
 
 			return *newvm, nil
@@ -351,55 +363,45 @@ func (v *Vdc) FindVMByName(vapp VApp, vm string) (VM, error) {
 		}
 
 	}
-	log.Printf("[TRACE] Couldn't find VM: %s", vm)
 	return VM{}, fmt.Errorf("can't find vm: %s", vm)
 }
 
 func (v *Vdc) FindVAppByID(vappid string) (VApp, error) {
+	return v.FindVAppByIDWithContext(context.Background(), vappid)
+}
 
-	// Horrible hack to fetch a vapp with its id.
-	// urn:vcloud:vapp:00000000-0000-0000-0000-000000000000
+func (v *Vdc) FindVAppByIDWithContext(ctx context.Context, vappid string) (_ VApp, err error) {
+	ctx, span := v.c.startSpan(ctx, "Vdc.FindVAppByID")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	id, err := urn.Resolve(urn.VApp, vappid)
 	if err != nil {
-		return VApp{}, fmt.Errorf("error refreshing vdc: %s", err)
+		return VApp{}, err
 	}
 
-	urnslice := strings.SplitAfter(vappid, ":")
-	urnid := urnslice[len(urnslice)-1]
+	err = v.RefreshWithContext(ctx)
+	if err != nil {
+		return VApp{}, fmt.Errorf("error refreshing vdc: %w", err)
+	}
 
 	for _, resents := range v.Vdc.ResourceEntities {
 		for _, resent := range resents.ResourceEntity {
 
-			hrefslice := strings.SplitAfter(resent.HREF, "/")
-			hrefslice = strings.SplitAfter(hrefslice[len(hrefslice)-1], "-")
-			res := strings.Join(hrefslice[1:], "")
-
-			if res == urnid && resent.Type == "application/vnd.vmware.vcloud.vApp+xml" {
-
-				u, err := url.ParseRequestURI(resent.HREF)
-
-				if err != nil {
-					return VApp{}, fmt.Errorf("error decoding vdc response: %s", err)
-				}
-
-				// Querying the VApp
-				req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-				resp, err := checkResp(v.c.Http.Do(req))
-				if err != nil {
-					return VApp{}, fmt.Errorf("error retrieving vApp: %s", err)
-				}
-
-				newvapp := NewVApp(v.c)
+			if resent.Type != "application/vnd.vmware.vcloud.vApp+xml" {
+				continue
+			}
 
-				if err = decodeBody(resp, newvapp.VApp); err != nil {
-					return VApp{}, fmt.Errorf("error decoding vApp response: %s", err)
-				}
+			if entity, err := urn.FromHREF(resent.HREF); err != nil || entity != id {
+				continue
+			}
 
-				return *newvapp, nil
+			newvapp := NewVApp(v.c)
 
+			if err = v.c.getEntity(ctx, resent.HREF, "vApp", newvapp.VApp); err != nil {
+				return VApp{}, err
 			}
+
+			return *newvapp, nil
 		}
 	}
 	return VApp{}, fmt.Errorf("can't find vApp")
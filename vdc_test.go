@@ -5,9 +5,10 @@
 package govcloudair
 
 import (
-	"github.com/stasian/govcloudair/testutil"
+	"github.com/ukcloud/govcloudair/testutil"
 
 	"strconv"
+	"strings"
 
 	. "gopkg.in/check.v1"
 )
@@ -109,8 +110,8 @@ func (s *S) Test_FindVApp(c *C) {
 	// c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000":       testutil.Response{200, nil, vdcExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, vappExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":       testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: vappExample},
 	})
 
 	_, err := s.vdc.FindVAppByName("myVApp")
@@ -120,8 +121,8 @@ func (s *S) Test_FindVApp(c *C) {
 	c.Assert(err, IsNil)
 
 	testServer.ResponseMap(2, testutil.ResponseMap{
-		"/api/vdc/00000000-0000-0000-0000-000000000000":       testutil.Response{200, nil, vdcExample},
-		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000": testutil.Response{200, nil, vappExample},
+		"/api/vdc/00000000-0000-0000-0000-000000000000":       testutil.Response{Status: 200, Headers: nil, Body: vdcExample},
+		"/api/vApp/vapp-00000000-0000-0000-0000-000000000000": testutil.Response{Status: 200, Headers: nil, Body: vappExample},
 	})
 
 	_, err = s.vdc.FindVAppByID("urn:vcloud:vapp:00000000-0000-0000-0000-000000000000")
@@ -132,6 +133,37 @@ func (s *S) Test_FindVApp(c *C) {
 
 }
 
+func (s *S) Test_ComposeRawVApp(c *C) {
+
+	// The vApp comes back with the instantiation task, which is waited on
+	testServer.ResponseMap(2, testutil.ResponseMap{
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp": testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/task/b3ff4b8c-9292-41a5-8dc8-22aba49bb02d":                   testutil.Response{Status: 200, Headers: nil, Body: taskExample},
+	})
+
+	err := s.vdc.ComposeRawVApp("myVApp")
+
+	reqs := testServer.WaitRequests(2)
+
+	c.Assert(err, IsNil)
+	c.Assert(reqs[0].Method, Equals, "POST")
+	c.Assert(reqs[0].Header.Get("Content-Type"), Equals, "application/vnd.vmware.vcloud.composeVAppParams+xml")
+	c.Assert(reqs[1].URL.Path, Equals, "/api/task/b3ff4b8c-9292-41a5-8dc8-22aba49bb02d")
+
+	// A failed task fails the composition
+	testServer.ResponseMap(2, testutil.ResponseMap{
+		"/api/vdc/00000000-0000-0000-0000-000000000000/action/composeVApp": testutil.Response{Status: 200, Headers: nil, Body: instantiatedvappExample},
+		"/api/task/b3ff4b8c-9292-41a5-8dc8-22aba49bb02d":                   testutil.Response{Status: 200, Headers: nil, Body: strings.Replace(taskExample, `status="success"`, `status="error"`, 1)},
+	})
+
+	err = s.vdc.ComposeRawVApp("myVApp")
+
+	_ = testServer.WaitRequests(2)
+
+	c.Assert(err, NotNil)
+
+}
+
 var vdcExample = `
 	<?xml version="1.0" ?>
 	<Vdc href="http://localhost:4444/api/vdc/00000000-0000-0000-0000-000000000000" id="urn:vcloud:vdc:00000000-0000-0000-0000-000000000000" name="M916272752-5793" status="1" type="application/vnd.vmware.vcloud.vdc+xml" xmlns="http://www.vmware.com/vcloud/v1.5" xmlns:xsi="http://www.w3.org/2001/XMLSchema-in stance" xsi:schemaLocation="http://www.vmware.com/vcloud/v1.5 http://10.6.32.3/api/v1.5/schema/master.xsd">
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range of vCloud Director API versions this library knows how to talk to.
+const (
+	MinSupportedAPIVersion = "5.5"
+	MaxSupportedAPIVersion = "36.0"
+)
+
+// SupportedVersions is the response of the unauthenticated /api/versions
+// endpoint.
+type SupportedVersions struct {
+	VersionInfo []VersionInfo `xml:"VersionInfo"`
+}
+
+// VersionInfo describes an API version offered by the server.
+type VersionInfo struct {
+	Deprecated bool   `xml:"deprecated,attr,omitempty"`
+	Version    string `xml:"Version"`
+	LoginUrl   string `xml:"LoginUrl"`
+}
+
+// Negotiate returns the highest version offered by the server that is also
+// supported by the library and lies between min and max. Empty bounds fall
+// back to MinSupportedAPIVersion and MaxSupportedAPIVersion.
+func (s *SupportedVersions) Negotiate(min, max string) (VersionInfo, error) {
+
+	if min == "" || CompareAPIVersions(min, MinSupportedAPIVersion) < 0 {
+		min = MinSupportedAPIVersion
+	}
+	if max == "" || CompareAPIVersions(max, MaxSupportedAPIVersion) > 0 {
+		max = MaxSupportedAPIVersion
+	}
+
+	var best VersionInfo
+	for _, v := range s.VersionInfo {
+		if CompareAPIVersions(v.Version, min) < 0 || CompareAPIVersions(v.Version, max) > 0 {
+			continue
+		}
+		if best.Version == "" || CompareAPIVersions(v.Version, best.Version) > 0 {
+			best = v
+		}
+	}
+
+	if best.Version == "" {
+		offered := make([]string, len(s.VersionInfo))
+		for i, v := range s.VersionInfo {
+			offered[i] = v.Version
+		}
+		return VersionInfo{}, fmt.Errorf("no API version between %s and %s offered by the server, it supports %s",
+			min, max, strings.Join(offered, ", "))
+	}
+	return best, nil
+}
+
+// CompareAPIVersions compares two dotted API versions such as "5.5" and
+// "27.0". It returns -1, 0 or 1 when a is lower than, equal to or greater
+// than b. Missing components count as zero.
+func CompareAPIVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := versionComponent(as, i), versionComponent(bs, i)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponent(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(parts[i]))
+	return n
+}
+
+// SupportsVersion reports whether the API version in use is at least version.
+// Use it to guard features that only exist in later API versions.
+func (c *Client) SupportsVersion(version string) bool {
+	return CompareAPIVersions(c.APIVersion, version) >= 0
+}
+
+// acceptHeader returns the Accept header value for mediaType pinned to the
+// API version in use.
+func (c *Client) acceptHeader(mediaType string) string {
+	return mediaType + ";version=" + c.APIVersion
+}
+
+// SupportsVersion reports whether the API version negotiated at login is at
+// least version.
+func (c *VCDClient) SupportsVersion(version string) bool {
+	return c.Client.SupportsVersion(version)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2014 VMware, Inc.  All rights reserved.  Licensed under the Apache v2 License.
+ */
+
+package govcloudair
+
+import (
+	"github.com/ukcloud/govcloudair/testutil"
+	. "gopkg.in/check.v1"
+)
+
+func (s *S) Test_CompareAPIVersions(c *C) {
+	c.Assert(CompareAPIVersions("5.5", "5.5"), Equals, 0)
+	c.Assert(CompareAPIVersions("5.5", "5.11"), Equals, -1)
+	c.Assert(CompareAPIVersions("27.0", "9.0"), Equals, 1)
+	c.Assert(CompareAPIVersions("9", "9.0"), Equals, 0)
+}
+
+func (s *S) Test_NegotiateAPIVersion(c *C) {
+
+	versions := &SupportedVersions{VersionInfo: []VersionInfo{
+		{Version: "1.5", Deprecated: true},
+		{Version: "27.0", LoginUrl: "http://localhost:4444/api/sessions"},
+		{Version: "5.5"},
+		{Version: "9.0"},
+		{Version: "99.0"},
+	}}
+
+	// Highest version known to both sides
+	v, err := versions.Negotiate("", "")
+	c.Assert(err, IsNil)
+	c.Assert(v.Version, Equals, "27.0")
+	c.Assert(v.LoginUrl, Equals, "http://localhost:4444/api/sessions")
+
+	// Caller pins
+	v, err = versions.Negotiate("", "9.0")
+	c.Assert(err, IsNil)
+	c.Assert(v.Version, Equals, "9.0")
+
+	v, err = versions.Negotiate("5.5", "5.5")
+	c.Assert(err, IsNil)
+	c.Assert(v.Version, Equals, "5.5")
+
+	_, err = versions.Negotiate("10.0", "20.0")
+	c.Assert(err, ErrorMatches, "no API version between 10.0 and 20.0 offered by the server, it supports 1.5, 27.0, 5.5, 9.0, 99.0")
+}
+
+func (s *S) Test_SupportsVersion(c *C) {
+	client := &Client{APIVersion: "9.0"}
+	c.Assert(client.SupportsVersion("5.5"), Equals, true)
+	c.Assert(client.SupportsVersion("9.0"), Equals, true)
+	c.Assert(client.SupportsVersion("27.0"), Equals, false)
+}
+
+func (s *S) Test_PinnedVersion(c *C) {
+
+	fake := testutil.NewFakeVCD()
+	defer fake.Close()
+
+	client := NewVCDClient(fake.Endpoint(), false)
+	client.Client.MaxAPIVersion = "9.0"
+	_, _, err := client.Authenticate(fake.User, fake.Password, fake.Org, fake.Vdc)
+	c.Assert(err, IsNil)
+	c.Assert(client.Client.APIVersion, Equals, "9.0")
+	c.Assert(client.SupportsVersion("27.0"), Equals, false)
+
+	client = NewVCDClient(fake.Endpoint(), false)
+	client.Client.MinAPIVersion = "30.0"
+	_, _, err = client.Authenticate(fake.User, fake.Password, fake.Org, fake.Vdc)
+	c.Assert(err, NotNil)
+
+}
@@ -6,11 +6,11 @@ package govcloudair
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"log"
 	"net/url"
-	"os"
 	"strconv"
 
 	types "github.com/stasian/govcloudair/types/v56"
@@ -29,41 +29,52 @@ func NewVM(c *Client) *VM {
 }
 
 func (v *VM) GetStatus() (string, error) {
-	err := v.Refresh()
+	return v.GetStatusWithContext(context.Background())
+}
+
+func (v *VM) GetStatusWithContext(ctx context.Context) (_ string, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.GetStatus")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return "", fmt.Errorf("error refreshing VM: %v", err)
+		return "", fmt.Errorf("error refreshing VM: %w", err)
 	}
 	return types.VAppStatuses[v.VM.Status], nil
 }
 
 func (v *VM) Refresh() error {
+	return v.RefreshWithContext(context.Background())
+}
+
+func (v *VM) RefreshWithContext(ctx context.Context) (err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.Refresh")
+	defer func() { endSpan(span, err) }()
 
 	if v.VM.HREF == "" {
 		return fmt.Errorf("cannot refresh VM, Object is empty")
 	}
 
-	u, _ := url.ParseRequestURI(v.VM.HREF)
+	// Decode into a new struct, otherwise we end up with duplicate elements
+	// in slices.
+	unmarshalled := &types.VM{}
 
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
-
-	resp, err := checkResp(v.c.Http.Do(req))
-	if err != nil {
-		return fmt.Errorf("error retrieving task: %s", err)
+	if err = v.c.getEntity(ctx, v.VM.HREF, "VM", unmarshalled); err != nil {
+		return err
 	}
 
-	// Empty struct before a new unmarshal, otherwise we end up with duplicate
-	// elements in slices.
-	v.VM = &types.VM{}
-
-	if err = decodeBody(resp, v.VM); err != nil {
-		return fmt.Errorf("error decoding task response VM: %s", err)
-	}
+	v.VM = unmarshalled
 
 	// The request was successful
 	return nil
 }
 
 func (v *VM) GetNetworkConnectionSection() (*types.NetworkConnectionSection, error) {
+	return v.GetNetworkConnectionSectionWithContext(context.Background())
+}
+
+func (v *VM) GetNetworkConnectionSectionWithContext(ctx context.Context) (_ *types.NetworkConnectionSection, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.GetNetworkConnectionSection")
+	defer func() { endSpan(span, err) }()
 
 	networkConnectionSection := &types.NetworkConnectionSection{}
 
@@ -73,17 +84,17 @@ func (v *VM) GetNetworkConnectionSection() (*types.NetworkConnectionSection, err
 
 	u, _ := url.ParseRequestURI(v.VM.HREF + "/networkConnectionSection/")
 
-	req := v.c.NewRequest(map[string]string{}, "GET", *u, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "GET", *u, nil)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkConnectionSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return networkConnectionSection, fmt.Errorf("error retrieving task: %s", err)
+		return networkConnectionSection, fmt.Errorf("error retrieving task: %w", err)
 	}
 
 	if err = decodeBody(resp, networkConnectionSection); err != nil {
-		return networkConnectionSection, fmt.Errorf("error decoding task response: %s", err)
+		return networkConnectionSection, fmt.Errorf("error decoding task response: %w", err)
 	}
 
 	// The request was successful
@@ -91,25 +102,17 @@ func (v *VM) GetNetworkConnectionSection() (*types.NetworkConnectionSection, err
 }
 
 func (c *VCDClient) FindVMByHREF(vmhref string) (VM, error) {
+	return c.FindVMByHREFWithContext(context.Background(), vmhref)
+}
 
-	u, err := url.ParseRequestURI(vmhref)
-
-	if err != nil {
-		return VM{}, fmt.Errorf("error decoding vm HREF: %s", err)
-	}
-
-	// Querying the VApp
-	req := c.Client.NewRequest(map[string]string{}, "GET", *u, nil)
-
-	resp, err := checkResp(c.Client.Http.Do(req))
-	if err != nil {
-		return VM{}, fmt.Errorf("error retrieving VM: %s", err)
-	}
+func (c *VCDClient) FindVMByHREFWithContext(ctx context.Context, vmhref string) (_ VM, err error) {
+	ctx, span := c.Client.startSpan(ctx, "VCDClient.FindVMByHREF")
+	defer func() { endSpan(span, err) }()
 
 	newvm := NewVM(&c.Client)
 
-	if err = decodeBody(resp, newvm.VM); err != nil {
-		return VM{}, fmt.Errorf("error decoding VM response: %s", err)
+	if err = c.Client.getEntity(ctx, vmhref, "VM", newvm.VM); err != nil {
+		return VM{}, err
 	}
 
 	return *newvm, nil
@@ -117,21 +120,27 @@ func (c *VCDClient) FindVMByHREF(vmhref string) (VM, error) {
 }
 
 func (v *VM) PowerOn() (Task, error) {
+	return v.PowerOnWithContext(context.Background())
+}
+
+func (v *VM) PowerOnWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.PowerOn")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/power/action/powerOn"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error powering on VM: %s", err)
+		return Task{}, fmt.Errorf("error powering on VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -140,21 +149,27 @@ func (v *VM) PowerOn() (Task, error) {
 }
 
 func (v *VM) PowerOff() (Task, error) {
+	return v.PowerOffWithContext(context.Background())
+}
+
+func (v *VM) PowerOffWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.PowerOff")
+	defer func() { endSpan(span, err) }()
 
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/power/action/powerOff"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, nil)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, nil)
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error powering off VM: %s", err)
+		return Task{}, fmt.Errorf("error powering off VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -163,10 +178,16 @@ func (v *VM) PowerOff() (Task, error) {
 }
 
 func (v *VM) ChangeCPUcount(size int) (Task, error) {
+	return v.ChangeCPUcountWithContext(context.Background(), size)
+}
+
+func (v *VM) ChangeCPUcountWithContext(ctx context.Context, size int) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.ChangeCPUcount")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing VM before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing VM before running customization: %w", err)
 	}
 
 	newcpu := &types.OVFItem{
@@ -194,13 +215,7 @@ func (v *VM) ChangeCPUcount(size int) (Task, error) {
 
 	output, err := xml.MarshalIndent(newcpu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling cpu settings: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -208,19 +223,19 @@ func (v *VM) ChangeCPUcount(size int) (Task, error) {
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/virtualHardwareSection/cpu"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.rasdItem+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -229,13 +244,17 @@ func (v *VM) ChangeCPUcount(size int) (Task, error) {
 }
 
 func (v *VM) ChangeNestedHypervisor(value bool) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeNestedHypervisorWithContext(context.Background(), value)
+}
+
+func (v *VM) ChangeNestedHypervisorWithContext(ctx context.Context, value bool) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.ChangeNestedHypervisor")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing vapp before running customization: %w", err)
 	}
 
-	log.Printf("[DEBUG] Nested Hypervisor is: %t", v.VM.NestedHypervisorEnabled)
-
 	b := bytes.NewBufferString(xml.Header)
 
 	s, _ := url.ParseRequestURI(v.VM.HREF)
@@ -246,21 +265,19 @@ func (v *VM) ChangeNestedHypervisor(value bool) (Task, error) {
 		s.Path += "/action/disableNestedHypervisor"
 	}
 
-	log.Printf("[DEBUG] URL for NestedHypervisor setting: %s", s)
-
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.vm+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -268,12 +285,18 @@ func (v *VM) ChangeNestedHypervisor(value bool) (Task, error) {
 }
 
 func (v *VM) ChangeNetworkConfig(networks []map[string]interface{}, ip string) (Task, error) {
-	err := v.Refresh()
+	return v.ChangeNetworkConfigWithContext(context.Background(), networks, ip)
+}
+
+func (v *VM) ChangeNetworkConfigWithContext(ctx context.Context, networks []map[string]interface{}, ip string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.ChangeNetworkConfig")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing VM before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing VM before running customization: %w", err)
 	}
 
-	networksection, err := v.GetNetworkConnectionSection()
+	networksection, err := v.GetNetworkConnectionSectionWithContext(ctx)
 
 	for index, network := range networks {
 		// Determine what type of address is requested for the vApp
@@ -299,8 +322,6 @@ func (v *VM) ChangeNetworkConfig(networks []map[string]interface{}, ip string) (
 			ipAllocationMode = "DHCP"
 		}
 
-		log.Printf("[DEBUG] Function ChangeNetworkConfig() for %s invoked", network["orgnetwork"])
-
 		networksection.Xmlns = "http://www.vmware.com/vcloud/v1.5"
 		networksection.Ovf = "http://schemas.dmtf.org/ovf/envelope/1"
 		networksection.Info = "Specifies the available VM network connections"
@@ -314,34 +335,32 @@ func (v *VM) ChangeNetworkConfig(networks []map[string]interface{}, ip string) (
 			networksection.PrimaryNetworkConnectionIndex = index
 		}
 
-		log.Printf("Networksection: %s", networksection)
+		log.Printf("Networksection: %+v", networksection)
 	}
 
 	output, err := xml.MarshalIndent(networksection, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
+		return Task{}, fmt.Errorf("error marshaling network connection section: %w", err)
 	}
 
-	log.Printf("[DEBUG] NetworkXML: %s", output)
-
 	b := bytes.NewBufferString(xml.Header + string(output))
 
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/networkConnectionSection/"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.networkConnectionSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM Network: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM Network: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -349,10 +368,16 @@ func (v *VM) ChangeNetworkConfig(networks []map[string]interface{}, ip string) (
 }
 
 func (v *VM) ChangeMemorySize(size int) (Task, error) {
+	return v.ChangeMemorySizeWithContext(context.Background(), size)
+}
+
+func (v *VM) ChangeMemorySizeWithContext(ctx context.Context, size int) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.ChangeMemorySize")
+	defer func() { endSpan(span, err) }()
 
-	err := v.Refresh()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing VM before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing VM before running customization: %w", err)
 	}
 
 	newmem := &types.OVFItem{
@@ -379,13 +404,7 @@ func (v *VM) ChangeMemorySize(size int) (Task, error) {
 
 	output, err := xml.MarshalIndent(newmem, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling memory settings: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -393,19 +412,19 @@ func (v *VM) ChangeMemorySize(size int) (Task, error) {
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/virtualHardwareSection/memory"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.rasdItem+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -414,13 +433,25 @@ func (v *VM) ChangeMemorySize(size int) (Task, error) {
 }
 
 func (v *VM) RunCustomizationScript(computername, script string) (Task, error) {
-	return v.Customize(computername, script, false)
+	return v.RunCustomizationScriptWithContext(context.Background(), computername, script)
+}
+
+func (v *VM) RunCustomizationScriptWithContext(ctx context.Context, computername, script string) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.RunCustomizationScript")
+	defer func() { endSpan(span, err) }()
+	return v.CustomizeWithContext(ctx, computername, script, false)
 }
 
 func (v *VM) Customize(computername, script string, changeSid bool) (Task, error) {
-	err := v.Refresh()
+	return v.CustomizeWithContext(context.Background(), computername, script, changeSid)
+}
+
+func (v *VM) CustomizeWithContext(ctx context.Context, computername, script string, changeSid bool) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.Customize")
+	defer func() { endSpan(span, err) }()
+	err = v.RefreshWithContext(ctx)
 	if err != nil {
-		return Task{}, fmt.Errorf("error refreshing VM before running customization: %v", err)
+		return Task{}, fmt.Errorf("error refreshing VM before running customization: %w", err)
 	}
 
 	vu := &types.GuestCustomizationSection{
@@ -439,15 +470,7 @@ func (v *VM) Customize(computername, script string, changeSid bool) (Task, error
 
 	output, err := xml.MarshalIndent(vu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	log.Printf("[DEBUG] VCD Client configuration: %s", output)
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling customization section: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -455,19 +478,19 @@ func (v *VM) Customize(computername, script string, changeSid bool) (Task, error
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/guestCustomizationSection/"
 
-	req := v.c.NewRequest(map[string]string{}, "PUT", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "PUT", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.guestCustomizationSection+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error customizing VM: %s", err)
+		return Task{}, fmt.Errorf("error customizing VM: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
@@ -475,6 +498,12 @@ func (v *VM) Customize(computername, script string, changeSid bool) (Task, error
 }
 
 func (v *VM) Undeploy() (Task, error) {
+	return v.UndeployWithContext(context.Background())
+}
+
+func (v *VM) UndeployWithContext(ctx context.Context) (_ Task, err error) {
+	ctx, span := v.c.startSpan(ctx, "VM.Undeploy")
+	defer func() { endSpan(span, err) }()
 
 	vu := &types.UndeployVAppParams{
 		Xmlns:               "http://www.vmware.com/vcloud/v1.5",
@@ -483,13 +512,7 @@ func (v *VM) Undeploy() (Task, error) {
 
 	output, err := xml.MarshalIndent(vu, "  ", "    ")
 	if err != nil {
-		fmt.Printf("error: %v\n", err)
-	}
-
-	debug := os.Getenv("GOVCLOUDAIR_DEBUG")
-
-	if debug == "true" {
-		fmt.Printf("\n\nXML DEBUG: %s\n\n", string(output))
+		return Task{}, fmt.Errorf("error marshaling undeploy parameters: %w", err)
 	}
 
 	b := bytes.NewBufferString(xml.Header + string(output))
@@ -497,19 +520,19 @@ func (v *VM) Undeploy() (Task, error) {
 	s, _ := url.ParseRequestURI(v.VM.HREF)
 	s.Path += "/action/undeploy"
 
-	req := v.c.NewRequest(map[string]string{}, "POST", *s, b)
+	req := v.c.NewRequestWithContext(ctx, map[string]string{}, "POST", *s, b)
 
 	req.Header.Add("Content-Type", "application/vnd.vmware.vcloud.undeployVAppParams+xml")
 
-	resp, err := checkResp(v.c.Http.Do(req))
+	resp, err := v.c.doRequest(req)
 	if err != nil {
-		return Task{}, fmt.Errorf("error undeploy vApp: %s", err)
+		return Task{}, fmt.Errorf("error undeploy vApp: %w", err)
 	}
 
 	task := NewTask(v.c)
 
 	if err = decodeBody(resp, task.Task); err != nil {
-		return Task{}, fmt.Errorf("error decoding Task response: %s", err)
+		return Task{}, fmt.Errorf("error decoding Task response: %w", err)
 	}
 
 	// The request was successful
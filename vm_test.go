@@ -14,7 +14,7 @@ func (s *K) Test_FindVMByHREF(c *C) {
 
 	// Get the Org populated
 	testServer.ResponseMap(1, testutil.ResponseMap{
-		"/api/vApp/vm-11111111-1111-1111-1111-111111111111": testutil.Response{200, nil, vmExample},
+		"/api/vApp/vm-11111111-1111-1111-1111-111111111111": testutil.Response{Status: 200, Headers: nil, Body: vmExample},
 	})
 
 	vm_href := vcdu_api.String() + "/vApp/vm-11111111-1111-1111-1111-111111111111"